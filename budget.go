@@ -0,0 +1,153 @@
+package crong
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Budget limits the total runtime of the jobs sharing it (see
+// [ScheduledJobOptions.Budget]) within fixed windows of time (ex:
+// at most 30 minutes of runtime per hour). Windows are aligned to
+// the zero time, as with [time.Time.Truncate], so hourly windows
+// start on the hour. Runtime is charged to the windows it falls in,
+// so a run that crosses into the next window uses some of that
+// window's budget, and runs in progress count toward the limit as
+// their runtime accrues. Once the limit is reached, ticks for the
+// rest of the window are skipped.
+type Budget struct {
+	limit  time.Duration
+	window time.Duration
+
+	// used holds the runtime of finished runs, by window start.
+	// Windows are pruned once they've passed, and no run in
+	// progress can still be charged to them.
+	used map[time.Time]time.Duration
+
+	// running holds the start times of runs in progress
+	running map[*budgetRun]struct{}
+
+	// latest is the start of the latest window charged
+	latest time.Time
+	mu     sync.Mutex
+}
+
+// budgetRun is a run in progress, charged to a Budget
+type budgetRun struct {
+	start time.Time
+}
+
+// NewBudget returns a Budget allowing limit of total
+// runtime in each window
+func NewBudget(limit time.Duration, window time.Duration) (*Budget, error) {
+	if limit <= 0 {
+		return nil, errors.New("budget limit must be greater than 0")
+	}
+	if window <= 0 {
+		return nil, errors.New("budget window must be greater than 0")
+	}
+	return &Budget{
+		limit:   limit,
+		window:  window,
+		used:    map[time.Time]time.Duration{},
+		running: map[*budgetRun]struct{}{},
+	}, nil
+}
+
+// Limit returns the total runtime allowed in each window
+func (b *Budget) Limit() time.Duration {
+	return b.limit
+}
+
+// Window returns the length of each window
+func (b *Budget) Window() time.Duration {
+	return b.window
+}
+
+// Used returns the runtime charged to the window containing t, by
+// finished runs and, up to t, by runs in progress. Windows that
+// passed before the previous one may have been pruned, and report 0.
+func (b *Budget) Used(t time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.usedAt(t)
+}
+
+// usedAt returns the runtime used in the window containing t.
+// b.mu must be held.
+func (b *Budget) usedAt(t time.Time) time.Duration {
+	windowStart := t.Truncate(b.window)
+	used := b.used[windowStart]
+	for r := range b.running {
+		used += overlap(r.start, t, windowStart, windowStart.Add(b.window))
+	}
+	return used
+}
+
+// Remaining returns the runtime left in the window containing t
+func (b *Budget) Remaining(t time.Time) time.Duration {
+	return max(b.limit-b.Used(t), 0)
+}
+
+// exhausted returns true if no runtime is left in
+// the window containing t
+func (b *Budget) exhausted(t time.Time) bool {
+	return b.Remaining(t) == 0
+}
+
+// begin records a run starting at start, so its runtime counts
+// toward the budget while it runs. The returned function charges
+// the run's runtime, up to end, once it has finished.
+func (b *Budget) begin(start time.Time) func(end time.Time) {
+	r := &budgetRun{start: start}
+	b.mu.Lock()
+	b.running[r] = struct{}{}
+	b.mu.Unlock()
+	return func(end time.Time) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.running, r)
+		b.charge(start, end)
+	}
+}
+
+// charge adds the runtime from start to end to the windows it
+// falls in. b.mu must be held.
+func (b *Budget) charge(start time.Time, end time.Time) {
+	for ws := start.Truncate(b.window); ws.Before(end); ws = ws.Add(b.window) {
+		if d := overlap(start, end, ws, ws.Add(b.window)); d > 0 {
+			b.used[ws] += d
+		}
+		if ws.After(b.latest) {
+			b.latest = ws
+		}
+	}
+	b.prune()
+}
+
+// prune removes windows before the previous one that runs
+// in progress can no longer be charged to. b.mu must be held.
+func (b *Budget) prune() {
+	cutoff := b.latest.Add(-b.window)
+	for r := range b.running {
+		if ws := r.start.Truncate(b.window); ws.Before(cutoff) {
+			cutoff = ws
+		}
+	}
+	for ws := range b.used {
+		if ws.Before(cutoff) {
+			delete(b.used, ws)
+		}
+	}
+}
+
+// overlap returns how much of [start, end) falls in [from, to)
+func overlap(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	return max(end.Sub(start), 0)
+}
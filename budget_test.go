@@ -0,0 +1,205 @@
+package crong
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	b, err := NewBudget(30*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	at := time.Date(2024, 2, 21, 10, 5, 0, 0, time.UTC)
+
+	b.begin(at)(at.Add(20 * time.Minute))
+	assertEqual(t, b.Used(at.Add(30*time.Minute)), 20*time.Minute)
+	assertEqual(t, b.Remaining(at), 10*time.Minute)
+	assertEqual(t, b.exhausted(at), false)
+
+	b.begin(at.Add(25 * time.Minute))(at.Add(40 * time.Minute))
+	assertEqual(t, b.Remaining(at), 0)
+	assertEqual(t, b.exhausted(at), true)
+
+	// the next window starts with the full budget
+	next := at.Add(time.Hour)
+	assertEqual(t, b.Remaining(next), 30*time.Minute)
+	b.begin(next)(next.Add(time.Minute))
+	assertEqual(t, b.Used(next), time.Minute)
+
+	// a late charge for a past window is kept, and doesn't
+	// count against the current one
+	b.begin(at.Add(45 * time.Minute))(at.Add(50 * time.Minute))
+	assertEqual(t, b.Used(at), 40*time.Minute)
+	assertEqual(t, b.Used(next), time.Minute)
+
+	if _, err = NewBudget(0, time.Hour); err == nil {
+		t.Errorf("expected error for zero limit")
+	}
+	if _, err = NewBudget(time.Minute, 0); err == nil {
+		t.Errorf("expected error for zero window")
+	}
+}
+
+func TestBudgetLongRun(t *testing.T) {
+	b, err := NewBudget(30*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a 50 minute run starting at 10:45 counts toward the 11:00
+	// window as it runs, and uses it up before it finishes
+	start := time.Date(2024, 2, 21, 10, 45, 0, 0, time.UTC)
+	end := b.begin(start)
+	assertEqual(t, b.Used(start.Add(10*time.Minute)), 10*time.Minute)
+	assertEqual(t, b.exhausted(start.Add(40*time.Minute)), false)
+	assertEqual(t, b.exhausted(start.Add(46*time.Minute)), true)
+
+	// once it ends, its runtime is split across both windows, so
+	// the next hourly occurrence (11:45) is skipped
+	end(start.Add(50 * time.Minute))
+	assertEqual(t, b.Used(start), 15*time.Minute)
+	assertEqual(t, b.Used(start.Add(time.Hour)), 35*time.Minute)
+	assertEqual(t, b.exhausted(start.Add(time.Hour)), true)
+	assertEqual(t, b.exhausted(start.Add(2*time.Hour)), false)
+
+	// a run longer than the window is charged to each window it
+	// spans, and windows before the previous one are pruned
+	long := time.Date(2024, 2, 21, 13, 30, 0, 0, time.UTC)
+	b.begin(long)(long.Add(3 * time.Hour))
+	assertEqual(t, b.Used(long.Add(2*time.Hour)), time.Hour)
+	assertEqual(t, b.Used(long.Add(3*time.Hour)), 30*time.Minute)
+	b.mu.Lock()
+	assertEqual(t, len(b.used), 2)
+	b.mu.Unlock()
+}
+
+func TestBudgetConcurrentRuns(t *testing.T) {
+	b, err := NewBudget(30*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// overlapping runs in progress count together, before
+	// either of them has finished
+	at := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	endA := b.begin(at)
+	endB := b.begin(at.Add(5 * time.Minute))
+	assertEqual(t, b.Used(at.Add(15*time.Minute)), 25*time.Minute)
+	assertEqual(t, b.exhausted(at.Add(15*time.Minute)), false)
+	assertEqual(t, b.exhausted(at.Add(20*time.Minute)), true)
+
+	endA(at.Add(10 * time.Minute))
+	assertEqual(t, b.Used(at.Add(20*time.Minute)), 25*time.Minute)
+	endB(at.Add(30 * time.Minute))
+	assertEqual(t, b.Used(at), 35*time.Minute)
+	assertEqual(t, b.exhausted(at), true)
+}
+
+func TestJobBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := NewBudget(time.Millisecond, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	now := time.Date(2024, 2, 21, 10, 5, 0, 0, time.UTC)
+	clock := ClockFunc(func() time.Time { return now })
+
+	// jobs sharing a budget are limited together
+	var jobs []*ScheduledJob
+	for i := 0; i < 2; i++ {
+		job := ScheduleFunc(
+			ctx, s, ScheduledJobOptions{Budget: b, Clock: clock},
+			func(dt time.Time) error {
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			},
+		)
+		defer job.Stop(context.Background())
+		jobs = append(jobs, job)
+	}
+
+	if err = jobs[0].Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(jobs[0].Runtimes()) == 1
+		},
+	)
+	if err = jobs[1].Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return jobs[1].OverBudget.Load() == 1
+		},
+	)
+	assertEqual(t, jobs[1].Runs.Load(), 0)
+}
+
+func TestJobBudgetRunning(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := NewBudget(30*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var mu sync.Mutex
+	now := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	clock := ClockFunc(
+		func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		},
+	)
+
+	release := make(chan struct{})
+	job := ScheduleFunc(
+		ctx, s, ScheduledJobOptions{Budget: b, Clock: clock, MaxConcurrent: 2},
+		func(dt time.Time) error {
+			<-release
+			return nil
+		},
+	)
+	defer job.Stop(context.Background())
+	defer close(release)
+
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return job.Running.Load() == 1
+		},
+	)
+
+	// the first run has been going for 40 minutes, so a
+	// concurrent run is skipped while it's still in progress
+	mu.Lock()
+	now = now.Add(40 * time.Minute)
+	mu.Unlock()
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return job.OverBudget.Load() == 1
+		},
+	)
+	assertEqual(t, job.Runs.Load(), 1)
+}
@@ -0,0 +1,72 @@
+package crong
+
+import "time"
+
+// NthWeekdayOfMonth returns the day of the month of the nth occurrence
+// of the given weekday (ex: n=3 and time.Friday for the third Friday),
+// as a schedule's '#' matches (ex: "5#3").
+// n starts at 1. If the month has fewer than n of the weekday (or n
+// is less than 1), ok is false.
+func NthWeekdayOfMonth(
+	year int,
+	month time.Month,
+	weekday time.Weekday,
+	n int,
+) (day int, ok bool) {
+	if n < 1 {
+		return 0, false
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	day = 1 + (int(weekday)-int(first)+7)%7 + (n-1)*7
+	if day > DaysInMonth(year, month) {
+		return 0, false
+	}
+	return day, true
+}
+
+// LastWeekdayOfMonth returns the day of the month of the last
+// occurrence of the given weekday (ex: the last Friday), as a
+// schedule's 'L' matches in the day of week field (ex: "5L")
+func LastWeekdayOfMonth(year int, month time.Month, weekday time.Weekday) int {
+	last := DaysInMonth(year, month)
+	lastWeekday := time.Date(year, month, last, 0, 0, 0, 0, time.UTC).Weekday()
+	return last - (int(lastWeekday)-int(weekday)+7)%7
+}
+
+// nearestWeekday returns the weekday (Monday to Friday) nearest the
+// given day of the month, without leaving the month. A Saturday moves
+// to the Friday before (or the Monday after, if it's the 1st), and a
+// Sunday to the Monday after (or the Friday before, if it's the last
+// day of the month).
+func nearestWeekday(year int, month time.Month, day int) int {
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == DaysInMonth(year, month) {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// NearestWeekday returns the day of the month of the weekday (Monday
+// to Friday) nearest the given day, as a schedule's 'W' does (ex: "15W"),
+// without leaving the month. If the month doesn't have the day (or
+// day is less than 1), ok is false.
+func NearestWeekday(year int, month time.Month, day int) (int, bool) {
+	if day < 1 || day > DaysInMonth(year, month) {
+		return 0, false
+	}
+	return nearestWeekday(year, month, day), true
+}
+
+// DaysInMonth returns the number of days in the given month, which
+// is the day a schedule's 'L' matches in the day of month field
+func DaysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
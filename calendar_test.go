@@ -0,0 +1,82 @@
+package crong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNthWeekdayOfMonth(t *testing.T) {
+	type nthCase struct {
+		Year      int
+		Month     time.Month
+		Weekday   time.Weekday
+		N         int
+		ExpectDay int
+		ExpectOK  bool
+	}
+	cases := []nthCase{
+		// March 2024 starts on a Friday
+		{2024, time.March, time.Friday, 1, 1, true},
+		{2024, time.March, time.Friday, 3, 15, true},
+		{2024, time.March, time.Friday, 5, 29, true},
+		{2024, time.March, time.Thursday, 1, 7, true},
+		{2024, time.March, time.Thursday, 4, 28, true},
+		{2024, time.March, time.Thursday, 5, 0, false},
+		{2024, time.February, time.Thursday, 5, 29, true},
+		{2023, time.February, time.Wednesday, 5, 0, false},
+		{2024, time.March, time.Friday, 0, 0, false},
+	}
+	for _, tc := range cases {
+		day, ok := NthWeekdayOfMonth(tc.Year, tc.Month, tc.Weekday, tc.N)
+		if day != tc.ExpectDay || ok != tc.ExpectOK {
+			t.Errorf(
+				"%d %s %s #%d: expected (%d, %t), got (%d, %t)",
+				tc.Year,
+				tc.Month,
+				tc.Weekday,
+				tc.N,
+				tc.ExpectDay,
+				tc.ExpectOK,
+				day,
+				ok,
+			)
+		}
+	}
+}
+
+func TestLastWeekdayOfMonth(t *testing.T) {
+	assertEqual(t, LastWeekdayOfMonth(2024, time.March, time.Sunday), 31)
+	assertEqual(t, LastWeekdayOfMonth(2024, time.March, time.Friday), 29)
+	assertEqual(t, LastWeekdayOfMonth(2024, time.March, time.Monday), 25)
+	assertEqual(t, LastWeekdayOfMonth(2024, time.February, time.Thursday), 29)
+	assertEqual(t, LastWeekdayOfMonth(2023, time.February, time.Thursday), 23)
+	assertEqual(t, LastWeekdayOfMonth(2023, time.December, time.Sunday), 31)
+}
+
+func TestNearestWeekday(t *testing.T) {
+	// June 2024 starts on a Saturday, and ends on a Sunday
+	assertEqual(t, nearestWeekday(2024, time.June, 1), 3)
+	assertEqual(t, nearestWeekday(2024, time.June, 12), 12)
+	assertEqual(t, nearestWeekday(2024, time.June, 15), 14)
+	assertEqual(t, nearestWeekday(2024, time.June, 16), 17)
+	assertEqual(t, nearestWeekday(2024, time.June, 30), 28)
+	// September 2024 starts on a Sunday
+	assertEqual(t, nearestWeekday(2024, time.September, 1), 2)
+
+	day, ok := NearestWeekday(2024, time.June, 30)
+	assertEqual(t, ok, true)
+	assertEqual(t, day, 28)
+	_, ok = NearestWeekday(2024, time.June, 31)
+	assertEqual(t, ok, false)
+	_, ok = NearestWeekday(2024, time.June, 0)
+	assertEqual(t, ok, false)
+}
+
+func TestDaysInMonth(t *testing.T) {
+	assertEqual(t, DaysInMonth(2024, time.January), 31)
+	assertEqual(t, DaysInMonth(2024, time.February), 29)
+	assertEqual(t, DaysInMonth(2023, time.February), 28)
+	assertEqual(t, DaysInMonth(2100, time.February), 28)
+	assertEqual(t, DaysInMonth(2024, time.April), 30)
+	assertEqual(t, DaysInMonth(2024, time.December), 31)
+}
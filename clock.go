@@ -0,0 +1,26 @@
+package crong
+
+import "time"
+
+// Clock provides the current time, so tests and simulations can
+// control the timestamps a [Ticker] or [ScheduledJob] records
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a [Clock]
+type ClockFunc func() time.Time
+
+// Now returns f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// clockNow returns the current time from c,
+// or from time.Now if c is nil
+func clockNow(c Clock) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
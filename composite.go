@@ -0,0 +1,171 @@
+package crong
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Composite is a schedule made of several schedules, which fires at
+// each of their scheduled times (ex: "09:00 Mon-Thu, 17:00 Fri",
+// which can't be written as a single cron expression). Times more
+// than one schedule fire at are only included once.
+type Composite struct {
+	schedules []*Schedule
+}
+
+// NewComposite creates a new Composite from the given schedules
+func NewComposite(schedules ...*Schedule) (*Composite, error) {
+	if len(schedules) == 0 {
+		return nil, errors.New("at least one schedule is required")
+	}
+	if slices.Contains(schedules, nil) {
+		return nil, errors.New("schedule cannot be nil")
+	}
+	return &Composite{schedules: slices.Clone(schedules)}, nil
+}
+
+// NewWeekdayTimes creates a new Composite firing at the given times of
+// day on each weekday, in loc (if nil, defaults to time.UTC). Times are
+// formatted as "15:04", or "15:04:05" to fire on a specific second.
+// Weekdays sharing a time share a schedule, so:
+//
+//	crong.NewWeekdayTimes(
+//		map[time.Weekday][]string{
+//			time.Monday:    {"09:00"},
+//			time.Tuesday:   {"09:00"},
+//			time.Wednesday: {"09:00"},
+//			time.Thursday:  {"09:00"},
+//			time.Friday:    {"17:00"},
+//		},
+//		nil,
+//	)
+//
+// is made of the schedules "0 9 * * 1,2,3,4" and "0 17 * * 5".
+func NewWeekdayTimes(
+	times map[time.Weekday][]string,
+	loc *time.Location,
+) (*Composite, error) {
+	// the weekdays each time of day is on
+	weekdays := map[time.Duration][]int{}
+	seconds := false
+	for weekday, dayTimes := range times {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return nil, fmt.Errorf("invalid weekday %d", weekday)
+		}
+		for _, ts := range dayTimes {
+			d, withSeconds, err := parseTimeOfDay(ts)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time '%s' on %s: %w", ts, weekday, err)
+			}
+			seconds = seconds || withSeconds
+			if !slices.Contains(weekdays[d], int(weekday)) {
+				weekdays[d] = append(weekdays[d], int(weekday))
+			}
+		}
+	}
+	if len(weekdays) == 0 {
+		return nil, errors.New("at least one time is required")
+	}
+
+	var opts []ParseOption
+	if seconds {
+		opts = append(opts, WithSeconds())
+	}
+	timesOfDay := make([]time.Duration, 0, len(weekdays))
+	for d := range weekdays {
+		timesOfDay = append(timesOfDay, d)
+	}
+	slices.Sort(timesOfDay)
+	schedules := make([]*Schedule, 0, len(timesOfDay))
+	for _, d := range timesOfDay {
+		days := weekdays[d]
+		slices.Sort(days)
+		values := make([]string, 0, len(days))
+		for _, wd := range days {
+			values = append(values, strconv.Itoa(wd))
+		}
+		cron := fmt.Sprintf(
+			"%d %d * * %s",
+			int(d%time.Hour/time.Minute),
+			int(d/time.Hour),
+			strings.Join(values, string(ListSeparator)),
+		)
+		if seconds {
+			cron = strconv.Itoa(int(d%time.Minute/time.Second)) + " " + cron
+		}
+		s, err := New(cron, loc, opts...)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return &Composite{schedules: schedules}, nil
+}
+
+// parseTimeOfDay parses a "15:04" or "15:04:05" time of day, returning
+// the time since midnight, and whether seconds were given
+func parseTimeOfDay(ts string) (time.Duration, bool, error) {
+	layout := "15:04"
+	withSeconds := strings.Count(ts, ":") == 2
+	if withSeconds {
+		layout = "15:04:05"
+	}
+	t, err := time.Parse(layout, ts)
+	if err != nil {
+		return 0, false, err
+	}
+	d := time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	return d, withSeconds, nil
+}
+
+// Schedules returns the schedules the composite is made of
+func (c *Composite) Schedules() []*Schedule {
+	return slices.Clone(c.schedules)
+}
+
+// Next returns the earliest scheduled time of any of the composite's
+// schedules after the given time, or the zero time if none of them
+// have an upcoming scheduled time
+func (c *Composite) Next(t time.Time) time.Time {
+	next, _ := NextAny(t, c.schedules...)
+	return next
+}
+
+// Prev returns the latest scheduled time of any of the composite's
+// schedules before the given time, or the zero time if none of them
+// have a previous scheduled time
+func (c *Composite) Prev(t time.Time) time.Time {
+	var latest time.Time
+	for _, s := range c.schedules {
+		if prev := s.Prev(t); prev.After(latest) {
+			latest = prev
+		}
+	}
+	return latest
+}
+
+// Matches returns true if any of the composite's schedules
+// match the given time
+func (c *Composite) Matches(t time.Time) bool {
+	return slices.ContainsFunc(
+		c.schedules, func(s *Schedule) bool {
+			return s.Matches(t)
+		},
+	)
+}
+
+// String returns the expressions of the composite's
+// schedules, separated by semicolons
+func (c *Composite) String() string {
+	exprs := make([]string, len(c.schedules))
+	for i, s := range c.schedules {
+		exprs[i] = s.String()
+	}
+	return strings.Join(exprs, "; ")
+}
@@ -0,0 +1,107 @@
+package crong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewWeekdayTimes(t *testing.T) {
+	c, err := NewWeekdayTimes(
+		map[time.Weekday][]string{
+			time.Monday:    {"09:00"},
+			time.Tuesday:   {"09:00"},
+			time.Wednesday: {"09:00"},
+			time.Thursday:  {"09:00"},
+			time.Friday:    {"17:00", "9:00"},
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, c.String(), "0 9 * * 1,2,3,4,5; 0 17 * * 5")
+	assertEqual(t, len(c.Schedules()), 2)
+
+	// 2024-03-07 is a Thursday
+	thursday := time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC)
+	friday9 := time.Date(2024, 3, 8, 9, 0, 0, 0, time.UTC)
+	friday17 := time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC)
+	assertEqual(t, c.Next(thursday), friday9)
+	assertEqual(t, c.Next(friday9), friday17)
+	assertEqual(t, c.Next(friday17), monday)
+	assertEqual(t, c.Prev(monday), friday17)
+	assertEqual(t, c.Prev(friday17), friday9)
+	assertEqual(t, c.Prev(friday9), time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC))
+	assertEqual(t, c.Matches(friday17), true)
+	assertEqual(t, c.Matches(thursday), false)
+	assertEqual(t, c.Matches(time.Date(2024, 3, 7, 17, 0, 0, 0, time.UTC)), false)
+}
+
+func TestNewWeekdayTimesLocation(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c, err := NewWeekdayTimes(
+		map[time.Weekday][]string{
+			time.Saturday: {"09:30:15"},
+			time.Sunday:   {"17:00"},
+		},
+		newYork,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, c.String(), "15 30 9 * * 6; 0 0 17 * * 0")
+
+	// DST starts on 2024-03-10 (a Sunday)
+	saturday := time.Date(2024, 3, 9, 9, 30, 15, 0, newYork)
+	sunday := time.Date(2024, 3, 10, 17, 0, 0, 0, newYork)
+	assertEqual(t, c.Next(saturday.Add(-time.Second)), saturday)
+	assertEqual(t, c.Next(saturday), sunday)
+	assertEqual(t, c.Next(sunday).Equal(saturday.AddDate(0, 0, 7)), true)
+	assertEqual(t, c.Prev(sunday), saturday)
+}
+
+func TestNewWeekdayTimesErrors(t *testing.T) {
+	cases := map[string]map[time.Weekday][]string{
+		"empty":       {},
+		"no times":    {time.Monday: nil},
+		"bad time":    {time.Monday: {"25:00"}},
+		"bad format":  {time.Monday: {"9am"}},
+		"bad weekday": {time.Weekday(7): {"09:00"}},
+	}
+	for name, times := range cases {
+		t.Run(
+			name, func(t *testing.T) {
+				_, err := NewWeekdayTimes(times, nil)
+				requireErr(t, err)
+			},
+		)
+	}
+}
+
+func TestNewComposite(t *testing.T) {
+	_, err := NewComposite()
+	requireErr(t, err)
+	_, err = NewComposite(nil)
+	requireErr(t, err)
+
+	hourly, err := New("@hourly", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	at, err := New("@at 2024-01-01T00:30:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c, err := NewComposite(hourly, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assertEqual(t, c.Next(start), at.At())
+	assertEqual(t, c.Next(at.At()), start.Add(time.Hour))
+	assertEqual(t, c.Prev(start.Add(time.Hour)), at.At())
+}
@@ -0,0 +1,56 @@
+package crong
+
+import (
+	"context"
+	"time"
+)
+
+// Countdown returns a channel that receives the time remaining until
+// the schedule's next occurrence after t, every resolution (or every
+// second, if resolution isn't positive). A final 0 is sent when the
+// occurrence is reached, and the channel is closed after it, or when
+// ctx is done. If the receiver falls behind, stale durations are
+// replaced by the latest one rather than queued. If the schedule has
+// no next occurrence, the channel is closed immediately.
+func (s *Schedule) Countdown(
+	ctx context.Context,
+	t time.Time,
+	resolution time.Duration,
+) <-chan time.Duration {
+	if resolution <= 0 {
+		resolution = time.Second
+	}
+	ch := make(chan time.Duration, 1)
+	next := s.Next(t)
+	if next.IsZero() {
+		close(ch)
+		return ch
+	}
+
+	go func() {
+		defer close(ch)
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			remaining := max(time.Until(next), 0)
+			// this is the only sender, so after discarding an unread
+			// value, there's always room for the latest one
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- remaining
+			if remaining == 0 {
+				return
+			}
+			timer.Reset(min(resolution, remaining))
+		}
+	}()
+	return ch
+}
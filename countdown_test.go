@@ -0,0 +1,69 @@
+package crong
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCountdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	at := time.Now().Add(300 * time.Millisecond)
+	s, err := New(At+" "+at.Format(time.RFC3339Nano), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var received []time.Duration
+	for d := range s.Countdown(ctx, time.Now(), 50*time.Millisecond) {
+		received = append(received, d)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("countdown didn't finish: %v", received)
+	}
+	if len(received) < 3 {
+		t.Fatalf("expected several durations, got %v", received)
+	}
+	for i := 1; i < len(received); i++ {
+		if received[i] >= received[i-1] {
+			t.Errorf("expected decreasing durations, got %v", received)
+		}
+	}
+	assertEqual(t, received[len(received)-1], time.Duration(0))
+	if time.Now().Before(at) {
+		t.Errorf("countdown finished early")
+	}
+
+	// nothing left to count down to
+	_, ok := <-s.Countdown(ctx, at.Add(time.Minute), time.Second)
+	assertEqual(t, ok, false)
+}
+
+func TestCountdownCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s, err := New(Yearly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ch := s.Countdown(ctx, time.Now(), time.Millisecond)
+	first := <-ch
+	if first <= 0 {
+		t.Errorf("expected a positive duration, got %s", first)
+	}
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("channel wasn't closed")
+		}
+	}
+}
@@ -0,0 +1,348 @@
+// Package crongrpc serves a [crong.Scheduler] over gRPC (see
+// [crongpb.SchedulerServiceServer]), so the jobs of a fleet of workers
+// can be listed, added, removed, suspended, resumed, run and inspected
+// from a central controller.
+//
+// Job functions can't be sent over the wire, so jobs added remotely
+// run one of the handlers registered with the server by name:
+//
+//	srv, err := crongrpc.NewServer(scheduler, crongrpc.ServerOptions{
+//		Handlers: map[string]crongrpc.Handler{"report": runReport},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	gs := grpc.NewServer()
+//	crongpb.RegisterSchedulerServiceServer(gs, srv)
+//
+// Jobs are identified by their path from the root scheduler, with
+// names separated by '/' (ex: "tenants/acme/report"), as with
+// [crong.Scheduler.Name].
+//
+// The package is its own module, so crong itself doesn't depend on
+// gRPC.
+package crongrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/arcward/crong"
+	"github.com/arcward/crong/crongrpc/crongpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Handler is a job function that can be scheduled with AddJob
+type Handler func(ctx context.Context, t time.Time) error
+
+// ServerOptions configures a Server
+type ServerOptions struct {
+	// Handlers are the job functions AddJob can schedule, by name
+	Handlers map[string]Handler
+
+	// JobOptions are the options of jobs added with AddJob. If
+	// Name is empty, it's set to the job's path, as with
+	// [crong.Scheduler.Add].
+	JobOptions crong.ScheduledJobOptions
+}
+
+// Server implements [crongpb.SchedulerServiceServer] for a Scheduler
+type Server struct {
+	crongpb.UnimplementedSchedulerServiceServer
+
+	scheduler *crong.Scheduler
+	options   ServerOptions
+}
+
+// NewServer returns a Server that manages the jobs of scheduler,
+// and of its child schedulers
+func NewServer(scheduler *crong.Scheduler, opts ServerOptions) (*Server, error) {
+	if scheduler == nil {
+		return nil, errors.New("scheduler cannot be nil")
+	}
+	for name, h := range opts.Handlers {
+		if name == "" {
+			return nil, errors.New("handler name cannot be empty")
+		}
+		if h == nil {
+			return nil, fmt.Errorf("handler '%s' is nil", name)
+		}
+	}
+	return &Server{scheduler: scheduler, options: opts}, nil
+}
+
+// ListJobs lists the jobs of the requested scheduler (and of its
+// children, if req.Recursive is set), sorted by path
+func (s *Server) ListJobs(
+	_ context.Context,
+	req *crongpb.ListJobsRequest,
+) (*crongpb.ListJobsResponse, error) {
+	scheduler, err := s.child(req.GetScheduler())
+	if err != nil {
+		return nil, err
+	}
+	resp := &crongpb.ListJobsResponse{}
+	var add func(snap crong.SchedulerSnapshot)
+	add = func(snap crong.SchedulerSnapshot) {
+		for _, js := range snap.Jobs {
+			resp.Jobs = append(resp.Jobs, newJob(join(snap.Name, js.Name), js))
+		}
+		if req.GetRecursive() {
+			for _, child := range snap.Children {
+				add(child)
+			}
+		}
+	}
+	add(scheduler.Snapshot(0))
+	return resp, nil
+}
+
+// AddJob adds and starts a job running the requested handler
+// (see [crong.Scheduler.Add]). The job runs until it's removed or its
+// scheduler is stopped, not just for the duration of the call.
+func (s *Server) AddJob(ctx context.Context, req *crongpb.AddJobRequest) (*crongpb.Job, error) {
+	h, ok := s.options.Handlers[req.GetHandler()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown handler '%s'", req.GetHandler())
+	}
+	loc := time.UTC
+	if req.GetLocation() != "" {
+		var err error
+		loc, err = time.LoadLocation(req.GetLocation())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	schedule, err := crong.New(req.GetSchedule(), loc)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	scheduler, name, err := s.split(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if scheduler.Job(name) != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "job '%s' already exists", req.GetPath())
+	}
+
+	job, err := scheduler.Add(context.WithoutCancel(ctx), name, schedule, s.options.JobOptions, h)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return newJob(req.GetPath(), job.Snapshot(0)), nil
+}
+
+// RemoveJob stops and removes a job (see [crong.Scheduler.Remove])
+func (s *Server) RemoveJob(
+	ctx context.Context,
+	req *crongpb.RemoveJobRequest,
+) (*crongpb.RemoveJobResponse, error) {
+	scheduler, name, err := s.split(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if !scheduler.Remove(ctx, name) {
+		return nil, notFound(req.GetPath())
+	}
+	return &crongpb.RemoveJobResponse{}, nil
+}
+
+// SuspendJob suspends a job (see [crong.ScheduledJob.Suspend]). It
+// returns a FailedPrecondition error if the job isn't started.
+func (s *Server) SuspendJob(_ context.Context, req *crongpb.SuspendJobRequest) (*crongpb.Job, error) {
+	job, err := s.job(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if !job.Suspend() && job.State() != crong.ScheduleSuspended {
+		return nil, status.Errorf(codes.FailedPrecondition, "job '%s' is %s", req.GetPath(), job.State())
+	}
+	return newJob(req.GetPath(), job.Snapshot(0)), nil
+}
+
+// ResumeJob resumes a suspended job (see [crong.ScheduledJob.Resume]).
+// It returns a FailedPrecondition error if the job is stopped.
+func (s *Server) ResumeJob(_ context.Context, req *crongpb.ResumeJobRequest) (*crongpb.Job, error) {
+	job, err := s.job(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if !job.Resume() && job.State() != crong.ScheduleStarted {
+		return nil, status.Errorf(codes.FailedPrecondition, "job '%s' is %s", req.GetPath(), job.State())
+	}
+	return newJob(req.GetPath(), job.Snapshot(0)), nil
+}
+
+// RunJob triggers a run of a job (see [crong.ScheduledJob.Trigger]).
+// It returns a FailedPrecondition error if the job isn't running, and
+// an AlreadyExists error if a triggered run is already pending.
+func (s *Server) RunJob(_ context.Context, req *crongpb.RunJobRequest) (*crongpb.RunJobResponse, error) {
+	job, err := s.job(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	switch err := job.Trigger(); {
+	case errors.Is(err, crong.ErrTriggerPending):
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &crongpb.RunJobResponse{}, nil
+}
+
+// GetHistory returns up to req.Limit of a job's most recent finished
+// runs, oldest first, or all of its retained runs if req.Limit is zero
+// (see [crong.ScheduledJob.Runtimes])
+func (s *Server) GetHistory(
+	_ context.Context,
+	req *crongpb.GetHistoryRequest,
+) (*crongpb.GetHistoryResponse, error) {
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit cannot be negative")
+	}
+	job, err := s.job(req.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = math.MaxInt
+	}
+	snap := job.Snapshot(limit)
+	resp := &crongpb.GetHistoryResponse{
+		Runs: make([]*crongpb.Run, 0, len(snap.Runtimes)),
+	}
+	for _, rt := range snap.Runtimes {
+		run := &crongpb.Run{
+			RunId:     rt.RunID,
+			Scheduled: timestamp(rt.Scheduled),
+			Reason:    runReason(rt.Reason),
+			Start:     timestamp(rt.Start),
+			End:       timestamp(rt.End),
+		}
+		if rt.Error != nil {
+			run.Error = rt.Error.Error()
+		}
+		resp.Runs = append(resp.Runs, run)
+	}
+	return resp, nil
+}
+
+// child returns the scheduler at the given path from the
+// root scheduler
+func (s *Server) child(path string) (*crong.Scheduler, error) {
+	scheduler := s.scheduler
+	if path == "" {
+		return scheduler, nil
+	}
+	for _, name := range strings.Split(path, "/") {
+		if scheduler = scheduler.Child(name); scheduler == nil {
+			return nil, status.Errorf(codes.NotFound, "scheduler '%s' not found", path)
+		}
+	}
+	return scheduler, nil
+}
+
+// split returns the scheduler of the job at the given path,
+// and the job's name in it
+func (s *Server) split(path string) (*crong.Scheduler, string, error) {
+	if path == "" {
+		return nil, "", status.Error(codes.InvalidArgument, "job path cannot be empty")
+	}
+	dir, name := "", path
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		dir, name = path[:i], path[i+1:]
+	}
+	if name == "" {
+		return nil, "", status.Errorf(codes.InvalidArgument, "invalid job path '%s'", path)
+	}
+	scheduler, err := s.child(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	return scheduler, name, nil
+}
+
+// job returns the job at the given path
+func (s *Server) job(path string) (*crong.ScheduledJob, error) {
+	scheduler, name, err := s.split(path)
+	if err != nil {
+		return nil, err
+	}
+	job := scheduler.Job(name)
+	if job == nil {
+		return nil, notFound(path)
+	}
+	return job, nil
+}
+
+func notFound(path string) error {
+	return status.Errorf(codes.NotFound, "job '%s' not found", path)
+}
+
+// join returns name prefixed with the scheduler path dir
+func join(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
+
+func newJob(path string, snap crong.JobSnapshot) *crongpb.Job {
+	return &crongpb.Job{
+		Path:                path,
+		Schedule:            snap.Schedule,
+		Location:            snap.Location,
+		State:               jobState(snap.State),
+		Next:                timestamp(snap.Next),
+		Runs:                snap.Runs,
+		Running:             snap.Running,
+		Failures:            snap.Failures,
+		ConsecutiveFailures: snap.ConsecutiveFailures,
+		Degraded:            snap.Degraded,
+	}
+}
+
+// timestamp returns t as a Timestamp, or nil if it's the zero time
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func jobState(state crong.ScheduleState) crongpb.JobState {
+	switch state {
+	case crong.ScheduleStarted:
+		return crongpb.JobState_JOB_STATE_STARTED
+	case crong.ScheduleSuspended:
+		return crongpb.JobState_JOB_STATE_SUSPENDED
+	case crong.ScheduleStopped:
+		return crongpb.JobState_JOB_STATE_STOPPED
+	case crong.ScheduleExhausted:
+		return crongpb.JobState_JOB_STATE_EXHAUSTED
+	default:
+		return crongpb.JobState_JOB_STATE_UNSPECIFIED
+	}
+}
+
+func runReason(reason crong.RunReason) crongpb.RunReason {
+	switch reason {
+	case crong.RunScheduled:
+		return crongpb.RunReason_RUN_REASON_SCHEDULED
+	case crong.RunCatchUp:
+		return crongpb.RunReason_RUN_REASON_CATCH_UP
+	case crong.RunTriggered:
+		return crongpb.RunReason_RUN_REASON_TRIGGERED
+	case crong.RunStartup:
+		return crongpb.RunReason_RUN_REASON_STARTUP
+	default:
+		return crongpb.RunReason_RUN_REASON_UNSPECIFIED
+	}
+}
@@ -0,0 +1,368 @@
+package crongrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/arcward/crong"
+	"github.com/arcward/crong/crongrpc/crongpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient serves srv over an in-memory connection, and returns
+// a client for it
+func newClient(t *testing.T, srv *Server) crongpb.SchedulerServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	crongpb.RegisterSchedulerServiceServer(gs, srv)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(
+			func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			},
+		),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return crongpb.NewSchedulerServiceClient(conn)
+}
+
+// requireCode fails the test if err doesn't have the given status code
+func requireCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Fatalf("expected %s, got %s (%v)", code, got, err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	scheduler, err := crong.NewScheduler(crong.SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer scheduler.Stop(ctx)
+	tenants, err := scheduler.NewChild("tenants", crong.SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = tenants.NewChild("acme", crong.SchedulerOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	srv, err := NewServer(
+		scheduler, ServerOptions{
+			Handlers: map[string]Handler{
+				"ok": func(context.Context, time.Time) error { return nil },
+				"fail": func(context.Context, time.Time) error {
+					return errors.New("boom")
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	client := newClient(t, srv)
+
+	job, err := client.AddJob(
+		ctx, &crongpb.AddJobRequest{
+			Path:     "tenants/acme/report",
+			Schedule: crong.Yearly,
+			Location: "America/New_York",
+			Handler:  "fail",
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.GetPath() != "tenants/acme/report" {
+		t.Errorf("expected path 'tenants/acme/report', got '%s'", job.GetPath())
+	}
+	if job.GetLocation() != "America/New_York" {
+		t.Errorf("expected location 'America/New_York', got '%s'", job.GetLocation())
+	}
+	if job.GetState() != crongpb.JobState_JOB_STATE_STARTED {
+		t.Errorf("expected started, got %s", job.GetState())
+	}
+	if job.GetNext() == nil {
+		t.Errorf("expected next time to be set")
+	}
+	if _, err = client.AddJob(
+		ctx, &crongpb.AddJobRequest{
+			Path:     "cleanup",
+			Schedule: crong.Yearly,
+			Handler:  "ok",
+		},
+	); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the job outlives the call that added it
+	if got := scheduler.Child("tenants").Child("acme").Job("report").State(); got != crong.ScheduleStarted {
+		t.Fatalf("expected job to be started, got %s", got)
+	}
+
+	list, err := client.ListJobs(ctx, &crongpb.ListJobsRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(list.GetJobs()) != 1 || list.GetJobs()[0].GetPath() != "cleanup" {
+		t.Errorf("expected only 'cleanup', got %v", list.GetJobs())
+	}
+	list, err = client.ListJobs(ctx, &crongpb.ListJobsRequest{Recursive: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var paths []string
+	for _, j := range list.GetJobs() {
+		paths = append(paths, j.GetPath())
+	}
+	if len(paths) != 2 || paths[0] != "cleanup" || paths[1] != "tenants/acme/report" {
+		t.Errorf("expected [cleanup tenants/acme/report], got %v", paths)
+	}
+	list, err = client.ListJobs(ctx, &crongpb.ListJobsRequest{Scheduler: "tenants/acme"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(list.GetJobs()) != 1 || list.GetJobs()[0].GetPath() != "tenants/acme/report" {
+		t.Errorf("expected only 'tenants/acme/report', got %v", list.GetJobs())
+	}
+
+	if _, err = client.RunJob(ctx, &crongpb.RunJobRequest{Path: "tenants/acme/report"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var history *crongpb.GetHistoryResponse
+	for {
+		history, err = client.GetHistory(ctx, &crongpb.GetHistoryRequest{Path: "tenants/acme/report"})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(history.GetRuns()) > 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("run wasn't recorded")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	run := history.GetRuns()[0]
+	if run.GetReason() != crongpb.RunReason_RUN_REASON_TRIGGERED {
+		t.Errorf("expected triggered run, got %s", run.GetReason())
+	}
+	if run.GetError() != "boom" {
+		t.Errorf("expected error 'boom', got '%s'", run.GetError())
+	}
+	if run.GetRunId() == "" || run.GetStart() == nil || run.GetEnd() == nil {
+		t.Errorf("expected run ID, start and end to be set, got %v", run)
+	}
+	if run.GetScheduled() != nil {
+		t.Errorf("expected no scheduled time for a triggered run, got %v", run.GetScheduled())
+	}
+
+	job, err = client.SuspendJob(ctx, &crongpb.SuspendJobRequest{Path: "cleanup"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.GetState() != crongpb.JobState_JOB_STATE_SUSPENDED {
+		t.Errorf("expected suspended, got %s", job.GetState())
+	}
+	_, err = client.RunJob(ctx, &crongpb.RunJobRequest{Path: "cleanup"})
+	requireCode(t, err, codes.FailedPrecondition)
+	job, err = client.ResumeJob(ctx, &crongpb.ResumeJobRequest{Path: "cleanup"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.GetState() != crongpb.JobState_JOB_STATE_STARTED {
+		t.Errorf("expected started, got %s", job.GetState())
+	}
+
+	if _, err = client.RemoveJob(ctx, &crongpb.RemoveJobRequest{Path: "cleanup"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if scheduler.Job("cleanup") != nil {
+		t.Errorf("expected job to be removed")
+	}
+	_, err = client.RemoveJob(ctx, &crongpb.RemoveJobRequest{Path: "cleanup"})
+	requireCode(t, err, codes.NotFound)
+}
+
+func TestServerErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	scheduler, err := crong.NewScheduler(crong.SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer scheduler.Stop(ctx)
+	srv, err := NewServer(
+		scheduler, ServerOptions{
+			Handlers: map[string]Handler{
+				"ok": func(context.Context, time.Time) error { return nil },
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	client := newClient(t, srv)
+	if _, err = client.AddJob(
+		ctx, &crongpb.AddJobRequest{Path: "report", Schedule: crong.Yearly, Handler: "ok"},
+	); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{
+			name: "unknown handler",
+			call: func() error {
+				_, err := client.AddJob(
+					ctx, &crongpb.AddJobRequest{Path: "a", Schedule: crong.Yearly, Handler: "nope"},
+				)
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "invalid schedule",
+			call: func() error {
+				_, err := client.AddJob(
+					ctx, &crongpb.AddJobRequest{Path: "a", Schedule: "* * *", Handler: "ok"},
+				)
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "invalid location",
+			call: func() error {
+				_, err := client.AddJob(
+					ctx, &crongpb.AddJobRequest{
+						Path:     "a",
+						Schedule: crong.Yearly,
+						Location: "Nowhere/Special",
+						Handler:  "ok",
+					},
+				)
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "empty path",
+			call: func() error {
+				_, err := client.AddJob(
+					ctx, &crongpb.AddJobRequest{Schedule: crong.Yearly, Handler: "ok"},
+				)
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "trailing slash",
+			call: func() error {
+				_, err := client.AddJob(
+					ctx, &crongpb.AddJobRequest{Path: "a/", Schedule: crong.Yearly, Handler: "ok"},
+				)
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "duplicate",
+			call: func() error {
+				_, err := client.AddJob(
+					ctx, &crongpb.AddJobRequest{Path: "report", Schedule: crong.Yearly, Handler: "ok"},
+				)
+				return err
+			},
+			code: codes.AlreadyExists,
+		},
+		{
+			name: "missing scheduler",
+			call: func() error {
+				_, err := client.AddJob(
+					ctx, &crongpb.AddJobRequest{Path: "x/a", Schedule: crong.Yearly, Handler: "ok"},
+				)
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "list missing scheduler",
+			call: func() error {
+				_, err := client.ListJobs(ctx, &crongpb.ListJobsRequest{Scheduler: "x"})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "suspend missing job",
+			call: func() error {
+				_, err := client.SuspendJob(ctx, &crongpb.SuspendJobRequest{Path: "missing"})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "negative limit",
+			call: func() error {
+				_, err := client.GetHistory(ctx, &crongpb.GetHistoryRequest{Path: "report", Limit: -1})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				requireCode(t, tc.call(), tc.code)
+			},
+		)
+	}
+
+	// a stopped job can't be resumed, run or suspended
+	scheduler.Job("report").Stop(ctx)
+	_, err = client.ResumeJob(ctx, &crongpb.ResumeJobRequest{Path: "report"})
+	requireCode(t, err, codes.FailedPrecondition)
+	_, err = client.SuspendJob(ctx, &crongpb.SuspendJobRequest{Path: "report"})
+	requireCode(t, err, codes.FailedPrecondition)
+	_, err = client.RunJob(ctx, &crongpb.RunJobRequest{Path: "report"})
+	requireCode(t, err, codes.FailedPrecondition)
+}
+
+func TestNewServer(t *testing.T) {
+	if _, err := NewServer(nil, ServerOptions{}); err == nil {
+		t.Errorf("expected error for nil scheduler")
+	}
+	scheduler, err := crong.NewScheduler(crong.SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = NewServer(scheduler, ServerOptions{Handlers: map[string]Handler{"a": nil}}); err == nil {
+		t.Errorf("expected error for nil handler")
+	}
+}
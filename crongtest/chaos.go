@@ -0,0 +1,104 @@
+package crongtest
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrInjected is the error returned by failures injected
+// by [Chaos], unless Chaos.Err is set
+var ErrInjected = errors.New("crongtest: injected failure")
+
+// Chaos wraps job functions to inject random failures, delays and
+// panics, for checking a job's failure handling (ex: MaxFailures,
+// ClassifyFailure, SLA and stuck run alerts) behaves as intended.
+// It's meant for tests and staging only.
+//
+// Rates are probabilities from 0 to 1, checked independently on each
+// call: the delay comes first, then the panic, then the failure.
+//
+//	chaos := crongtest.Chaos{FailureRate: 0.2, DelayRate: 0.1, MaxDelay: time.Second}
+//	job := crong.ScheduleFuncContext(ctx, schedule, opts, chaos.Wrap(f))
+type Chaos struct {
+	// FailureRate is the probability of returning Err
+	// instead of calling the function
+	FailureRate float64
+
+	// Err is the error returned for injected failures.
+	// Defaults to ErrInjected.
+	Err error
+
+	// DelayRate is the probability of sleeping before
+	// calling the function
+	DelayRate float64
+
+	// MaxDelay is the longest injected delay. Delays are random,
+	// up to MaxDelay, and end early if the run's context is done.
+	MaxDelay time.Duration
+
+	// PanicRate is the probability of panicking instead of calling
+	// the function. Jobs recover panics as failed runs (see
+	// [crong.PanicError]), so this checks they're handled like
+	// other failures (ex: counted toward MaxFailures).
+	PanicRate float64
+
+	// Rand, if set, is the source of randomness, so results can be
+	// reproduced with a fixed seed. Calls using it are serialized.
+	Rand *rand.Rand
+}
+
+// Wrap returns f, with failures, delays and panics injected
+func (c Chaos) Wrap(
+	f func(ctx context.Context, t time.Time) error,
+) func(ctx context.Context, t time.Time) error {
+	var mu sync.Mutex
+	random := func() float64 {
+		if c.Rand == nil {
+			return rand.Float64()
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return c.Rand.Float64()
+	}
+	roll := func(rate float64) bool {
+		return rate > 0 && random() < rate
+	}
+
+	return func(ctx context.Context, t time.Time) error {
+		if roll(c.DelayRate) && c.MaxDelay > 0 {
+			delay := time.Duration(random() * float64(c.MaxDelay))
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+		if roll(c.PanicRate) {
+			panic("crongtest: injected panic")
+		}
+		if roll(c.FailureRate) {
+			if c.Err != nil {
+				return c.Err
+			}
+			return ErrInjected
+		}
+		return f(ctx, t)
+	}
+}
+
+// WrapFunc is [Chaos.Wrap] for functions that don't
+// take a context
+func (c Chaos) WrapFunc(f func(t time.Time) error) func(t time.Time) error {
+	wrapped := c.Wrap(
+		func(_ context.Context, t time.Time) error {
+			return f(t)
+		},
+	)
+	return func(t time.Time) error {
+		return wrapped(context.Background(), t)
+	}
+}
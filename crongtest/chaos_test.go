@@ -0,0 +1,123 @@
+package crongtest
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/arcward/crong"
+)
+
+func TestChaos(t *testing.T) {
+	calls := 0
+	f := func(ctx context.Context, t time.Time) error {
+		calls++
+		return nil
+	}
+	ctx := context.Background()
+
+	errBoom := errors.New("boom")
+	failing := Chaos{FailureRate: 1, Err: errBoom}.Wrap(f)
+	if err := failing(ctx, time.Now()); !errors.Is(err, errBoom) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected the function not to be called")
+	}
+
+	// with a fixed seed, the same calls fail
+	results := func() []bool {
+		wrapped := Chaos{
+			FailureRate: 0.5,
+			Rand:        rand.New(rand.NewPCG(1, 2)),
+		}.Wrap(f)
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			failed = append(failed, wrapped(ctx, time.Now()) != nil)
+		}
+		return failed
+	}
+	first, second := results(), results()
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the same results with the same seed")
+		}
+		if first[i] {
+			failures++
+		}
+	}
+	if failures == 0 || failures == len(first) {
+		t.Errorf("expected some failures, got %d of %d", failures, len(first))
+	}
+
+	// delays end when the context is done
+	delayed := Chaos{DelayRate: 1, MaxDelay: time.Hour}.Wrap(f)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := delayed(canceled, time.Now()); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	panicking := Chaos{PanicRate: 1}.WrapFunc(
+		func(t time.Time) error { return nil },
+	)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	_ = panicking(time.Now())
+}
+
+func TestChaosPanicMaxFailures(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schedule, err := crong.New(crong.Yearly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	job := crong.ScheduleFuncContext(
+		ctx,
+		schedule,
+		crong.ScheduledJobOptions{MaxFailures: 3},
+		Chaos{PanicRate: 1}.Wrap(
+			func(ctx context.Context, t time.Time) error { return nil },
+		),
+	)
+	defer job.Stop(ctx)
+
+	// each panic is a failed run, until the job stops
+	at := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if !TickJob(ctx, job, at.Add(time.Duration(i)*time.Minute)) {
+			t.Fatalf("expected tick %d to be sent", i)
+		}
+	}
+	for job.State() != crong.ScheduleStopped {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("job didn't stop, failures: %d", job.Failures.Load())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	if got := job.StopReason(); got != crong.StopMaxFailures {
+		t.Errorf("expected %s, got %s", crong.StopMaxFailures, got)
+	}
+	runtimes := job.Runtimes()
+	if len(runtimes) != 3 {
+		t.Fatalf("expected 3 runtimes, got %d", len(runtimes))
+	}
+	for _, rt := range runtimes {
+		var panicErr *crong.PanicError
+		if !errors.As(rt.Error, &panicErr) {
+			t.Fatalf("expected a PanicError, got %v", rt.Error)
+		}
+		if panicErr.Value != "crongtest: injected panic" || len(panicErr.Stack) == 0 {
+			t.Errorf("unexpected panic error: %#v", panicErr)
+		}
+	}
+}
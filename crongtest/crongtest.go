@@ -0,0 +1,59 @@
+// Package crongtest provides helpers for testing code that uses
+// crong tickers and scheduled jobs, without waiting on the clock.
+//
+// Tickers (and the tickers of scheduled jobs) still tick on their
+// schedule, so tests usually use a schedule that won't fire while
+// they run (ex: "@yearly"), and send ticks with [Tick] or [TickJob]:
+//
+//	job := crong.ScheduleFunc(ctx, schedule, crong.ScheduledJobOptions{}, f)
+//	defer job.Stop(ctx)
+//	crongtest.TickJob(ctx, job, time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC))
+//	if err := crongtest.WaitForRuns(ctx, job, 1); err != nil {
+//		t.Fatal(err)
+//	}
+package crongtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/arcward/crong"
+	"github.com/arcward/crong/internal/testhook"
+)
+
+// pollInterval is how often WaitForRuns checks a job's runs
+const pollInterval = 5 * time.Millisecond
+
+// Tick sends a tick for the given time (or the current time, if it's
+// zero) on the ticker, as if its schedule had fired. It waits for the
+// ticker to accept the tick, and returns false if the ticker stopped
+// or ctx was done first. Like scheduled ticks, the tick is then
+// delivered on Ticker.C or Ticker.Ticks according to the ticker's
+// options.
+func Tick(ctx context.Context, ticker *crong.Ticker, at time.Time) bool {
+	return testhook.Tick(ctx, ticker, at)
+}
+
+// TickJob sends a tick for the given time (or the current time, if
+// it's zero) to the job's ticker, as if its schedule had fired. The
+// job handles it as it would a scheduled tick (ex: it's skipped if
+// the job is suspended). It returns false if the job stopped or ctx
+// was done before the tick was accepted.
+func TickJob(ctx context.Context, job *crong.ScheduledJob, at time.Time) bool {
+	return testhook.Tick(ctx, job, at)
+}
+
+// WaitForRuns waits until the job has finished at least n runs,
+// returning ctx's error if it's done first
+func WaitForRuns(ctx context.Context, job *crong.ScheduledJob, n int) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for len(job.Runtimes()) < n {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package crongtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arcward/crong"
+)
+
+func TestTick(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schedule, err := crong.New(crong.Yearly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := crong.NewTicker(ctx, schedule, 0)
+	defer ticker.Stop()
+
+	at := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	if !Tick(ctx, ticker, at) {
+		t.Fatalf("expected tick to be sent")
+	}
+	select {
+	case got := <-ticker.C:
+		if !got.Equal(at) {
+			t.Errorf("expected %s, got %s", at, got)
+		}
+	case <-ctx.Done():
+		t.Fatalf("tick wasn't received")
+	}
+
+	ticker.Stop()
+	<-ticker.Done()
+	if Tick(ctx, ticker, at) {
+		t.Errorf("expected tick not to be sent after stopping")
+	}
+}
+
+func TestTickJob(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schedule, err := crong.New(crong.Yearly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var mu sync.Mutex
+	var ran []time.Time
+	job := crong.ScheduleFunc(
+		ctx,
+		schedule,
+		crong.ScheduledJobOptions{},
+		func(t time.Time) error {
+			mu.Lock()
+			defer mu.Unlock()
+			ran = append(ran, t)
+			return nil
+		},
+	)
+	defer job.Stop(ctx)
+
+	at := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	if !TickJob(ctx, job, at) {
+		t.Fatalf("expected tick to be sent")
+	}
+	if err = WaitForRuns(ctx, job, 1); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ran) != 1 || !ran[0].Equal(at) {
+		t.Errorf("expected a single run at %s, got %v", at, ran)
+	}
+}
+
+func TestWaitForRunsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	schedule, err := crong.New(crong.Yearly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	job := crong.ScheduleFunc(
+		ctx,
+		schedule,
+		crong.ScheduledJobOptions{},
+		func(t time.Time) error { return nil },
+	)
+	defer job.Stop(ctx)
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer waitCancel()
+	if err = WaitForRuns(waitCtx, job, 100); err == nil {
+		t.Errorf("expected error")
+	}
+}
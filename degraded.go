@@ -0,0 +1,110 @@
+package crong
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultDegradedWindow is the window the failure rate is measured
+// over, if [ScheduledJobOptions.DegradedWindow] isn't set
+const defaultDegradedWindow = time.Hour
+
+// failureWindow tracks the outcomes of a job's runs over a sliding
+// window, to check its failure rate against
+// [ScheduledJobOptions.DegradedFailureRate]
+type failureWindow struct {
+	mu       sync.Mutex
+	outcomes []runOutcome
+	degraded bool
+}
+
+// runOutcome is whether a run that ended at the given time failed
+type runOutcome struct {
+	end    time.Time
+	failed bool
+}
+
+// record adds the outcome of a run, drops the outcomes that ended
+// before the window, and updates whether the job is degraded. It
+// returns the failure rate in the window, and whether the job became
+// (or stopped being) degraded.
+func (w *failureWindow) record(
+	o runOutcome,
+	window time.Duration,
+	threshold float64,
+	minRuns int,
+) (rate float64, changed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.outcomes = append(w.outcomes, o)
+	cutoff := o.end.Add(-window)
+	i := 0
+	for i < len(w.outcomes) && w.outcomes[i].end.Before(cutoff) {
+		i++
+	}
+	w.outcomes = w.outcomes[i:]
+
+	failures := 0
+	for _, outcome := range w.outcomes {
+		if outcome.failed {
+			failures++
+		}
+	}
+	rate = float64(failures) / float64(len(w.outcomes))
+	degraded := len(w.outcomes) >= max(minRuns, 1) && rate > threshold
+	changed = degraded != w.degraded
+	w.degraded = degraded
+	return rate, changed
+}
+
+// Degraded returns true if the job's failure rate is over
+// [ScheduledJobOptions.DegradedFailureRate]. It's updated as
+// runs finish.
+func (s *ScheduledJob) Degraded() bool {
+	s.outcomes.mu.Lock()
+	defer s.outcomes.mu.Unlock()
+	return s.outcomes.degraded
+}
+
+// recordOutcome checks the job's failure rate once a run ends,
+// calling OnDegraded if the job became degraded
+func (s *ScheduledJob) recordOutcome(runID string, end time.Time, failed bool) {
+	threshold := s.options.DegradedFailureRate
+	if threshold <= 0 {
+		return
+	}
+	window := s.options.DegradedWindow
+	if window <= 0 {
+		window = defaultDegradedWindow
+	}
+	rate, changed := s.outcomes.record(
+		runOutcome{end: end, failed: failed},
+		window,
+		threshold,
+		s.options.DegradedMinRuns,
+	)
+	switch {
+	case !changed:
+	case s.Degraded():
+		jobLogger().Warn(
+			"failure rate over threshold, job degraded",
+			"run_id", runID,
+			"failure_rate", rate,
+			"threshold", threshold,
+			"window", window,
+			"scheduled_job", s,
+		)
+		if f := s.options.OnDegraded; f != nil {
+			f(rate)
+		}
+	default:
+		jobLogger().Info(
+			"failure rate back under threshold, job recovered",
+			"run_id", runID,
+			"failure_rate", rate,
+			"threshold", threshold,
+			"window", window,
+			"scheduled_job", s,
+		)
+	}
+}
@@ -0,0 +1,87 @@
+package crong
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobDegraded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var mu sync.Mutex
+	now := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	degradedCh := make(chan float64, 4)
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			TickerReceiveTimeout: 5 * time.Second,
+			DegradedFailureRate:  0.5,
+			DegradedMinRuns:      3,
+			OnDegraded: func(rate float64) {
+				degradedCh <- rate
+			},
+			Clock: ClockFunc(
+				func() time.Time {
+					mu.Lock()
+					defer mu.Unlock()
+					return now
+				},
+			),
+		},
+		func(dt time.Time) error {
+			if dt.Minute()%2 == 1 {
+				return errors.New("odd minute")
+			}
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	run := func(at time.Time) {
+		t.Helper()
+		mu.Lock()
+		now = at
+		mu.Unlock()
+		want := len(sj.Runtimes()) + 1
+		sj.ticker.inject(ctx, at)
+		waitFor(
+			t, 5*time.Second, func() bool {
+				return len(sj.Runtimes()) == want
+			},
+		)
+	}
+
+	// a single failure isn't enough runs to be degraded
+	run(now.Add(time.Minute))
+	assertEqual(t, sj.Degraded(), false)
+	run(now.Add(time.Minute))
+	assertEqual(t, sj.Degraded(), false)
+
+	// two of three runs failed
+	run(now.Add(time.Minute))
+	assertEqual(t, sj.Degraded(), true)
+	assertEqual(t, sj.Snapshot(0).Degraded, true)
+	if rate := <-degradedCh; rate < 0.66 || rate > 0.67 {
+		t.Errorf("expected a failure rate of 2/3, got %f", rate)
+	}
+
+	// still degraded, so the hook isn't called again
+	run(now.Add(2 * time.Minute))
+	assertEqual(t, sj.Degraded(), true)
+	assertEqual(t, len(degradedCh), 0)
+
+	// the failures age out of the window, and the job recovers
+	run(now.Add(2*time.Hour + time.Minute))
+	assertEqual(t, sj.Degraded(), false)
+	assertEqual(t, len(degradedCh), 0)
+	assertEqual(t, sj.Failures.Load(), int64(3))
+}
@@ -0,0 +1,233 @@
+package crong
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// FieldDiff describes how the values included in one field changed
+// between two schedules
+type FieldDiff struct {
+	// Field is the name of the field (ex: "hour")
+	Field string
+
+	// Added holds the values only the new schedule includes,
+	// in ascending order
+	Added []string
+
+	// Removed holds the values only the old schedule includes,
+	// in ascending order
+	Removed []string
+}
+
+// String describes the change (ex: "hour: added 18, removed 6")
+func (d FieldDiff) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.Field)
+	sb.WriteString(":")
+	if len(d.Added) > 0 {
+		sb.WriteString(" added ")
+		sb.WriteString(strings.Join(d.Added, string(ListSeparator)))
+	}
+	if len(d.Removed) > 0 {
+		if len(d.Added) > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(" removed ")
+		sb.WriteString(strings.Join(d.Removed, string(ListSeparator)))
+	}
+	return sb.String()
+}
+
+// ScheduleDiff describes how a schedule changed (see [Diff])
+type ScheduleDiff struct {
+	// From is the expression of the old schedule
+	From string
+
+	// To is the expression of the new schedule
+	To string
+
+	// Fields holds the fields whose values changed, from
+	// seconds to weekday
+	Fields []FieldDiff
+
+	// FromLocation and ToLocation are the names of the old and
+	// new schedules' locations, if they differ
+	FromLocation string
+	ToLocation   string
+
+	// changed is set if the schedules aren't equivalent
+	changed bool
+}
+
+// Changed returns true if the schedules fire at different times
+func (d ScheduleDiff) Changed() bool {
+	return d.changed
+}
+
+// String describes each change, separated by semicolons
+// (ex: "hour: added 18, removed 6; location: UTC to Europe/Paris")
+func (d ScheduleDiff) String() string {
+	changes := make([]string, 0, len(d.Fields)+1)
+	for _, fd := range d.Fields {
+		changes = append(changes, fd.String())
+	}
+	if d.FromLocation != d.ToLocation {
+		changes = append(
+			changes,
+			"location: "+d.FromLocation+" to "+d.ToLocation,
+		)
+	}
+	if len(changes) == 0 && d.changed {
+		// one-shot schedules on different dates
+		changes = append(changes, d.From+" to "+d.To)
+	}
+	return strings.Join(changes, "; ")
+}
+
+func (d ScheduleDiff) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("from", d.From),
+		slog.String("to", d.To),
+	}
+	for _, fd := range d.Fields {
+		attrs = append(
+			attrs,
+			slog.Group(
+				fd.Field,
+				slog.Any("added", fd.Added),
+				slog.Any("removed", fd.Removed),
+			),
+		)
+	}
+	if d.FromLocation != d.ToLocation {
+		attrs = append(
+			attrs,
+			slog.Group(
+				"location",
+				slog.String("from", d.FromLocation),
+				slog.String("to", d.ToLocation),
+			),
+		)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Diff compares the values each field of schedule a includes with
+// those of schedule b, for audit logs and reviewing edits to a
+// schedule. Fields are compared by the values they include, so
+// rewriting a field (ex: "1-3" to "1,2,3") isn't a change, and
+// wildcards include every value. A schedule without a seconds field
+// only includes the first second of each minute. "L" in the day
+// field is reported as the value "L", weeks of the year as "WY" values
+// (ex: "WY10"), nearest weekdays as "W" values (ex: "15W" or "LW"), the
+// last of a weekday in the month as an "L" value (ex: "5L"), and the nth
+// of a weekday as a "#" value (ex: "5#3"). Weekdays are numbered from 0 (Sunday), unless both
+// schedules were parsed with WithISOWeekdays.
+func Diff(a *Schedule, b *Schedule) ScheduleDiff {
+	d := ScheduleDiff{
+		From:    a.String(),
+		To:      b.String(),
+		changed: !Equivalent(a, b),
+	}
+	if a.loc.String() != b.loc.String() {
+		d.FromLocation = a.loc.String()
+		d.ToLocation = b.loc.String()
+	}
+
+	// weekdays are numbered as ISO 8601 does if both schedules are
+	iso := a.options.isoWeekdays && b.options.isoWeekdays
+	weekdays := weekdayOpts
+	if iso {
+		weekdays = isoWeekdayOpts
+	}
+
+	as, bs := a.valueSets(), b.valueSets()
+	fields := []field{
+		secondOpts,
+		minuteOpts,
+		hourOpts,
+		dayOpts,
+		monthOpts,
+		weekdays,
+	}
+	for _, f := range fields {
+		i := f.Index
+		if f.Index == secondInd {
+			i = len(as) - 1
+		}
+		fd := FieldDiff{Field: f.Name}
+		for _, v := range f.Allowed {
+			bit := v
+			if iso && f.Index == weekdayInd {
+				bit = v % 7
+			}
+			switch {
+			case bs[i].has(bit) && !as[i].has(bit):
+				fd.Added = append(fd.Added, strconv.Itoa(v))
+			case as[i].has(bit) && !bs[i].has(bit):
+				fd.Removed = append(fd.Removed, strconv.Itoa(v))
+			}
+		}
+		if f.Index == dayInd {
+			aLast, bLast := a.lastDay, b.lastDay
+			switch {
+			case bLast && !aLast:
+				fd.Added = append(fd.Added, string(Last))
+			case aLast && !bLast:
+				fd.Removed = append(fd.Removed, string(Last))
+			}
+			for week := 1; week <= 53; week++ {
+				token := fmt.Sprintf("%cY%d", Week, week)
+				switch {
+				case b.weekOfYearSet.has(week) && !a.weekOfYearSet.has(week):
+					fd.Added = append(fd.Added, token)
+				case a.weekOfYearSet.has(week) && !b.weekOfYearSet.has(week):
+					fd.Removed = append(fd.Removed, token)
+				}
+			}
+			for day := 0; day <= f.Max(); day++ {
+				token := strconv.Itoa(day) + string(Week)
+				if day == 0 {
+					token = string(Last) + string(Week)
+				}
+				switch {
+				case b.nearestWeekdaySet.has(day) && !a.nearestWeekdaySet.has(day):
+					fd.Added = append(fd.Added, token)
+				case a.nearestWeekdaySet.has(day) && !b.nearestWeekdaySet.has(day):
+					fd.Removed = append(fd.Removed, token)
+				}
+			}
+		}
+		if f.Index == weekdayInd {
+			for _, v := range f.Allowed {
+				bit := v % 7
+				token := strconv.Itoa(v) + string(Last)
+				switch {
+				case b.lastWeekdaySet.has(bit) && !a.lastWeekdaySet.has(bit):
+					fd.Added = append(fd.Added, token)
+				case a.lastWeekdaySet.has(bit) && !b.lastWeekdaySet.has(bit):
+					fd.Removed = append(fd.Removed, token)
+				}
+			}
+			for _, v := range f.Allowed {
+				for n := 1; n <= 5; n++ {
+					bit := nthWeekdayBit(v%7, n)
+					token := fmt.Sprintf("%d%c%d", v, Nth, n)
+					switch {
+					case b.nthWeekdaySet.has(bit) && !a.nthWeekdaySet.has(bit):
+						fd.Added = append(fd.Added, token)
+					case a.nthWeekdaySet.has(bit) && !b.nthWeekdaySet.has(bit):
+						fd.Removed = append(fd.Removed, token)
+					}
+				}
+			}
+		}
+		if len(fd.Added) > 0 || len(fd.Removed) > 0 {
+			d.Fields = append(d.Fields, fd)
+		}
+	}
+	return d
+}
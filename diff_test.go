@@ -0,0 +1,98 @@
+package crong
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	type diffCase struct {
+		From   string
+		To     string
+		Opts   []ParseOption
+		Expect string
+	}
+	cases := []diffCase{
+		{From: "0 6 * * *", To: "0 18 * * *", Expect: "hour: added 18, removed 6"},
+		{From: "0 9-11 * * *", To: "0 9,10,11 * * *", Expect: ""},
+		{From: "0 0 * * 1-5", To: "0 0 * * *", Expect: "weekday: added 0,6"},
+		{From: "*/20 * * * *", To: "0 * * * *", Expect: "minute: removed 20,40"},
+		{From: "0 0 L * *", To: "0 0 1 * *", Expect: "day: added 1, removed L"},
+		{
+			From:   "0 0 * JAN *",
+			To:     "30 12 * FEB *",
+			Expect: "minute: added 30, removed 0; hour: added 12, removed 0; month: added 2, removed 1",
+		},
+		{
+			From:   "@daily",
+			To:     "*/30 0 0 * * *",
+			Opts:   []ParseOption{WithSeconds()},
+			Expect: "second: added 30",
+		},
+		{
+			From:   "@at 2024-01-01T00:00:00Z",
+			To:     "@at 2025-01-01T00:00:00Z",
+			Expect: "@at 2024-01-01T00:00:00Z to @at 2025-01-01T00:00:00Z",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.From+"|"+tc.To, func(t *testing.T) {
+				a, err := New(tc.From, nil, tc.Opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				b, err := New(tc.To, nil, tc.Opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				d := Diff(a, b)
+				assertEqual(t, d.String(), tc.Expect)
+				assertEqual(t, d.Changed(), tc.Expect != "")
+				assertEqual(t, d.From, a.String())
+				assertEqual(t, d.To, b.String())
+			},
+		)
+	}
+
+	t.Run(
+		"location", func(t *testing.T) {
+			a, err := New("0 6 * * *", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			paris, err := time.LoadLocation("Europe/Paris")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			b, err := New("0 18 * * *", paris)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			d := Diff(a, b)
+			assertEqual(t, d.Changed(), true)
+			assertEqual(
+				t,
+				d.String(),
+				"hour: added 18, removed 6; location: UTC to Europe/Paris",
+			)
+
+			buf := &bytes.Buffer{}
+			logger := slog.New(slog.NewTextHandler(buf, nil))
+			logger.Info("schedule changed", "diff", d)
+			out := buf.String()
+			for _, s := range []string{
+				"diff.hour.added=[18]",
+				"diff.hour.removed=[6]",
+				"diff.location.to=Europe/Paris",
+			} {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected %q in %q", s, out)
+				}
+			}
+		},
+	)
+}
@@ -10,9 +10,15 @@ as well as less standard expressions. For example, `5/10 4,5 * *` means
 
 Days of the week are indexed 0-6, with 0 being Sunday, and can be
 referenced by name (SUN, MON, TUE, WED, THU, FRI, SAT) or by number.
+As in Vixie cron, 7 is also Sunday (ex: `1-7` is Monday to Sunday,
+as is `MON-SUN`).
+With the WithISOWeekdays option, they're indexed 1-7 as in ISO 8601,
+with 1 being Monday and 7 being Sunday.
 
 Months are indexed 1-12, and can be referenced by
 name (JAN, FEB, MAR, APR, MAY, JUN, JUL, AUG, SEP, OCT, NOV, DEC) or by number.
+Names can be used anywhere numbers can, including in ranges, steps
+and lists (ex: `JAN-MAR`, `MON-FRI/2`, `SAT,SUN`).
 
 Cron macros supported:
 
@@ -21,6 +27,9 @@ Cron macros supported:
 	@weekly - Run once a week, midnight between Saturday and Sunday
 	@daily (or @midnight) - Run once a day, midnight
 	@hourly - Run once an hour, beginning of hour
+	@at <timestamp> - Run once, at the given RFC 3339 timestamp
+	@every <duration> - Run at a fixed interval (ex: @every 90s)
+	@reboot - Run once, when a ScheduledJob is started
 
 Other characters supported:
 
@@ -29,6 +38,54 @@ Other characters supported:
   - - range of values
     / - step values
     ? - no specific value (month, day of month, day of week only)
-    L - last day of month (when used, must be used alone)
+    L - last day of month (day of month), or last of a weekday in the month (5L)
+    W - week of month, W1 to W5 (day of month only, ex: W2 is days 8-14)
+    W - nearest weekday (15W), or last weekday of month (LW) (day of month only)
+    WY - ISO 8601 week of year, WY1 to WY53 (day of month only)
+    # - nth weekday of month, 1 to 5 (day of week only, ex: 5#3 is the third Friday)
+    H - value derived from a hash key (see WithHashKey), ex: H, H(0-7), H/15
+
+A step applies only to the list entry it follows, so `1-10,20-30/5`
+is 1 through 10, then 20, 25 and 30. A step after a single value runs
+to the end of the field, so `1,5,9/2` is 1, 5, and every other value
+from 9. Entries with more than one step (`1-10/2/3`) or range
+(`1-5-10`), wildcards in a range (`*-5`), and 'L' or weeks in a range
+or with a step are rejected as ambiguous.
+
+In the day of month field, 'L' may be used alone or as a list entry.
+'W' after a day is the weekday (Monday to Friday) nearest that day,
+without leaving the month (ex: `15W` is Friday the 14th if the 15th is
+a Saturday, and `1W` is Monday the 3rd if the 1st is a Saturday), and
+`LW` is the last weekday of the month.
+In the day of week field, it follows a weekday, for the last of that
+weekday in the month (ex: `0 22 * * 5L` is 22:00 on the last Friday).
+'#' follows a weekday, for the nth of that weekday in the month (ex:
+`0 9 * * MON#1` is 09:00 on the first Monday). Months without a fifth
+of the weekday are skipped for `#5`.
+
+'H' spreads the load of many jobs with the same expression, as in
+Jenkins: `H H(0-7) * * *` is once a day, at a minute and an hour
+between 00:00 and 07:59 derived from the key set with WithHashKey
+(ex: the job's name), so each job fires at the same time every day,
+but not at the same time as the others.
+
+The day of month and day of week fields must both match, so weeks
+select the nth weekday of the month (ex: `0 9 W2 * TUE` is 09:00 on
+the second Tuesday), or weekdays in given weeks of the year (ex:
+`0 9 WY1,WY27 * MON`). With the WithDomDowUnion option, days matching
+either field are included instead, as in Vixie cron (ex: `0 0 1 * MON`
+is midnight on the 1st, and on every Monday).
+
+Expressions with a leading seconds field (ex: `30 0 12 * * *`) can be
+parsed with the WithSeconds option.
+
+An expression can start with its time zone, as a CRON_TZ (or TZ)
+prefix, overriding the location it's parsed with (ex:
+`CRON_TZ=America/New_York 0 9 * * MON-FRI`).
+
+WithParseMode(ParseStrict) rejects non-standard constructs, like the
+step shorthand (`5/10`), while WithParseMode(ParseLenient) accepts
+fields separated by runs of spaces or tabs, and a trailing comment
+(ex: `0  0 * * *  # daily`).
 */
 package crong
@@ -0,0 +1,74 @@
+package crong
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// newEvery finishes a fixed-interval (@every) schedule, running
+// every interval given as a duration (ex: "90s")
+func newEvery(s *Schedule, ds string) (*Schedule, error) {
+	d, err := time.ParseDuration(ds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s interval '%s': %w", Every, ds, err)
+	}
+	if d < time.Second || d%time.Second != 0 {
+		return nil, fmt.Errorf(
+			"invalid %s interval '%s': must be a whole number of seconds, of at least 1s",
+			Every,
+			ds,
+		)
+	}
+	s.every = d
+
+	// the schedule can fire at any time of day, so the field
+	// accessors return wildcards
+	s.values = [5]string{
+		string(Any),
+		string(Any),
+		string(Any),
+		string(Any),
+		string(Any),
+	}
+	if s.options.seconds {
+		s.second = string(Any)
+	}
+	return s, s.validate()
+}
+
+// everySeconds returns the interval of an @every schedule in seconds
+func (s *Schedule) everySeconds() int64 {
+	return int64(s.every / time.Second)
+}
+
+// everyFloor returns the latest occurrence of an @every
+// schedule at or before t
+func (s *Schedule) everyFloor(t time.Time) time.Time {
+	secs := s.everySeconds()
+	u := t.Unix()
+	u -= (u%secs + secs) % secs
+	return time.Unix(u, 0).In(s.loc)
+}
+
+// everyCount returns the number of occurrences of an
+// @every schedule from `from` up to (but not including) `to`
+func (s *Schedule) everyCount(from time.Time, to time.Time) int64 {
+	first := s.Ceil(from)
+	if !first.Before(to) {
+		return 0
+	}
+	return (to.Unix()-first.Unix()-1)/s.everySeconds() + 1
+}
+
+// everyAtIndex returns the occurrence of an @every schedule with
+// sequence number n, counting from the first occurrence at or after
+// epoch (see [Schedule.AtIndex])
+func (s *Schedule) everyAtIndex(n int64, epoch time.Time) (time.Time, error) {
+	first := s.Ceil(epoch).Unix()
+	secs := s.everySeconds()
+	if n > (math.MaxInt64-first)/secs {
+		return time.Time{}, ErrUnreachableSchedule
+	}
+	return time.Unix(first+n*secs, 0).In(s.loc), nil
+}
@@ -0,0 +1,112 @@
+package crong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	s, err := New("@every 90s", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "@every 1m30s")
+	assertEqual(t, s.Every(), 90*time.Second)
+	assertEqual(t, s.resolution(), time.Second)
+	if s.Fields() != nil {
+		t.Errorf("expected no fields, got %#v", s.Fields())
+	}
+	if err = CheckRoundTrip(s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// occurrences are multiples of the interval since the Unix epoch
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assertEqual(t, s.Next(ts), ts.Add(90*time.Second))
+	assertEqual(t, s.Prev(ts), ts.Add(-90*time.Second))
+	assertEqual(t, s.Floor(ts.Add(time.Minute)), ts)
+	assertEqual(t, s.Ceil(ts.Add(time.Minute)), ts.Add(90*time.Second))
+	assertEqual(t, s.Ceil(ts), ts)
+	assertEqual(t, s.MatchesSecond(ts.Add(90*time.Second)), true)
+	assertEqual(t, s.MatchesSecond(ts.Add(60*time.Second)), false)
+	// 00:01:30 falls in the minute starting 00:01, but nothing
+	// falls in the minute starting 00:02
+	assertEqual(t, s.Matches(ts.Add(time.Minute)), true)
+	assertEqual(t, s.Matches(ts.Add(2*time.Minute)), false)
+
+	n, err := s.IndexOf(ts.Add(time.Hour), ts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, n, 40)
+	at, err := s.AtIndex(n, ts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, at, ts.Add(time.Hour))
+
+	gap, _, err := s.MaxGap(ts, ts.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, gap, 90*time.Second)
+
+	h := s.Histogram(ts, ts.AddDate(0, 0, 1))
+	assertEqual(t, h.Total, 960)
+
+	if _, err = s.WithMinute("5"); err == nil {
+		t.Errorf("expected an error changing the minute of an @every schedule")
+	}
+}
+
+func TestEveryLongInterval(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err := New("@every 4h30m", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.resolution(), time.Minute)
+
+	// the interval is kept across the DST transition, rather
+	// than the wall clock time
+	ts := time.Date(2024, 3, 10, 0, 0, 0, 0, loc)
+	prev := s.Next(ts)
+	for i := 0; i < 10; i++ {
+		next := s.Next(prev)
+		assertEqual(t, next.Sub(prev), 270*time.Minute)
+		assertEqual(t, next.Location(), loc)
+		prev = next
+	}
+
+	other, err := New("@every 4h", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(s, other), false)
+	same, err := New("@every 270m", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(s, same), true)
+}
+
+func TestEveryInvalid(t *testing.T) {
+	for _, cron := range []string{
+		"@every 0s",
+		"@every 1.5s",
+		"@every 500ms",
+		"@every -1m",
+		"@every x",
+		"@every ",
+	} {
+		t.Run(
+			cron, func(t *testing.T) {
+				_, err := New(cron, time.UTC)
+				requireErr(t, err)
+			},
+		)
+	}
+}
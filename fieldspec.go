@@ -0,0 +1,194 @@
+package crong
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldKind is the syntax used by a cron expression field
+type FieldKind int
+
+const (
+	// FieldAny is a wildcard ('*' or '?')
+	FieldAny FieldKind = iota
+	// FieldValue is a single value (ex: "5" or "MON")
+	FieldValue
+	// FieldRange is a range of values (ex: "1-5")
+	FieldRange
+	// FieldStep is a step over a range of values (ex: "*/15" or "1-30/2")
+	FieldStep
+	// FieldList is a list of entries (ex: "1,15,30-35")
+	FieldList
+	// FieldLast is the last day of the month ('L'), or the last of
+	// a weekday in the month (ex: "5L", where Start is the weekday)
+	FieldLast
+	// FieldWeek is a week of the month (ex: "W2", days 8-14)
+	FieldWeek
+	// FieldWeekOfYear is an ISO 8601 week of the year (ex: "WY10")
+	FieldWeekOfYear
+	// FieldNearestWeekday is the weekday nearest a day of the month
+	// (ex: "15W", where Start is the day), or the last weekday of the
+	// month ("LW", where Start is 0)
+	FieldNearestWeekday
+	// FieldNthWeekday is the nth of a weekday in the month (ex: "5#3",
+	// where Start is the weekday, and Nth is 3)
+	FieldNthWeekday
+)
+
+func (k FieldKind) String() string {
+	switch k {
+	case FieldAny:
+		return "any"
+	case FieldValue:
+		return "value"
+	case FieldRange:
+		return "range"
+	case FieldStep:
+		return "step"
+	case FieldList:
+		return "list"
+	case FieldLast:
+		return "last"
+	case FieldWeek:
+		return "week"
+	case FieldWeekOfYear:
+		return "week of year"
+	case FieldNearestWeekday:
+		return "nearest weekday"
+	case FieldNthWeekday:
+		return "nth weekday"
+	default:
+		return fmt.Sprintf("FieldKind(%d)", int(k))
+	}
+}
+
+// FieldSpec is a parsed representation of a single field (or a single
+// entry of a list) of a cron expression
+type FieldSpec struct {
+	// Name is the name of the field (ex: "minute", "weekday")
+	Name string
+	// Raw is the field as written in the expression
+	Raw string
+	// Kind is the syntax used by the field
+	Kind FieldKind
+
+	// Start is the value for FieldValue, the week for FieldWeek and
+	// FieldWeekOfYear, or the first value of the range for
+	// FieldRange and FieldStep. For FieldAny and
+	// FieldStep over a wildcard, it's the field's minimum value.
+	Start int
+	// End is the last value of the range for FieldRange and
+	// FieldStep. For FieldValue, it's the same as Start.
+	End int
+	// Step is the step for FieldStep
+	Step int
+	// Nth is the occurrence of the weekday in the month
+	// for FieldNthWeekday (ex: 3 for "5#3")
+	Nth int
+
+	// Names holds the names used in place of numbers
+	// (ex: ["MON", "FRI"] for "MON-FRI")
+	Names []string
+	// Entries holds each entry of a FieldList
+	Entries []FieldSpec
+	// Values is the sorted values the field (or entry) expands to.
+	// It's empty for FieldLast, FieldNearestWeekday and
+	// FieldNthWeekday, which depend on the month, and FieldWeekOfYear, which depends on the year.
+	Values []int
+}
+
+// Fields returns a parsed representation of each field of the
+// schedule, in the order they appear in the expression (beginning
+// with the seconds field, if parsed with WithSeconds). One-shot (@at)
+// schedules don't repeat, and fixed-interval (@every) and startup
+// (@reboot) schedules aren't described by their fields, so they have
+// no fields, and nil is returned (see [Schedule.At] and [Schedule.Every]).
+func (s *Schedule) Fields() []FieldSpec {
+	if !s.at.IsZero() || s.every > 0 || s.reboot {
+		return nil
+	}
+	fvs := s.fieldValues()
+	specs := make([]FieldSpec, 0, len(fvs))
+	for _, fv := range fvs {
+		specs = append(specs, fv.field.spec(fv.value))
+	}
+	return specs
+}
+
+// spec returns the FieldSpec for the given (valid) field value
+func (f field) spec(s string) FieldSpec {
+	spec := FieldSpec{Name: f.Name, Raw: s}
+	spec.Values, _ = f.parse(s)
+	spec.Values = f.canonical(spec.Values)
+
+	switch {
+	case s == string(Any) || s == string(Blank):
+		allowed := f.canonical(f.Allowed)
+		spec.Kind = FieldAny
+		spec.Start, spec.End = allowed[0], allowed[len(allowed)-1]
+	case strings.ContainsRune(s, ListSeparator):
+		spec.Kind = FieldList
+		for _, entry := range strings.Split(s, string(ListSeparator)) {
+			es := f.spec(entry)
+			spec.Entries = append(spec.Entries, es)
+			spec.Names = append(spec.Names, es.Names...)
+		}
+	case f.Index == weekdayInd && strings.ContainsRune(s, Nth):
+		before, after, _ := strings.Cut(s, string(Nth))
+		weekday := f.spec(before)
+		spec.Kind = FieldNthWeekday
+		spec.Start, spec.End = weekday.Start, weekday.End
+		spec.Names = weekday.Names
+		spec.Nth, _ = strconv.Atoi(after)
+	case strings.ContainsRune(s, Step):
+		spec.Kind = FieldStep
+		before, after, _ := strings.Cut(s, string(Step))
+		base := f.spec(before)
+		spec.Names = base.Names
+		spec.Start, spec.End = base.Start, base.End
+		if base.Kind == FieldValue {
+			// "5/10" steps from 5 to the field's maximum value
+			spec.End = f.Max()
+		}
+		spec.Step, _ = strconv.Atoi(after)
+	case strings.ContainsRune(s, Range):
+		spec.Kind = FieldRange
+		before, after, _ := strings.Cut(s, string(Range))
+		start, end := f.spec(before), f.spec(after)
+		spec.Start, spec.End = start.Start, f.rangeEnd(start.Start, end.Start, len(end.Names) > 0)
+		spec.Names = append(start.Names, end.Names...)
+	case strings.EqualFold(s, string(Last)):
+		spec.Kind = FieldLast
+	case f.Index == weekdayInd && strings.HasSuffix(strings.ToUpper(s), string(Last)):
+		// the last of a weekday in the month (ex: "5L")
+		weekday := f.spec(s[:len(s)-1])
+		spec.Kind = FieldLast
+		spec.Start, spec.End = weekday.Start, weekday.End
+		spec.Names = weekday.Names
+	case f.Index == dayInd && strings.HasSuffix(strings.ToUpper(s), string(Week)):
+		spec.Kind = FieldNearestWeekday
+		spec.Start, _ = nearestWeekdayDay(s)
+		spec.End = spec.Start
+	case f.Index == dayInd && strings.HasPrefix(strings.ToUpper(s), string(Week)):
+		spec.Kind = FieldWeek
+		week, ok := weekOfYear(s)
+		if ok {
+			spec.Kind = FieldWeekOfYear
+		} else {
+			week, _ = strconv.Atoi(s[1:])
+		}
+		spec.Start, spec.End = week, week
+	default:
+		spec.Kind = FieldValue
+		upper := strings.ToUpper(s)
+		if v, ok := f.Conversions[upper]; ok {
+			spec.Start = v
+			spec.Names = []string{upper}
+		} else {
+			spec.Start, _ = strconv.Atoi(s)
+		}
+		spec.End = spec.Start
+	}
+	return spec
+}
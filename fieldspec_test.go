@@ -0,0 +1,70 @@
+package crong
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	s, err := New("*/15 9-17 1,15,L * MON-FRI", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fields := s.Fields()
+	assertEqual(t, len(fields), 5)
+
+	minute := fields[minuteInd]
+	assertEqual(t, minute.Name, "minute")
+	assertEqual(t, minute.Raw, "*/15")
+	assertEqual(t, minute.Kind, FieldStep)
+	assertEqual(t, minute.Start, 0)
+	assertEqual(t, minute.End, 59)
+	assertEqual(t, minute.Step, 15)
+	assertEqual(t, slicesEqual(t, minute.Values, []int{0, 15, 30, 45}), true)
+
+	hour := fields[hourInd]
+	assertEqual(t, hour.Kind, FieldRange)
+	assertEqual(t, hour.Start, 9)
+	assertEqual(t, hour.End, 17)
+	assertEqual(t, len(hour.Values), 9)
+
+	day := fields[dayInd]
+	assertEqual(t, day.Kind, FieldList)
+	assertEqual(t, len(day.Entries), 3)
+	assertEqual(t, day.Entries[0].Kind, FieldValue)
+	assertEqual(t, day.Entries[0].Start, 1)
+	assertEqual(t, day.Entries[1].End, 15)
+	assertEqual(t, day.Entries[2].Kind, FieldLast)
+	assertEqual(t, len(day.Entries[2].Values), 0)
+
+	month := fields[monthInd]
+	assertEqual(t, month.Kind, FieldAny)
+	assertEqual(t, month.Start, 1)
+	assertEqual(t, month.End, 12)
+	assertEqual(t, len(month.Values), 12)
+
+	weekday := fields[weekdayInd]
+	assertEqual(t, weekday.Kind, FieldRange)
+	assertEqual(t, weekday.Start, 1)
+	assertEqual(t, weekday.End, 5)
+	assertEqual(t, slices.Equal(weekday.Names, []string{Monday, Friday}), true)
+	assertEqual(t, weekday.Kind.String(), "range")
+
+	s, err = New("5/20 0 12 ? * sun,3", nil, WithSeconds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fields = s.Fields()
+	assertEqual(t, len(fields), 6)
+	assertEqual(t, fields[0].Name, "second")
+	assertEqual(t, fields[0].Kind, FieldStep)
+	assertEqual(t, fields[0].Start, 5)
+	assertEqual(t, fields[0].End, 59)
+	assertEqual(t, fields[0].Step, 20)
+	assertEqual(t, slicesEqual(t, fields[0].Values, []int{5, 25, 45}), true)
+	assertEqual(t, fields[3].Kind, FieldAny)
+	assertEqual(t, len(fields[3].Values), 31)
+	assertEqual(t, fields[5].Kind, FieldList)
+	assertEqual(t, slices.Equal(fields[5].Names, []string{Sunday}), true)
+	assertEqual(t, slicesEqual(t, fields[5].Values, []int{0, 3}), true)
+}
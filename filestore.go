@@ -0,0 +1,280 @@
+package crong
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore is an [AdvanceWatermarkStore] and [RuntimeStore] that keeps
+// its data in memory and snapshots it to a JSON file, so a small daemon
+// can survive restarts without a database. Snapshots are written with
+// Snapshot, or periodically with SnapshotEvery. Data recorded since
+// the last snapshot is lost if the process exits without one.
+// Archived runtimes are kept according to [FileStoreOptions], so the
+// store (and each snapshot) doesn't grow without bound.
+type FileStore struct {
+	path       string
+	options    FileStoreOptions
+	watermarks map[string]time.Time
+	runtimes   map[string][]*JobRuntime
+	mu         sync.RWMutex
+}
+
+// DefaultFileStoreMaxRuntimes is the number of archived runtimes a
+// [FileStore] keeps for each key, if [FileStoreOptions.MaxRuntimes]
+// isn't set
+const DefaultFileStoreMaxRuntimes = 1000
+
+// FileStoreOptions configures a [FileStore]
+type FileStoreOptions struct {
+	// MaxRuntimes is the maximum number of archived runtimes kept
+	// for each key. Older runtimes are discarded as new ones are
+	// archived. If it isn't set, DefaultFileStoreMaxRuntimes is
+	// used. If it's negative, runtimes are only discarded by
+	// MaxRuntimeAge.
+	MaxRuntimes int
+
+	// MaxRuntimeAge, if set, is how long after a run ends its
+	// runtime is kept. Runtimes are checked as runtimes are archived
+	// for the same key, and when the store is opened.
+	MaxRuntimeAge time.Duration
+}
+
+// fileSnapshot is the file format of a FileStore
+type fileSnapshot struct {
+	Watermarks map[string]time.Time       `json:"watermarks"`
+	Runtimes   map[string][]runtimeRecord `json:"runtimes"`
+}
+
+// OpenFileStore returns a FileStore snapshotting to the file at path,
+// restoring it from the file if it exists. Restored runtimes keep
+// their errors and attributes (sorted by key) as strings.
+func OpenFileStore(path string) (*FileStore, error) {
+	return OpenFileStoreWithOptions(path, FileStoreOptions{})
+}
+
+// OpenFileStoreWithOptions returns a FileStore with the given options,
+// as with [OpenFileStore]. Restored runtimes are trimmed according to
+// the options.
+func OpenFileStoreWithOptions(path string, opts FileStoreOptions) (*FileStore, error) {
+	if opts.MaxRuntimes == 0 {
+		opts.MaxRuntimes = DefaultFileStoreMaxRuntimes
+	}
+	f := &FileStore{
+		path:       path,
+		options:    opts,
+		watermarks: map[string]time.Time{},
+		runtimes:   map[string][]*JobRuntime{},
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot fileSnapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	for key, wm := range snapshot.Watermarks {
+		f.watermarks[key] = wm
+	}
+	now := time.Now()
+	for key, records := range snapshot.Runtimes {
+		for _, r := range records {
+			f.runtimes[key] = append(f.runtimes[key], r.runtime())
+		}
+		f.trim(key, now)
+	}
+	return f, nil
+}
+
+// runtime returns the JobRuntime the record was exported from,
+// with its error and attributes as strings
+func (r runtimeRecord) runtime() *JobRuntime {
+	rt := &JobRuntime{
+		RunID:     r.RunID,
+		Scheduled: r.Scheduled,
+		Triggered: r.Triggered,
+		Reason:    r.Reason,
+		Start:     r.Start,
+		End:       r.End,
+	}
+	if r.Triggered && r.Reason == RunScheduled {
+		// exported before reasons were recorded
+		rt.Reason = RunTriggered
+	}
+	if r.Error != "" {
+		rt.Error = errors.New(r.Error)
+	}
+	for k, v := range r.Attrs {
+		rt.Attrs = append(rt.Attrs, slog.String(k, v))
+	}
+	slices.SortFunc(
+		rt.Attrs, func(a slog.Attr, b slog.Attr) int {
+			return strings.Compare(a.Key, b.Key)
+		},
+	)
+	return rt
+}
+
+// Watermark returns the watermark for the given key
+func (f *FileStore) Watermark(_ context.Context, key string) (time.Time, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.watermarks[key], nil
+}
+
+// SetWatermark sets the watermark for the given key
+func (f *FileStore) SetWatermark(_ context.Context, key string, t time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.watermarks[key] = t
+	return nil
+}
+
+// AdvanceWatermark sets the watermark for the given key to t,
+// if t is after it
+func (f *FileStore) AdvanceWatermark(_ context.Context, key string, t time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !t.After(f.watermarks[key]) {
+		return false, nil
+	}
+	f.watermarks[key] = t
+	return true, nil
+}
+
+// ArchiveRuntimes appends the given runtimes to those stored for the
+// given key, discarding those past the store's retention (see
+// [FileStoreOptions])
+func (f *FileStore) ArchiveRuntimes(
+	_ context.Context,
+	key string,
+	runtimes []*JobRuntime,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runtimes[key] = append(f.runtimes[key], runtimes...)
+	f.trim(key, time.Now())
+	return nil
+}
+
+// trim discards the runtimes stored for key that are past the
+// store's retention at now. f.mu must be held.
+func (f *FileStore) trim(key string, now time.Time) {
+	runtimes := f.runtimes[key]
+	drop := 0
+	if age := f.options.MaxRuntimeAge; age > 0 {
+		for drop < len(runtimes) && now.Sub(runtimes[drop].End) > age {
+			drop++
+		}
+	}
+	if n := f.options.MaxRuntimes; n > 0 {
+		drop = max(drop, len(runtimes)-n)
+	}
+	if drop == 0 {
+		return
+	}
+	// copied, so the dropped runtimes aren't kept
+	// alive by the backing array
+	f.runtimes[key] = slices.Clone(runtimes[drop:])
+}
+
+// Runtimes returns the runtimes archived for the given key
+func (f *FileStore) Runtimes(key string) []*JobRuntime {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return slices.Clone(f.runtimes[key])
+}
+
+// Snapshot writes the store's data to its file. The file is written
+// and synced under a temporary name, then renamed over the previous
+// snapshot, so a crash while writing leaves the previous snapshot.
+func (f *FileStore) Snapshot() error {
+	f.mu.RLock()
+	snapshot := fileSnapshot{
+		Watermarks: make(map[string]time.Time, len(f.watermarks)),
+		Runtimes:   make(map[string][]runtimeRecord, len(f.runtimes)),
+	}
+	for key, wm := range f.watermarks {
+		snapshot.Watermarks[key] = wm
+	}
+	for key, runtimes := range f.runtimes {
+		records := make([]runtimeRecord, 0, len(runtimes))
+		for _, rt := range runtimes {
+			records = append(records, newRuntimeRecord(rt))
+		}
+		snapshot.Runtimes[key] = records
+	}
+	f.mu.RUnlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), f.path); err != nil {
+		return err
+	}
+
+	// sync the directory, so the rename itself survives a crash
+	dir, err := os.Open(filepath.Dir(f.path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// SnapshotEvery writes a snapshot every interval until ctx is done,
+// then writes a final snapshot and returns its error. Errors from
+// periodic snapshots are logged. It returns an error right away if
+// interval isn't greater than 0.
+func (f *FileStore) SnapshotEvery(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("snapshot interval must be greater than 0")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return f.Snapshot()
+		case <-ticker.C:
+			if err := f.Snapshot(); err != nil {
+				jobLogger().Error(
+					"failed to snapshot file store",
+					"error", err,
+					"path", f.path,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package crong
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "crong.json")
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wm := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	if err = store.SetWatermark(ctx, "job", wm); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = store.ArchiveRuntimes(
+		ctx, "job", []*JobRuntime{
+			{
+				RunID:  "a",
+				Reason: RunTriggered,
+				Start:  wm,
+				End:    wm.Add(time.Second),
+				Error:  errors.New("boom"),
+				Attrs:  []slog.Attr{slog.Int("rows", 3)},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err = store.Snapshot(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	restored, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := restored.Watermark(ctx, "job")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, got, wm)
+	advanced, err := restored.AdvanceWatermark(ctx, "job", wm.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, advanced, false)
+
+	runtimes := restored.Runtimes("job")
+	if len(runtimes) != 1 {
+		t.Fatalf("expected 1 runtime, got %d", len(runtimes))
+	}
+	rt := runtimes[0]
+	assertEqual(t, rt.RunID, "a")
+	assertEqual(t, rt.Reason, RunTriggered)
+	assertEqual(t, rt.Start, wm)
+	assertEqual(t, rt.Error.Error(), "boom")
+	assertEqual(t, rt.Attrs[0].String(), "rows=3")
+
+	if err = os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = OpenFileStore(path); err == nil {
+		t.Errorf("expected error for a corrupt snapshot")
+	}
+}
+
+func TestFileStoreSnapshotEvery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crong.json")
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err = store.SnapshotEvery(context.Background(), 0); err == nil {
+		t.Errorf("expected error for a zero interval")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- store.SnapshotEvery(ctx, time.Hour)
+	}()
+	wm := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	_ = store.SetWatermark(context.Background(), "job", wm)
+
+	// a final snapshot is written when ctx is done
+	cancel()
+	if err = <-errCh; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	restored, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, _ := restored.Watermark(context.Background(), "job")
+	assertEqual(t, got, wm)
+}
+
+func TestFileStoreRetention(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "crong.json")
+	store, err := OpenFileStoreWithOptions(
+		path, FileStoreOptions{MaxRuntimes: 3, MaxRuntimeAge: time.Hour},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	now := time.Now()
+	old := &JobRuntime{RunID: "old", End: now.Add(-2 * time.Hour)}
+	if err = store.ArchiveRuntimes(ctx, "job", []*JobRuntime{old}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		err = store.ArchiveRuntimes(ctx, "job", []*JobRuntime{{RunID: id, End: now}})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	var ids []string
+	for _, rt := range store.Runtimes("job") {
+		ids = append(ids, rt.RunID)
+	}
+	assertEqual(t, strings.Join(ids, ","), "b,c,d")
+
+	// restored runtimes are trimmed by the options they're opened with
+	if err = store.Snapshot(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	restored, err := OpenFileStoreWithOptions(path, FileStoreOptions{MaxRuntimes: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	runtimes := restored.Runtimes("job")
+	if len(runtimes) != 1 {
+		t.Fatalf("expected 1 runtime, got %d", len(runtimes))
+	}
+	assertEqual(t, runtimes[0].RunID, "d")
+
+	// the default limit applies when none is set
+	unset, err := OpenFileStore(filepath.Join(t.TempDir(), "crong.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	batch := make([]*JobRuntime, DefaultFileStoreMaxRuntimes+5)
+	for i := range batch {
+		batch[i] = &JobRuntime{End: now}
+	}
+	if err = unset.ArchiveRuntimes(ctx, "job", batch); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, len(unset.Runtimes("job")), DefaultFileStoreMaxRuntimes)
+}
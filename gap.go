@@ -0,0 +1,206 @@
+package crong
+
+import (
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// GapError is returned by [Schedule.CheckMaxGap] when the time between
+// two consecutive scheduled times exceeds the limit
+type GapError struct {
+	// Limit is the largest gap allowed
+	Limit time.Duration
+
+	// Gap is the largest gap found
+	Gap time.Duration
+
+	// After is the scheduled time the gap starts at
+	After time.Time
+}
+
+func (e *GapError) Error() string {
+	return fmt.Sprintf(
+		"schedule goes %s without running after %s, more than %s",
+		e.Gap,
+		e.After.Format(time.RFC3339),
+		e.Limit,
+	)
+}
+
+// MaxGap returns the longest time between consecutive scheduled times,
+// for each scheduled time from `from` up to (but not including) `to`,
+// along with the scheduled time the gap starts at. The gap after the
+// last scheduled time in that period is measured to the next scheduled
+// time, even if it's after `to`, so a schedule that runs at least every
+// 6 hours between from and to returns 6 hours or less.
+//
+// The Gregorian calendar repeats every 400 years, so checking from
+// 2000-01-01 to 2400-01-01 covers every combination of day, month and
+// weekday (for the daylight saving time rules currently known for the
+// schedule's location). Days without a daylight saving time transition
+// are checked all at once, but days with one are stepped through, which
+// takes longer for schedules that fire often.
+//
+// If there are no scheduled times in the period, the gap is 0. An
+// error is returned if the schedule has no scheduled time at or after
+// from (ex: "0 0 30 2 *"), or stops having scheduled times (ex: a
+// one-shot (@at) schedule).
+func (s *Schedule) MaxGap(from time.Time, to time.Time) (
+	gap time.Duration,
+	after time.Time,
+	err error,
+) {
+	_, longest, err := s.gaps(from, to)
+	return longest.gap, longest.after, err
+}
+
+// MinGap returns the shortest time between consecutive scheduled
+// times, for each scheduled time from `from` up to (but not including)
+// `to`, along with the scheduled time the gap starts at. It's computed
+// as MaxGap is (see [Schedule.MaxGap]), so the shortest gap across a
+// daylight saving time transition is included (ex: 01:30 to 03:00 on
+// the day the clocks go forward is 30 minutes).
+func (s *Schedule) MinGap(from time.Time, to time.Time) (
+	gap time.Duration,
+	after time.Time,
+	err error,
+) {
+	shortest, _, err := s.gaps(from, to)
+	return shortest.gap, shortest.after, err
+}
+
+// scheduleGap is the time between two consecutive scheduled times
+type scheduleGap struct {
+	gap   time.Duration
+	after time.Time
+}
+
+// update records the gap between the given scheduled times, if it's
+// shorter than the shortest gap, or longer than the longest gap
+func (g *scheduleGap) update(d time.Duration, after time.Time, longest bool) {
+	if g.after.IsZero() || (longest && d > g.gap) || (!longest && d < g.gap) {
+		g.gap = d
+		g.after = after
+	}
+}
+
+// gaps returns the shortest and longest gaps between consecutive
+// scheduled times, for each scheduled time from `from` up to `to`
+func (s *Schedule) gaps(from time.Time, to time.Time) (
+	shortest scheduleGap,
+	longest scheduleGap,
+	err error,
+) {
+	prev := s.Ceil(from)
+	if prev.IsZero() {
+		_, err = s.NextErr(from)
+		return shortest, longest, err
+	}
+	if s.every > 0 {
+		// every gap is the interval
+		if prev.Before(to) {
+			shortest.update(s.every, prev, false)
+			longest.update(s.every, prev, true)
+		}
+		return shortest, longest, nil
+	}
+
+	// the shortest and longest gaps within a day, which are the same
+	// for every day without a daylight saving time transition
+	dayShortest, dayLongest, ok := s.timeOfDayGaps()
+	for prev.Before(to) {
+		if end, whole := s.wholeDay(prev, from); whole && !end.After(to) {
+			if ok {
+				midnight := prev.Add(-timeOfDay(prev))
+				shortest.update(dayShortest.gap, midnight.Add(dayShortest.at), false)
+				longest.update(dayLongest.gap, midnight.Add(dayLongest.at), true)
+			}
+			prev = s.Prev(end)
+		}
+		next := s.Next(prev)
+		if next.IsZero() {
+			if !s.at.IsZero() {
+				return shortest, longest, ErrScheduleExhausted
+			}
+			return shortest, longest, fmt.Errorf(
+				"%w after %s",
+				ErrUnreachableSchedule,
+				prev.Format(time.RFC3339),
+			)
+		}
+		d := next.Sub(prev)
+		shortest.update(d, prev, false)
+		longest.update(d, prev, true)
+		prev = next
+	}
+	return shortest, longest, nil
+}
+
+// CheckMaxGap returns a [GapError] if the schedule ever goes longer
+// than limit without running, between from and to (ex: to verify a
+// schedule runs at least every 6 hours). See [Schedule.MaxGap].
+func (s *Schedule) CheckMaxGap(
+	limit time.Duration,
+	from time.Time,
+	to time.Time,
+) error {
+	gap, after, err := s.MaxGap(from, to)
+	if err != nil {
+		return err
+	}
+	if gap > limit {
+		return &GapError{Limit: limit, Gap: gap, After: after}
+	}
+	return nil
+}
+
+// timeOfDay returns the time since midnight of the given time, by the
+// clock (which is the time elapsed, on days without a daylight saving
+// time transition)
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+}
+
+// timeOfDayGap is the time between two consecutive times of day
+type timeOfDayGap struct {
+	gap time.Duration
+	at  time.Duration
+}
+
+// timeOfDayGaps returns the shortest and longest gaps between
+// consecutive times of day the schedule fires at, on a day it runs on
+// without a daylight saving time transition, with the time of day each
+// gap starts at. Gaps across midnight aren't included, so ok is false
+// if the schedule only fires once a day.
+func (s *Schedule) timeOfDayGaps() (shortest, longest timeOfDayGap, ok bool) {
+	sets := s.valueSets()
+	var last time.Duration
+	first := true
+	for hours := uint64(sets[hourInd]); hours != 0; hours &= hours - 1 {
+		hour := bits.TrailingZeros64(hours)
+		for minutes := uint64(sets[minuteInd]); minutes != 0; minutes &= minutes - 1 {
+			minute := bits.TrailingZeros64(minutes)
+			for seconds := uint64(sets[len(sets)-1]); seconds != 0; seconds &= seconds - 1 {
+				d := time.Duration(hour)*time.Hour +
+					time.Duration(minute)*time.Minute +
+					time.Duration(bits.TrailingZeros64(seconds))*time.Second
+				if !first {
+					gap := d - last
+					if !ok || gap < shortest.gap {
+						shortest = timeOfDayGap{gap: gap, at: last}
+					}
+					if !ok || gap > longest.gap {
+						longest = timeOfDayGap{gap: gap, at: last}
+					}
+					ok = true
+				}
+				last = d
+				first = false
+			}
+		}
+	}
+	return shortest, longest, ok
+}
@@ -0,0 +1,230 @@
+package crong
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMaxGap(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type gapCase struct {
+		Cron        string
+		Loc         *time.Location
+		ExpectGap   time.Duration
+		ExpectAfter time.Time
+	}
+	cases := []gapCase{
+		{
+			Cron:        "0 */6 * * *",
+			ExpectGap:   6 * time.Hour,
+			ExpectAfter: from,
+		},
+		{
+			// the weekend is the longest gap
+			Cron:        "0 9,17 * * MON-FRI",
+			ExpectGap:   64 * time.Hour,
+			ExpectAfter: time.Date(2024, 1, 5, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			// the longest gap is within each day
+			Cron:        "0 1,2,20 * * *",
+			ExpectGap:   18 * time.Hour,
+			ExpectAfter: time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			Cron:        "30 */10 * * * *",
+			ExpectGap:   10 * time.Minute,
+			ExpectAfter: time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC),
+		},
+		{
+			// the clocks going back adds an hour
+			Cron:        "0 */6 * * *",
+			Loc:         newYork,
+			ExpectGap:   7 * time.Hour,
+			ExpectAfter: time.Date(2024, 11, 3, 0, 0, 0, 0, newYork),
+		},
+		{
+			// the gap after the last run is measured to the next run
+			Cron:        "0 0 1 */6 *",
+			ExpectGap:   184 * 24 * time.Hour,
+			ExpectAfter: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				var opts []ParseOption
+				if tc.Cron == "30 */10 * * * *" {
+					opts = append(opts, WithSeconds())
+				}
+				s, err := New(tc.Cron, tc.Loc, opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				gap, after, err := s.MaxGap(from, to)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, gap, tc.ExpectGap)
+				assertEqual(t, after.Equal(tc.ExpectAfter), true)
+			},
+		)
+	}
+}
+
+// TestMaxGapMatchesStepping compares MaxGap and MinGap against stepping
+// through each scheduled time with Next
+func TestMaxGapMatchesStepping(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	from := time.Date(2024, 2, 20, 7, 31, 0, 0, newYork)
+	to := time.Date(2024, 11, 20, 0, 0, 0, 0, newYork)
+	for _, cron := range append(
+		slices.Clone(benchmarkExprs),
+		"30 1,2,3 * * *",
+		"0 0 L * *",
+		"0 22 * * FRI",
+		"0,59 0,23 * * *",
+		"0 1,3 * * *",
+		"*/20 2 * * *",
+	) {
+		s, err := New(cron, newYork)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var expected, expectedMin time.Duration
+		var expectedAfter, expectedMinAfter time.Time
+		for prev := s.Ceil(from); prev.Before(to); {
+			next := s.Next(prev)
+			d := next.Sub(prev)
+			if d > expected {
+				expected = d
+				expectedAfter = prev
+			}
+			if expectedMinAfter.IsZero() || d < expectedMin {
+				expectedMin = d
+				expectedMinAfter = prev
+			}
+			prev = next
+		}
+		gap, after, err := s.MaxGap(from, to)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", cron, err)
+		}
+		assertEqual(t, gap, expected)
+		assertEqual(t, after.Equal(expectedAfter), true)
+
+		gap, after, err = s.MinGap(from, to)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", cron, err)
+		}
+		assertEqual(t, gap, expectedMin)
+		assertEqual(t, after.Equal(expectedMinAfter), true)
+	}
+}
+
+func TestMinGap(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type gapCase struct {
+		Cron        string
+		Loc         *time.Location
+		ExpectGap   time.Duration
+		ExpectAfter time.Time
+	}
+	cases := []gapCase{
+		{
+			Cron:        "*/15 9-17 * * MON-FRI",
+			ExpectGap:   15 * time.Minute,
+			ExpectAfter: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			// the shortest gap is across midnight
+			Cron:        "0,59 0,23 * * *",
+			ExpectGap:   time.Minute,
+			ExpectAfter: time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC),
+		},
+		{
+			// the clocks going forward skips an hour
+			Cron:        "0 1,3 * * *",
+			Loc:         newYork,
+			ExpectGap:   time.Hour,
+			ExpectAfter: time.Date(2024, 3, 10, 1, 0, 0, 0, newYork),
+		},
+		{
+			Cron:        "0 12 * * *",
+			ExpectGap:   24 * time.Hour,
+			ExpectAfter: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, tc.Loc)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				gap, after, err := s.MinGap(from, to)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, gap, tc.ExpectGap)
+				assertEqual(t, after.Equal(tc.ExpectAfter), true)
+			},
+		)
+	}
+}
+
+func TestCheckMaxGap(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, err := New("0 9,17 * * MON-FRI", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err = s.CheckMaxGap(72*time.Hour, from, to); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = s.CheckMaxGap(24*time.Hour, from, to)
+	var gapErr *GapError
+	if !errors.As(err, &gapErr) {
+		t.Fatalf("expected GapError, got %v", err)
+	}
+	assertEqual(t, gapErr.Limit, 24*time.Hour)
+	assertEqual(t, gapErr.Gap, 64*time.Hour)
+	assertEqual(t, gapErr.After, time.Date(2024, 1, 5, 17, 0, 0, 0, time.UTC))
+
+	never, err := New("0 0 30 2 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, _, err = never.MaxGap(from, to)
+	if !errors.Is(err, ErrUnreachableSchedule) {
+		t.Errorf("expected ErrUnreachableSchedule, got %v", err)
+	}
+
+	at, err := New("@at 2024-06-01T00:00:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, _, err = at.MaxGap(from, to)
+	if !errors.Is(err, ErrScheduleExhausted) {
+		t.Errorf("expected ErrScheduleExhausted, got %v", err)
+	}
+}
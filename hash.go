@@ -0,0 +1,121 @@
+package crong
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// hashDayMax is the largest day an "H" entry in the day field resolves
+// to (without a range), so it occurs in every month, as in Jenkins
+const hashDayMax = 28
+
+// WithHashKey sets the key "H" entries are derived from (see [Hash]).
+// Jobs with the same expression, but different keys (ex: the job's
+// name), fire at different times, spreading their load, while each
+// job keeps firing at the same times for as long as its key doesn't
+// change. Expressions with "H" entries can't be parsed without a key.
+func WithHashKey(key string) ParseOption {
+	return func(o *parseOptions) {
+		o.hashKey = key
+	}
+}
+
+// expandHashes replaces the "H" entries in the schedule's fields with
+// the values derived from its hash key, recording any errors on verr.
+// It returns false if any entry couldn't be expanded.
+func (s *Schedule) expandHashes(verr *ValidationError) bool {
+	ok := true
+	for _, fv := range s.fieldValues() {
+		expanded, err := fv.field.expandHash(fv.value, s.options.hashKey)
+		if err != nil {
+			verr.add(fv.field, fv.value, err)
+			ok = false
+			continue
+		}
+		if fv.field.Index == secondInd {
+			s.second = expanded
+		} else {
+			s.values[fv.field.Index] = expanded
+		}
+	}
+	return ok
+}
+
+// expandHash returns the field value with each "H" list entry
+// (ex: "H", "H(0-7)" or "H/15") replaced by the value (or step)
+// derived from key, and other entries unchanged
+func (f field) expandHash(value string, key string) (string, error) {
+	entries := strings.Split(value, string(ListSeparator))
+	expanded := false
+	for i, entry := range entries {
+		rest, found := strings.CutPrefix(strings.ToUpper(entry), string(Hash))
+		if !found {
+			continue
+		}
+		if key == "" {
+			return "", f.error(
+				fmt.Sprintf("'%c' requires a hash key (see WithHashKey)", Hash),
+			)
+		}
+		allowed := f.canonical(f.Allowed)
+		lo, hi := allowed[0], allowed[len(allowed)-1]
+		if f.Index == dayInd {
+			hi = hashDayMax
+		}
+		if r, ok := strings.CutPrefix(rest, "("); ok {
+			inner, after, closed := strings.Cut(r, ")")
+			before, end, isRange := strings.Cut(inner, string(Range))
+			start, startOK := atoi(before)
+			stop, stopOK := atoi(end)
+			switch {
+			case !closed || !isRange || !startOK || !stopOK:
+				return "", f.error(fmt.Sprintf("invalid hash range '%s'", entry))
+			case start < f.Min() || stop > f.Max() || start > stop:
+				return "", f.error(
+					fmt.Sprintf(
+						"hash range '%s' must be within %d-%d",
+						entry,
+						f.Min(),
+						f.Max(),
+					),
+				)
+			}
+			lo, hi, rest = start, stop, after
+		}
+
+		h := hashValue(key, f.Name, i)
+		switch {
+		case rest == "":
+			entries[i] = strconv.Itoa(lo + int(h%uint64(hi-lo+1)))
+		case strings.HasPrefix(rest, string(Step)):
+			step, ok := atoi(rest[1:])
+			if !ok || step < 1 {
+				return "", f.error(fmt.Sprintf("invalid hash step '%s'", entry))
+			}
+			start := lo + int(h%uint64(min(step, hi-lo+1)))
+			if start+step > hi {
+				// the step only occurs once in the range
+				entries[i] = strconv.Itoa(start)
+				break
+			}
+			entries[i] = fmt.Sprintf("%d%c%d%c%d", start, Range, hi, Step, step)
+		default:
+			return "", f.error(fmt.Sprintf("invalid hash entry '%s'", entry))
+		}
+		expanded = true
+	}
+	if !expanded {
+		return value, nil
+	}
+	return strings.Join(entries, string(ListSeparator)), nil
+}
+
+// hashValue returns a hash of the key, for the list entry
+// at the given index of the named field
+func hashValue(key string, field string, entry int) uint64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00%d", key, field, entry)
+	return h.Sum64()
+}
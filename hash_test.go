@@ -0,0 +1,120 @@
+package crong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	s, err := New("H H(0-7) * * *", nil, WithHashKey("backup"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	minutes, hours := s.minutes, s.hours
+	if len(minutes) != 1 || len(hours) != 1 {
+		t.Fatalf("expected a single minute and hour, got %s", s)
+	}
+	if hours[0] > 7 {
+		t.Errorf("expected an hour from 0-7, got %d", hours[0])
+	}
+	if err = CheckRoundTrip(s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the same key always gives the same schedule
+	again, err := New("H H(0-7) * * *", nil, WithHashKey("backup"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, again.String(), s.String())
+
+	// and the expression with the values filled in parses without it
+	resolved, err := New(s.String(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(s, resolved), true)
+
+	// different keys spread out
+	seen := map[string]bool{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		ks, err := New("H H(0-7) * * *", nil, WithHashKey(key))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		seen[ks.String()] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected different keys to give different schedules")
+	}
+}
+
+func TestHashStep(t *testing.T) {
+	for _, key := range []string{"a", "b", "c", "d"} {
+		s, err := New("H/15 * * * *", nil, WithHashKey(key))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(s.minutes) != 4 || s.minutes[0] > 14 {
+			t.Errorf("expected 4 minutes, starting before 15, got %v", s.minutes)
+		}
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		next := s.Next(from)
+		assertEqual(t, s.Next(next).Sub(next), 15*time.Minute)
+	}
+
+	// a step longer than the range occurs once
+	s, err := New("0 H(0-3)/6 * * *", nil, WithHashKey("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, len(s.hours), 1)
+
+	// the day field stays in days every month has
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		s, err = New("0 0 H * *", nil, WithHashKey(key))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s.days[0] > hashDayMax {
+			t.Errorf("expected a day up to %d, got %d", hashDayMax, s.days[0])
+		}
+	}
+
+	// other list entries are kept
+	s, err = New("0,H(30-40) * * * *", nil, WithHashKey("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, len(s.minutes), 2)
+	assertEqual(t, s.minutes[0], 0)
+}
+
+func TestHashInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		cron string
+		key  string
+	}{
+		{cron: "H * * * *"},
+		{cron: "H(0-7 * * * *", key: "a"},
+		{cron: "H(7-0) * * * *", key: "a"},
+		{cron: "H(0-70) * * * *", key: "a"},
+		{cron: "H/0 * * * *", key: "a"},
+		{cron: "H/x * * * *", key: "a"},
+		{cron: "Hx * * * *", key: "a"},
+	} {
+		t.Run(
+			tc.cron, func(t *testing.T) {
+				_, err := New(tc.cron, nil, WithHashKey(tc.key))
+				requireErr(t, err)
+			},
+		)
+	}
+
+	// weekday names with an H aren't hash entries
+	s, err := New("0 0 * * THU", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "0 0 * * THU")
+}
@@ -3,6 +3,7 @@ package crong
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 // assertEqual is a helper function to compare two values
@@ -42,3 +43,16 @@ func requireErr(t testing.TB, err error, msg ...string) {
 		t.Fatalf("expected error (%s)", strings.Join(msg, "- \n"))
 	}
 }
+
+// waitFor polls f until it returns true, failing the test
+// if it doesn't within the given timeout
+func waitFor(t testing.TB, timeout time.Duration, f func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !f() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met after %s", timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,90 @@
+package crong
+
+import (
+	"slices"
+	"time"
+)
+
+// Histogram counts a schedule's occurrences between two
+// times by hour of day and by weekday
+type Histogram struct {
+	// From is the start of the counted period
+	From time.Time
+	// To is the end of the counted period
+	To time.Time
+
+	// Hours holds the number of occurrences in each hour of the day
+	Hours [24]int
+	// Weekdays holds the number of occurrences on each
+	// weekday, indexed by time.Weekday
+	Weekdays [7]int
+	// Total is the total number of occurrences
+	Total int
+}
+
+// Histogram returns the number of occurrences of the schedule in
+// each hour of the day and on each weekday, for whole days from the
+// day of from up to (but not including) the day of to, in the
+// schedule's location. Occurrences are counted per day from the
+// schedule's fields rather than by enumerating them, so this is
+// cheap over long periods. Clock changes (ex: DST transitions)
+// aren't accounted for.
+func (s *Schedule) Histogram(from time.Time, to time.Time) Histogram {
+	from = from.In(s.loc)
+	to = to.In(s.loc)
+	h := Histogram{From: from, To: to}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, s.loc)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, s.loc)
+
+	if !s.at.IsZero() {
+		if !s.at.Before(start) && s.at.Before(end) {
+			h.Hours[s.at.Hour()]++
+			h.Weekdays[s.at.Weekday()]++
+			h.Total++
+		}
+		return h
+	}
+	if s.reboot {
+		return h
+	}
+	if s.every > 0 {
+		for hour := start; hour.Before(end); hour = hour.Add(time.Hour) {
+			n := int(s.everyCount(hour, hour.Add(time.Hour)))
+			h.Hours[hour.Hour()] += n
+			h.Weekdays[hour.Weekday()] += n
+			h.Total += n
+		}
+		return h
+	}
+
+	// occurrences in each hour, on days the schedule runs
+	var perHour [24]int
+	perMinute := 1
+	if s.options.seconds {
+		perMinute = len(s.seconds)
+	}
+	minutes := len(s.minutes)
+	if s.allowAnyMinute {
+		minutes = len(minuteOpts.Allowed)
+	}
+	perDay := 0
+	for hour := range perHour {
+		if s.allowAnyHour || slices.Contains(s.hours, hour) {
+			perHour[hour] = minutes * perMinute
+			perDay += perHour[hour]
+		}
+	}
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !s.isMonth(day) || !s.isScheduledDay(day) {
+			continue
+		}
+		for hour, n := range perHour {
+			h.Hours[hour] += n
+		}
+		h.Weekdays[day.Weekday()] += perDay
+		h.Total += perDay
+	}
+	return h
+}
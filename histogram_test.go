@@ -0,0 +1,62 @@
+package crong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	s, err := New("*/15 9-17 * * MON-FRI", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// 2024-03-04 is a Monday, so this is two full weeks
+	from := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	h := s.Histogram(from, to)
+
+	assertEqual(t, h.From, from)
+	assertEqual(t, h.To, to)
+	assertEqual(t, h.Total, 10*9*4)
+	assertEqual(t, h.Hours[8], 0)
+	assertEqual(t, h.Hours[9], 10*4)
+	assertEqual(t, h.Hours[17], 10*4)
+	assertEqual(t, h.Hours[18], 0)
+	assertEqual(t, h.Weekdays[time.Sunday], 0)
+	assertEqual(t, h.Weekdays[time.Monday], 2*9*4)
+	assertEqual(t, h.Weekdays[time.Saturday], 0)
+
+	// the histogram should agree with enumerating occurrences
+	enumerated := Histogram{}
+	for next := s.Next(from.Truncate(24 * time.Hour).Add(-time.Minute)); next.Before(to); next = s.Next(next) {
+		enumerated.Hours[next.Hour()]++
+		enumerated.Weekdays[next.Weekday()]++
+		enumerated.Total++
+	}
+	assertEqual(t, h.Hours, enumerated.Hours)
+	assertEqual(t, h.Weekdays, enumerated.Weekdays)
+	assertEqual(t, h.Total, enumerated.Total)
+
+	s, err = New("0 12 L * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	h = s.Histogram(
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	assertEqual(t, h.Total, 12)
+	assertEqual(t, h.Hours[12], 12)
+
+	s, err = New(At+" 2024-03-04T13:20:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	h = s.Histogram(from, to)
+	assertEqual(t, h.Total, 1)
+	assertEqual(t, h.Hours[13], 1)
+	assertEqual(t, h.Weekdays[time.Monday], 1)
+	h = s.Histogram(to, to.AddDate(0, 0, 7))
+	assertEqual(t, h.Total, 0)
+}
@@ -0,0 +1,230 @@
+package crong
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RuntimeStore archives run history trimmed from a [ScheduledJob]'s
+// memory (see [ScheduledJobOptions.RuntimeStore])
+type RuntimeStore interface {
+	// ArchiveRuntimes stores the given runtimes, oldest first, for
+	// the job identified by key
+	ArchiveRuntimes(ctx context.Context, key string, runtimes []*JobRuntime) error
+}
+
+// MemoryRuntimeStore is a [RuntimeStore] that keeps archived
+// runtimes in memory, which is mostly useful for tests
+type MemoryRuntimeStore struct {
+	runtimes map[string][]*JobRuntime
+	mu       sync.RWMutex
+}
+
+// NewMemoryRuntimeStore returns an empty [MemoryRuntimeStore]
+func NewMemoryRuntimeStore() *MemoryRuntimeStore {
+	return &MemoryRuntimeStore{runtimes: map[string][]*JobRuntime{}}
+}
+
+// ArchiveRuntimes appends the given runtimes to those stored
+// for the given key
+func (m *MemoryRuntimeStore) ArchiveRuntimes(
+	_ context.Context,
+	key string,
+	runtimes []*JobRuntime,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runtimes[key] = append(m.runtimes[key], runtimes...)
+	return nil
+}
+
+// Runtimes returns the runtimes archived for the given key
+func (m *MemoryRuntimeStore) Runtimes(key string) []*JobRuntime {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.runtimes[key])
+}
+
+// ExportFormat is a format run history can be exported in
+// (see [ExportRuntimes])
+type ExportFormat int
+
+const (
+	// ExportJSON writes a JSON array with an object for each run
+	ExportJSON ExportFormat = iota
+
+	// ExportCSV writes a header row, then a row for each run
+	ExportCSV
+)
+
+// RuntimeFilter selects the runs to export (see [ExportRuntimes]).
+// The zero value selects every run.
+type RuntimeFilter struct {
+	// Since, if set, excludes runs that started before it
+	Since time.Time
+
+	// Until, if set, excludes runs that started at or after it
+	Until time.Time
+
+	// ErrorsOnly excludes runs that didn't return an error
+	ErrorsOnly bool
+}
+
+// match returns true if the runtime is selected by the filter
+func (f RuntimeFilter) match(rt *JobRuntime) bool {
+	switch {
+	case !f.Since.IsZero() && rt.Start.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !rt.Start.Before(f.Until):
+		return false
+	case f.ErrorsOnly && rt.Error == nil:
+		return false
+	default:
+		return true
+	}
+}
+
+// runtimeRecord is the exported form of a JobRuntime
+type runtimeRecord struct {
+	RunID     string            `json:"run_id"`
+	Scheduled time.Time         `json:"scheduled"`
+	Triggered bool              `json:"triggered,omitempty"`
+	Reason    RunReason         `json:"reason"`
+	Start     time.Time         `json:"start"`
+	End       time.Time         `json:"end"`
+	Error     string            `json:"error,omitempty"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+}
+
+// newRuntimeRecord returns the exported form of the given runtime
+func newRuntimeRecord(rt *JobRuntime) runtimeRecord {
+	r := runtimeRecord{
+		RunID:     rt.RunID,
+		Scheduled: rt.Scheduled,
+		Triggered: rt.Triggered,
+		Reason:    rt.Reason,
+		Start:     rt.Start,
+		End:       rt.End,
+	}
+	if rt.Error != nil {
+		r.Error = rt.Error.Error()
+	}
+	if len(rt.Attrs) > 0 {
+		r.Attrs = make(map[string]string, len(rt.Attrs))
+		for _, attr := range rt.Attrs {
+			r.Attrs[attr.Key] = attr.Value.String()
+		}
+	}
+	return r
+}
+
+// ExportRuntimes writes the runtimes selected by filter to w in the
+// given format, for auditing or loading into other tools (ex: the
+// result of [ScheduledJob.Runtimes]). Attributes are exported as
+// strings, and are omitted from CSV.
+func ExportRuntimes(
+	w io.Writer,
+	format ExportFormat,
+	runtimes []*JobRuntime,
+	filter RuntimeFilter,
+) error {
+	records := make([]runtimeRecord, 0, len(runtimes))
+	for _, rt := range runtimes {
+		if filter.match(rt) {
+			records = append(records, newRuntimeRecord(rt))
+		}
+	}
+
+	switch format {
+	case ExportJSON:
+		return json.NewEncoder(w).Encode(records)
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"run_id", "scheduled", "start", "end", "error"})
+		for _, r := range records {
+			_ = cw.Write(
+				[]string{
+					r.RunID,
+					formatExportTime(r.Scheduled),
+					formatExportTime(r.Start),
+					formatExportTime(r.End),
+					r.Error,
+				},
+			)
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown export format %d", format)
+	}
+}
+
+// formatExportTime formats t for CSV, leaving zero times empty
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// archive moves runtimes beyond MaxRuntimes, or older than
+// MaxRuntimeAge, out of memory and into the RuntimeStore (if set).
+// If the store returns an error, the runtimes are kept in memory
+// and archived on a later attempt.
+func (s *ScheduledJob) archive(ctx context.Context) {
+	maxRuntimes := s.options.MaxRuntimes
+	maxAge := s.options.MaxRuntimeAge
+	if maxRuntimes <= 0 && maxAge <= 0 {
+		return
+	}
+	s.archiveMu.Lock()
+	defer s.archiveMu.Unlock()
+
+	s.mu.RLock()
+	n := 0
+	if maxRuntimes > 0 {
+		n = max(len(s.runtimes)-maxRuntimes, 0)
+	}
+	if maxAge > 0 {
+		cutoff := clockNow(s.options.Clock).Add(-maxAge)
+		for n < len(s.runtimes) && s.runtimes[n].End.Before(cutoff) {
+			n++
+		}
+	}
+	archived := slices.Clone(s.runtimes[:n])
+	s.mu.RUnlock()
+	if n == 0 {
+		return
+	}
+
+	if store := s.options.RuntimeStore; store != nil {
+		if err := store.ArchiveRuntimes(ctx, s.runtimeKey(), archived); err != nil {
+			jobLogger().Error(
+				"failed to archive runtimes",
+				"error", err,
+				"scheduled_job", s,
+			)
+			return
+		}
+	}
+
+	// runtimes are only removed here, while archiveMu is
+	// held, so the archived runtimes are still at the front
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runtimes = slices.Clone(s.runtimes[n:])
+}
+
+// runtimeKey returns the key the job's runtimes are archived under
+func (s *ScheduledJob) runtimeKey() string {
+	if s.options.Name != "" {
+		return s.options.Name
+	}
+	return s.Schedule().String()
+}
@@ -0,0 +1,138 @@
+package crong
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportRuntimes(t *testing.T) {
+	start := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	runtimes := []*JobRuntime{
+		{RunID: "a", Scheduled: start, Start: start, End: start.Add(time.Second)},
+		{
+			RunID:     "b",
+			Scheduled: start.Add(time.Hour),
+			Reason:    RunCatchUp,
+			Start:     start.Add(time.Hour),
+			End:       start.Add(time.Hour + time.Second),
+			Error:     errors.New("boom"),
+			Attrs:     []slog.Attr{slog.Int("rows", 3)},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportRuntimes(&buf, ExportJSON, runtimes, RuntimeFilter{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, len(records), 2)
+	assertEqual(t, records[1]["run_id"], any("b"))
+	assertEqual(t, records[1]["reason"], any("catch_up"))
+	assertEqual(t, records[1]["error"], any("boom"))
+	assertEqual(t, records[1]["attrs"].(map[string]any)["rows"], any("3"))
+	if _, ok := records[0]["error"]; ok {
+		t.Errorf("expected no error for a successful run")
+	}
+
+	buf.Reset()
+	err := ExportRuntimes(&buf, ExportCSV, runtimes, RuntimeFilter{ErrorsOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assertEqual(t, len(lines), 2)
+	assertEqual(t, lines[0], "run_id,scheduled,start,end,error")
+	assertEqual(
+		t,
+		lines[1],
+		"b,2024-02-21T11:00:00Z,2024-02-21T11:00:00Z,2024-02-21T11:00:01Z,boom",
+	)
+
+	buf.Reset()
+	err = ExportRuntimes(
+		&buf,
+		ExportCSV,
+		runtimes,
+		RuntimeFilter{Since: start.Add(time.Minute), Until: start.Add(time.Hour)},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, strings.Count(buf.String(), "\n"), 1)
+
+	if err = ExportRuntimes(&buf, ExportFormat(99), runtimes, RuntimeFilter{}); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestJobArchiveRuntimes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	store := NewMemoryRuntimeStore()
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			Name:                 "report",
+			TickerReceiveTimeout: 5 * time.Second,
+			MaxRuntimes:          2,
+			RuntimeStore:         store,
+		},
+		func(dt time.Time) error {
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	for range 5 {
+		sj.ticker.tick(ctx)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.Runs.Load() == 5 && len(store.Runtimes("report")) == 3
+		},
+	)
+	assertEqual(t, len(sj.Runtimes()), 2)
+}
+
+func TestArchiveRuntimeAge(t *testing.T) {
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	now := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	sj := &ScheduledJob{
+		schedule: s,
+		options: ScheduledJobOptions{
+			MaxRuntimeAge: time.Hour,
+			Clock: ClockFunc(
+				func() time.Time {
+					return now
+				},
+			),
+		},
+	}
+	sj.runtimes = []*JobRuntime{
+		{RunID: "old", End: now.Add(-2 * time.Hour)},
+		{RunID: "new", End: now.Add(-time.Minute)},
+	}
+
+	// without a store, old runtimes are discarded
+	sj.archive(context.Background())
+	assertEqual(t, len(sj.runtimes), 1)
+	assertEqual(t, sj.runtimes[0].RunID, "new")
+}
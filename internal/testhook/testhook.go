@@ -0,0 +1,13 @@
+// Package testhook connects package crongtest to the
+// unexported parts of package crong it needs
+package testhook
+
+import (
+	"context"
+	"time"
+)
+
+// Tick sends a synthetic tick for the given time to a *crong.Ticker
+// or *crong.ScheduledJob, returning true if the tick was sent. It's
+// set by package crong.
+var Tick func(ctx context.Context, target any, at time.Time) bool
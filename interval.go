@@ -0,0 +1,33 @@
+package crong
+
+import (
+	"fmt"
+	"time"
+)
+
+// FromInterval returns a cron expression running every d, starting at
+// the top of the hour or day (ex: 15m is "*/15 * * * *", and 6h is
+// "0 */6 * * *"). Only intervals that evenly divide an hour or a day,
+// or are exactly a day or a week, can be expressed without drifting at
+// hour, day or month boundaries. Any other interval returns an error.
+func FromInterval(d time.Duration) (string, error) {
+	switch {
+	case d <= 0:
+		return "", fmt.Errorf("interval must be greater than 0, got %s", d)
+	case d%time.Minute != 0:
+		return "", fmt.Errorf("interval must be a whole number of minutes, got %s", d)
+	case d == time.Minute:
+		return "* * * * *", nil
+	case d < time.Hour && time.Hour%d == 0:
+		return fmt.Sprintf("*/%d * * * *", d/time.Minute), nil
+	case d == time.Hour:
+		return cronShortcut[Hourly], nil
+	case d < 24*time.Hour && d%time.Hour == 0 && (24*time.Hour)%d == 0:
+		return fmt.Sprintf("0 */%d * * *", d/time.Hour), nil
+	case d == 24*time.Hour:
+		return cronShortcut[Daily], nil
+	case d == 7*24*time.Hour:
+		return cronShortcut[Weekly], nil
+	}
+	return "", fmt.Errorf("no cron expression runs every %s", d)
+}
@@ -0,0 +1,56 @@
+package crong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromInterval(t *testing.T) {
+	type intervalCase struct {
+		Interval    time.Duration
+		Expect      string
+		ExpectError bool
+	}
+	cases := []intervalCase{
+		{Interval: time.Minute, Expect: "* * * * *"},
+		{Interval: 15 * time.Minute, Expect: "*/15 * * * *"},
+		{Interval: 30 * time.Minute, Expect: "*/30 * * * *"},
+		{Interval: time.Hour, Expect: "0 * * * *"},
+		{Interval: 6 * time.Hour, Expect: "0 */6 * * *"},
+		{Interval: 12 * time.Hour, Expect: "0 */12 * * *"},
+		{Interval: 24 * time.Hour, Expect: "0 0 * * *"},
+		{Interval: 7 * 24 * time.Hour, Expect: "0 0 * * 0"},
+		{Interval: 0, ExpectError: true},
+		{Interval: -time.Minute, ExpectError: true},
+		{Interval: 90 * time.Second, ExpectError: true},
+		{Interval: 7 * time.Minute, ExpectError: true},
+		{Interval: 90 * time.Minute, ExpectError: true},
+		{Interval: 5 * time.Hour, ExpectError: true},
+		{Interval: 48 * time.Hour, ExpectError: true},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Interval.String(), func(t *testing.T) {
+				expr, err := FromInterval(tc.Interval)
+				if tc.ExpectError {
+					if err == nil {
+						t.Fatalf("expected error, got %q", expr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, expr, tc.Expect)
+
+				// consecutive occurrences are the interval apart
+				s, err := New(expr, nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				next := s.Next(time.Date(2024, 3, 3, 1, 2, 0, 0, time.UTC))
+				assertEqual(t, s.Next(next).Sub(next), tc.Interval)
+			},
+		)
+	}
+}
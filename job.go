@@ -2,8 +2,12 @@ package crong
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"runtime/debug"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,15 +19,70 @@ const (
 	ScheduleStarted ScheduleState = iota + 1
 	ScheduleSuspended
 	ScheduleStopped
+
+	// ScheduleExhausted means the job's schedule has no more
+	// occurrences (ex: a one-shot (@at) schedule that has fired,
+	// or one that can't occur within [Schedule.NextErr]'s horizon),
+	// so the job won't run again
+	ScheduleExhausted
 )
 
+func (s ScheduleState) String() string {
+	switch s {
+	case ScheduleStarted:
+		return "started"
+	case ScheduleSuspended:
+		return "suspended"
+	case ScheduleStopped:
+		return "stopped"
+	case ScheduleExhausted:
+		return "exhausted"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalJSON encodes the state as its name (see [ScheduleState.String])
+func (s ScheduleState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON decodes a state from its name
+// (see [ScheduleState.String])
+func (s *ScheduleState) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for _, state := range []ScheduleState{
+		ScheduleStarted,
+		ScheduleSuspended,
+		ScheduleStopped,
+		ScheduleExhausted,
+	} {
+		if state.String() == name {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown schedule state '%s'", name)
+}
+
 type ScheduledJobOptions struct {
+	// Name identifies the job (and its ticker) in log records
+	Name string
+
 	// MaxConcurrent is the maximum number of concurrent job executions.
-	// If 0, there is no limit
+	// If 0, there's no worker pool: runs are serialized, each waiting
+	// for the previous run to finish, with ticks waiting for a run to
+	// start as they would for a single worker
 	MaxConcurrent int
 
 	// TickerReceiveTimeout is the maximum time the job's ticker will
-	// wait for the job to receive a tick on the Ticker.C channel
+	// wait for the job to receive a tick on the Ticker.C channel.
+	// If 0, the ticker waits until the tick is received. If
+	// [DropImmediately] (or any negative value), ticks are dropped
+	// when the job isn't ready to receive them
 	TickerReceiveTimeout time.Duration
 
 	// MaxFailures is the maximum number of times the job can fail
@@ -33,26 +92,347 @@ type ScheduledJobOptions struct {
 	// MaxConsecutiveFailures is the maximum number of consecutive
 	// times the job can fail before it is stopped. 0=no limit
 	MaxConsecutiveFailures int
+
+	// ClassifyFailure, if set, determines whether an error returned
+	// by the job function counts as a failure (toward MaxFailures and
+	// MaxConsecutiveFailures). If nil, every error counts. Ex, to
+	// ignore cancellation while shutting down:
+	//
+	//	func(err error) crong.FailureClass {
+	//		if errors.Is(err, context.Canceled) {
+	//			return crong.FailureIgnored
+	//		}
+	//		return crong.FailureCounted
+	//	}
+	ClassifyFailure func(err error) FailureClass
+
+	// Coalesce runs the job once, rather than once per occurrence,
+	// when several scheduled occurrences become due at once
+	// (see [TickerOptions.Coalesce])
+	Coalesce bool
+
+	// MissedTicks determines how occurrences missed while the host
+	// was asleep are handled (see [TickerOptions.MissedTicks])
+	MissedTicks MissedTickPolicy
+
+	// Tolerance is how far before a scheduled occurrence the job's
+	// ticker may fire (see [TickerOptions.Tolerance])
+	Tolerance time.Duration
+
+	// AlignStart only runs the job for occurrences after it was
+	// started, so the first run is for the next occurrence after
+	// Start (or ScheduleFunc). Without it, a tick for an occurrence
+	// that came due before the job started, such as one the ticker
+	// held while a job created with NewScheduledJob waited to be
+	// started, runs right away, which can look like a double run
+	// when the job starts near an occurrence. Triggered runs
+	// aren't affected.
+	AlignStart bool
+
+	// StartAfter, if set, skips occurrences before the given time,
+	// so a job registered ahead of time (ex: during a deploy) doesn't
+	// run until then. Triggered runs aren't affected.
+	StartAfter time.Time
+
+	// WarmupDelay, if set, skips occurrences until this long after
+	// the job was started (ex: to let a rollout settle). Triggered
+	// runs aren't affected.
+	WarmupDelay time.Duration
+
+	// NotAfter, if set, stops the job at the given time (ex: the end
+	// of a campaign), with [StopNotAfter] as its StopReason. Ticks for
+	// occurrences after it are skipped, and runs in progress when it
+	// passes have their context canceled. If it has already passed
+	// when the job starts, the job stops right away.
+	NotAfter time.Time
+
+	// MaxQueueDepth is the maximum number of ticks that can wait
+	// for a worker when all MaxConcurrent workers are busy. Ticks
+	// received while the queue is full are shed. If 0, ticks aren't
+	// queued, and the job waits for a worker before receiving the
+	// next tick.
+	MaxQueueDepth int
+
+	// MaxQueueAge is the maximum time a tick can wait for a worker.
+	// Ticks are shed as soon as they've waited longer, including the
+	// tick the job is holding when MaxQueueDepth is 0. If 0, there
+	// is no limit.
+	MaxQueueAge time.Duration
+
+	// Watermarks, if set, records the most recent occurrence the job
+	// ran for, and ticks for occurrences at or before it are skipped.
+	// The watermark is recorded as the run starts, so an occurrence
+	// runs at most once, even if the job is restarted. Shed ticks
+	// aren't recorded. If the store returns an error, the job runs
+	// anyway.
+	Watermarks WatermarkStore
+
+	// WatermarkKey is the key the job's watermark is stored under.
+	// Defaults to the schedule's cron expression.
+	WatermarkKey string
+
+	// Locker, if set with Watermarks, runs each occurrence of the
+	// schedule on one replica of the job, where replicas share the
+	// Locker, the watermark store and WatermarkKey. A replica locks
+	// the watermark key, then advances the watermark to the
+	// occurrence before it runs, and the other replicas skip the
+	// tick, counting it in Duplicates. An occurrence may be missed,
+	// but never runs twice:
+	//   - If the replica exits or fails during the run, the
+	//     occurrence isn't retried, as the watermark is already set.
+	//     This includes runs that return an error.
+	//   - If the Locker or the watermark store returns an error, or
+	//     another replica holds the lock, the tick is skipped,
+	//     rather than run without the lock.
+	//   - Replicas whose clocks lag may tick for an occurrence after
+	//     a later one has run, and skip it, as it's behind the
+	//     watermark. The watermark never moves backward.
+	// The lock is only held while the watermark is checked and set,
+	// so the guarantee holds as long as the store's writes are
+	// visible to the next replica to take the lock, and LockTTL is
+	// longer than that takes. With an [AdvanceWatermarkStore], the
+	// watermark is checked and set in one step, so it can't move
+	// backward even if a lock expires. Triggered runs don't take
+	// the lock.
+	Locker Locker
+
+	// LockTTL is how long a replica can hold the lock for an
+	// occurrence (see Locker), in case it exits before releasing it.
+	// Defaults to 1 minute.
+	LockTTL time.Duration
+
+	// TriggerDedupeWindow, if set, skips the tick for a scheduled
+	// occurrence up to this long after the job was last triggered
+	// (see [ScheduledJob.Trigger]), so a run kicked off by hand just
+	// before a scheduled one isn't followed by a second run. Skipped
+	// ticks are counted in Duplicates.
+	TriggerDedupeWindow time.Duration
+
+	// StuckRunThreshold, if set, flags runs that are still going
+	// after this long: a warning is logged, StuckRuns is incremented
+	// and OnStuckRun is called. The run isn't interrupted.
+	StuckRunThreshold time.Duration
+
+	// Pool, if set, is a worker pool shared with other jobs, which
+	// runs the job instead of its own workers. MaxConcurrent,
+	// MaxQueueDepth and MaxQueueAge don't apply, and ticks wait
+	// in the pool's queue until a worker is free.
+	Pool *WorkerPool
+
+	// Priority orders the job's ticks in Pool's queue: ticks from
+	// jobs with a higher priority start first
+	Priority int
+
+	// Queue, if set, carries the job's ticks, keyed by Name (or, if
+	// it's empty, the schedule's cron expression), between the job
+	// scheduling them and the job running them, so they can be
+	// different jobs (or processes) (see QueueRole). Without a
+	// Queue, ticks go straight from the job's ticker to its workers,
+	// as they would through a [MemoryTickQueue]. Triggered runs
+	// aren't queued, and run locally. If it's an [AckTickQueue], each
+	// tick is acknowledged once it has run (or been skipped), even
+	// if the job is stopping, so a run that finishes during shutdown
+	// isn't repeated. A tick the job stopped before finishing with
+	// (including a run that returned the job's cancellation) is
+	// redelivered.
+	Queue TickQueue
+
+	// QueueRole is whether the job enqueues its ticks, runs the ticks
+	// dequeued for it, or (by default) both. It only applies with
+	// a Queue.
+	QueueRole QueueRole
+
+	// MaxRuntimes, if set, is the maximum number of runtimes kept in
+	// memory (see [ScheduledJob.Runtimes]). Older runtimes are moved
+	// to RuntimeStore, or discarded if it isn't set.
+	MaxRuntimes int
+
+	// MaxRuntimeAge, if set, is how long after a run ends its runtime
+	// is kept in memory, before it's moved to RuntimeStore (or
+	// discarded, if it isn't set). Runtimes are checked as runs end.
+	MaxRuntimeAge time.Duration
+
+	// RuntimeStore, if set, archives runtimes trimmed from memory by
+	// MaxRuntimes and MaxRuntimeAge, under the job's Name (or its
+	// schedule's cron expression, if it has no name). If the store
+	// returns an error, the runtimes stay in memory until a later
+	// run's attempt succeeds.
+	RuntimeStore RuntimeStore
+
+	// Clock, if set, provides the times recorded for runs. It's
+	// passed on to the job's ticker (see [TickerOptions.Clock]),
+	// whose tick times become JobRuntime.Start, and provides
+	// JobRuntime.End.
+	Clock Clock
+
+	// Budget, if set, limits the job's total runtime per window of
+	// time. It can be shared, to limit the combined runtime of a group
+	// of jobs. Ticks received once the window's budget is used up are
+	// skipped, and counted in [ScheduledJob.OverBudget].
+	Budget *Budget
+
+	// SLA, if set, is how long after its scheduled occurrence each
+	// run is expected to finish (ex: 15 minutes, for a run scheduled
+	// at 02:00 to finish by 02:15). Runs are counted in SLAHits or
+	// SLAMisses, and OnSLAMiss is called as soon as a run misses it.
+	// Triggered runs (see [ScheduledJob.Trigger]) and skipped ticks
+	// aren't counted.
+	SLA time.Duration
+
+	// OnSLAMiss, if set, is called with the run's ID and scheduled
+	// occurrence when a run misses the SLA. It's called from its own
+	// goroutine if the run is still going.
+	OnSLAMiss func(runID string, scheduled time.Time)
+
+	// OnStuckRun, if set, is called with the run's ID and how long
+	// it has been running when a run exceeds StuckRunThreshold.
+	// It's called from its own goroutine.
+	OnStuckRun func(runID string, elapsed time.Duration)
+
+	// DegradedFailureRate, if set, is the fraction of runs (0-1) that
+	// can fail within DegradedWindow before the job is considered
+	// degraded (ex: 0.2, to alert on more than 20% of runs failing).
+	// Unlike MaxFailures, the job keeps running: OnDegraded is called
+	// when it becomes degraded, and again if it recovers, then
+	// degrades again (see [ScheduledJob.Degraded]). Errors ignored
+	// by ClassifyFailure aren't counted as runs.
+	DegradedFailureRate float64
+
+	// DegradedWindow is how far back runs are counted toward
+	// DegradedFailureRate, by when they ended. Defaults to an hour.
+	DegradedWindow time.Duration
+
+	// DegradedMinRuns is the number of runs needed in DegradedWindow
+	// before the job can be considered degraded, so a single failed
+	// run isn't a 100% failure rate. Defaults to 1.
+	DegradedMinRuns int
+
+	// OnDegraded, if set, is called with the failure rate when the
+	// job becomes degraded. It's called from the goroutine of the
+	// run that pushed the rate over DegradedFailureRate.
+	OnDegraded func(rate float64)
 }
 
 func (s ScheduledJobOptions) LogValue() slog.Value {
 	return slog.GroupValue(
+		slog.String("name", s.Name),
 		slog.Int("max_concurrent", s.MaxConcurrent),
 		slog.Int("max_failures", s.MaxFailures),
 		slog.Int("max_consecutive_failures", s.MaxConsecutiveFailures),
 		slog.Duration("ticker_receive_timeout", s.TickerReceiveTimeout),
+		slog.Bool("coalesce", s.Coalesce),
+		slog.String("missed_ticks", s.MissedTicks.String()),
+		slog.Duration("tolerance", s.Tolerance),
+		slog.Bool("align_start", s.AlignStart),
+		slog.Time("start_after", s.StartAfter),
+		slog.Duration("warmup_delay", s.WarmupDelay),
+		slog.Time("not_after", s.NotAfter),
+		slog.Int("max_queue_depth", s.MaxQueueDepth),
+		slog.Duration("max_queue_age", s.MaxQueueAge),
+		slog.Duration("lock_ttl", s.LockTTL),
+		slog.Duration("trigger_dedupe_window", s.TriggerDedupeWindow),
+		slog.Duration("stuck_run_threshold", s.StuckRunThreshold),
+		slog.Duration("sla", s.SLA),
+		slog.Float64("degraded_failure_rate", s.DegradedFailureRate),
+		slog.Duration("degraded_window", s.DegradedWindow),
+		slog.String("queue_role", s.QueueRole.String()),
 	)
 }
 
+// tickerOptions returns the options for the job's [Ticker]
+func (s ScheduledJobOptions) tickerOptions() TickerOptions {
+	return TickerOptions{
+		Name:        s.Name,
+		SendTimeout: s.TickerReceiveTimeout,
+		Coalesce:    s.Coalesce,
+		MissedTicks: s.MissedTicks,
+		Tolerance:   s.Tolerance,
+		Clock:       s.Clock,
+	}
+}
+
+// FailureClass determines whether an error returned by a job
+// function counts as a failure (see [ScheduledJobOptions.ClassifyFailure])
+type FailureClass int
+
+const (
+	// FailureCounted counts the error as a failure
+	FailureCounted FailureClass = iota
+
+	// FailureIgnored records the error on the run's [JobRuntime],
+	// without counting it as a failure or resetting the job's
+	// consecutive failures
+	FailureIgnored
+)
+
+func (c FailureClass) String() string {
+	switch c {
+	case FailureCounted:
+		return "counted"
+	case FailureIgnored:
+		return "ignored"
+	default:
+		return "unknown"
+	}
+}
+
+// StopReason is why a [ScheduledJob] stopped
+// (see [ScheduledJob.StopReason])
+type StopReason int
+
+const (
+	// StopReasonNone means the job hasn't stopped
+	StopReasonNone StopReason = iota
+
+	// StopRequested means [ScheduledJob.Stop] was called
+	StopRequested
+
+	// StopContextDone means the context the job was
+	// started with was canceled (or timed out)
+	StopContextDone
+
+	// StopMaxFailures means the job reached
+	// [ScheduledJobOptions.MaxFailures]
+	StopMaxFailures
+
+	// StopMaxConsecutiveFailures means the job reached
+	// [ScheduledJobOptions.MaxConsecutiveFailures]
+	StopMaxConsecutiveFailures
+
+	// StopNotAfter means the job reached [ScheduledJobOptions.NotAfter]
+	StopNotAfter
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopReasonNone:
+		return "none"
+	case StopRequested:
+		return "requested"
+	case StopContextDone:
+		return "context_done"
+	case StopMaxFailures:
+		return "max_failures"
+	case StopMaxConsecutiveFailures:
+		return "max_consecutive_failures"
+	case StopNotAfter:
+		return "not_after"
+	default:
+		return "unknown"
+	}
+}
+
 // ScheduledJob is a function that runs on Ticker ticks
 // for a Schedule
 type ScheduledJob struct {
-	schedule *Schedule
-	ticker   *Ticker
-	f        func(t time.Time) error
-	runtimes []*JobRuntime
-	mu       sync.RWMutex
-	stopCh   chan struct{}
+	// schedule is replaced by SetLocation, guarded by scheduleMu
+	schedule   *Schedule
+	scheduleMu sync.RWMutex
+	ticker     *Ticker
+	f          func(ctx context.Context, t time.Time) error
+	runtimes   []*JobRuntime
+	mu         sync.RWMutex
+	stopCh     chan struct{}
 
 	// Failures is the number of times the job has failed
 	Failures atomic.Int64
@@ -66,38 +446,160 @@ type ScheduledJob struct {
 	// Running is the number of times the job is currently running
 	Running atomic.Int64
 
+	// Duplicates is the number of ticks skipped because the job had
+	// already run for the occurrence, or another replica had (see
+	// [ScheduledJobOptions.Watermarks], [ScheduledJobOptions.Locker]
+	// and [ScheduledJobOptions.TriggerDedupeWindow])
+	Duplicates atomic.Int64
+
+	// BlackedOut is the number of ticks skipped because they fell in
+	// a blackout window of the job's [Scheduler] (see
+	// [SchedulerOptions.Blackouts])
+	BlackedOut atomic.Int64
+
+	// OverBudget is the number of ticks skipped because the job's
+	// [ScheduledJobOptions.Budget] was used up
+	OverBudget atomic.Int64
+
+	// SLAHits is the number of runs that finished within
+	// [ScheduledJobOptions.SLA]
+	SLAHits atomic.Int64
+
+	// SLAMisses is the number of runs that didn't finish within
+	// [ScheduledJobOptions.SLA]
+	SLAMisses atomic.Int64
+
+	// StuckRuns is the number of runs that exceeded
+	// [ScheduledJobOptions.StuckRunThreshold]
+	StuckRuns atomic.Int64
+
+	// Shed is the number of ticks discarded without running the job,
+	// because the queue was full or they waited too long for a worker
+	// (see [ScheduledJobOptions.MaxQueueDepth] and
+	// [ScheduledJobOptions.MaxQueueAge])
+	Shed atomic.Int64
+
 	state             atomic.Int64
+	stopReason        atomic.Int64
 	previouslyStarted atomic.Bool
 	startMu           sync.Mutex
 	options           ScheduledJobOptions
+
+	// maxConcurrent is the current worker pool size, initially
+	// options.MaxConcurrent (see SetMaxConcurrent)
+	maxConcurrent atomic.Int64
+
+	// resized signals the dispatcher that maxConcurrent changed
+	resized chan struct{}
+
+	// triggers holds a tick sent by Trigger, until the
+	// dispatcher receives it
+	triggers chan Tick
+
+	// stopping is closed once the job starts stopping, and is nil
+	// until it's started. It's guarded by mu.
+	stopping <-chan struct{}
+
+	// triggerPending is set by Trigger until the triggered
+	// run starts (or is shed)
+	triggerPending atomic.Bool
+
+	// lastTrigger is the time Trigger was last called, in
+	// nanoseconds since the Unix epoch, or 0 if it hasn't been
+	lastTrigger atomic.Int64
+
+	// durations tracks run durations (see Stats)
+	durations durationStats
+
+	// active holds the runs in progress, guarded by mu
+	active map[*run]struct{}
+
+	// archiveMu serializes archiving runtimes
+	archiveMu sync.Mutex
+
+	// scheduler is the Scheduler the job was added to, if any
+	scheduler *Scheduler
+
+	// outcomes tracks the failure rate of recent runs
+	// (see DegradedFailureRate)
+	outcomes failureWindow
+
+	// done is closed once the job has stopped, and its runs
+	// have finished
+	done chan struct{}
+
+	// drained, cancelled and dropped count the runs that finished,
+	// the runs that were cancelled, and the ticks that never ran,
+	// after the job stopped (see Shutdown)
+	drained   atomic.Int64
+	cancelled atomic.Int64
+	dropped   atomic.Int64
+
+	// startedAt is when the job was started, set before its
+	// ticks are received (see AlignStart and WarmupDelay)
+	startedAt time.Time
 }
 
 func NewScheduledJob(
 	schedule *Schedule,
 	opts ScheduledJobOptions,
 	f func(t time.Time) error,
+) *ScheduledJob {
+	return NewScheduledJobContext(schedule, opts, withoutContext(f))
+}
+
+// NewScheduledJobContext creates a new ScheduledJob, as with
+// [NewScheduledJob], for a function that also receives the run's
+// context. The context is canceled when the job stops, and can be
+// used with [AddRunAttrs].
+func NewScheduledJobContext(
+	schedule *Schedule,
+	opts ScheduledJobOptions,
+	f func(ctx context.Context, t time.Time) error,
 ) *ScheduledJob {
 	job := &ScheduledJob{
 		schedule: schedule,
-		ticker: NewTicker(
+		ticker: NewTickerWithOptions(
 			context.Background(),
 			schedule,
-			opts.TickerReceiveTimeout,
+			opts.tickerOptions(),
 		),
 		f:        f,
 		runtimes: make([]*JobRuntime, 0),
 		stopCh:   make(chan struct{}, 1),
+		resized:  make(chan struct{}, 1),
+		triggers: make(chan Tick, 1),
+		done:     make(chan struct{}),
 		options:  opts,
 	}
+	job.maxConcurrent.Store(int64(opts.MaxConcurrent))
 
 	return job
 }
 
-func (s ScheduledJob) LogValue() slog.Value {
-	return slog.GroupValue(
-		slog.String("schedule", s.schedule.String()),
+func (s *ScheduledJob) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 12)
+	if s.options.Name != "" {
+		attrs = append(attrs, slog.String("name", s.options.Name))
+	}
+	state := s.State()
+	attrs = append(
+		attrs,
+		slog.String("schedule", s.Schedule().String()),
+		slog.String("state", state.String()),
+	)
+	if state == ScheduleStarted || state == ScheduleSuspended {
+		if next := s.Schedule().Next(time.Now()); !next.IsZero() {
+			attrs = append(attrs, slog.Time("next", next))
+		}
+	}
+	if reason := s.StopReason(); reason != StopReasonNone {
+		attrs = append(attrs, slog.String("stop_reason", reason.String()))
+	}
+	attrs = append(
+		attrs,
 		slog.Group(
-			"options", slog.Int("max_concurrent", s.options.MaxConcurrent),
+			"options", slog.Int64("max_concurrent", s.maxConcurrent.Load()),
 			slog.Int("max_failures", s.options.MaxFailures),
 			slog.Int(
 				"max_consecutive_failures",
@@ -107,12 +609,25 @@ func (s ScheduledJob) LogValue() slog.Value {
 				"ticker_receive_timeout",
 				s.options.TickerReceiveTimeout,
 			),
+			slog.Bool("coalesce", s.options.Coalesce),
+			slog.String("missed_ticks", s.options.MissedTicks.String()),
+			slog.Duration("tolerance", s.options.Tolerance),
+			slog.Int("max_queue_depth", s.options.MaxQueueDepth),
+			slog.Duration("max_queue_age", s.options.MaxQueueAge),
+			slog.Duration(
+				"stuck_run_threshold",
+				s.options.StuckRunThreshold,
+			),
 		),
 		slog.Int64("failures", s.Failures.Load()),
 		slog.Int64("consecutive_failures", s.ConsecutiveFailures.Load()),
 		slog.Int64("runs", s.Runs.Load()),
 		slog.Int64("running", s.Running.Load()),
+		slog.Int64("duplicates", s.Duplicates.Load()),
+		slog.Int64("shed", s.Shed.Load()),
+		slog.Int64("stuck_runs", s.StuckRuns.Load()),
 	)
+	return slog.GroupValue(attrs...)
 }
 
 // ScheduleFunc creates and starts a new ScheduledJob with the given schedule and options.
@@ -152,18 +667,50 @@ func ScheduleFunc(
 	opts ScheduledJobOptions,
 	f func(t time.Time) error,
 ) *ScheduledJob {
+	return ScheduleFuncContext(ctx, schedule, opts, withoutContext(f))
+}
+
+// ScheduleFuncContext creates and starts a new ScheduledJob, as with
+// [ScheduleFunc], for a function that also receives the run's context.
+// The context is canceled when the job stops, and can be used with
+// [AddRunAttrs].
+func ScheduleFuncContext(
+	ctx context.Context,
+	schedule *Schedule,
+	opts ScheduledJobOptions,
+	f func(ctx context.Context, t time.Time) error,
+) *ScheduledJob {
+	return scheduleFunc(ctx, schedule, opts, f, nil)
+}
+
+// scheduleFunc creates and starts a new ScheduledJob, which
+// belongs to the given scheduler, if it isn't nil
+func scheduleFunc(
+	ctx context.Context,
+	schedule *Schedule,
+	opts ScheduledJobOptions,
+	f func(ctx context.Context, t time.Time) error,
+	scheduler *Scheduler,
+) *ScheduledJob {
+	startedAt := time.Now()
 	s := &ScheduledJob{
+		startedAt:         startedAt,
 		schedule:          schedule,
-		ticker:            NewTicker(ctx, schedule, opts.TickerReceiveTimeout),
+		ticker:            NewTickerWithOptions(ctx, schedule, opts.tickerOptions()),
 		f:                 f,
 		runtimes:          make([]*JobRuntime, 0),
 		stopCh:            make(chan struct{}, 1),
+		resized:           make(chan struct{}, 1),
+		triggers:          make(chan Tick, 1),
+		done:              make(chan struct{}),
 		state:             atomic.Int64{},
 		previouslyStarted: atomic.Bool{},
 		options:           opts,
+		scheduler:         scheduler,
 	}
 	s.state.Store(int64(ScheduleStarted))
 	s.previouslyStarted.Store(true)
+	s.maxConcurrent.Store(int64(opts.MaxConcurrent))
 
 	go func() {
 		_ = s.start(ctx)
@@ -186,6 +733,7 @@ func (s *ScheduledJob) Start(ctx context.Context) error {
 // Stop stops job execution. After Stop is called, the job cannot be
 // restarted.
 func (s *ScheduledJob) Stop(ctx context.Context) bool {
+	s.stopWith(StopRequested)
 	select {
 	case <-ctx.Done():
 	case s.stopCh <- struct{}{}:
@@ -221,18 +769,82 @@ func (s *ScheduledJob) Runtimes() []*JobRuntime {
 	return s.runtimes[:]
 }
 
+// Missed returns the scheduled occurrences after the job's most recent
+// successful run, up through the current time, so callers can decide
+// whether to backfill them. If the job hasn't run successfully after
+// since, the occurrences after since are returned instead.
+func (s *ScheduledJob) Missed(since time.Time) []time.Time {
+	return s.missed(since, time.Now())
+}
+
+// missed returns the scheduled occurrences after the most recent
+// successful run (or since), up through now
+func (s *ScheduledJob) missed(since time.Time, now time.Time) []time.Time {
+	if last := s.lastSuccess(); last.After(since) {
+		since = last
+	}
+	var occurrences []time.Time
+	schedule := s.Schedule()
+	next := schedule.NextDue(since, s.options.Tolerance)
+	for ; !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		occurrences = append(occurrences, next)
+	}
+	return occurrences
+}
+
+// lastSuccess returns the start of the most recent run that
+// didn't return an error, or the zero time if there isn't one
+func (s *ScheduledJob) lastSuccess() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var last time.Time
+	for _, rt := range s.runtimes {
+		if rt.Error == nil && rt.Start.After(last) {
+			last = rt.Start
+		}
+	}
+	return last
+}
+
 func (s *ScheduledJob) State() ScheduleState {
 	return ScheduleState(s.state.Load())
 }
 
+// StopReason returns why the job stopped, or [StopReasonNone] if it
+// hasn't. If there were several reasons (ex: Stop was called after
+// the job reached MaxFailures), the first is returned.
+func (s *ScheduledJob) StopReason() StopReason {
+	return StopReason(s.stopReason.Load())
+}
+
+// stopWith records the reason the job is stopping,
+// unless a reason was already recorded
+func (s *ScheduledJob) stopWith(reason StopReason) {
+	s.stopReason.CompareAndSwap(int64(StopReasonNone), int64(reason))
+}
+
+// stop signals the job to stop for the given reason, without waiting
+func (s *ScheduledJob) stop(reason StopReason) {
+	s.stopWith(reason)
+	select {
+	case s.stopCh <- struct{}{}:
+	default:
+	}
+}
+
 // Start starts the job. If the job has already been started,
 // it returns an error. If the job has been stopped, it returns an error.
 func (s *ScheduledJob) start(ctx context.Context) error {
+	defer close(s.done)
 	s.mu.Lock()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	s.stopping = ctx.Done()
 
 	s.state.Store(int64(ScheduleStarted))
+	if s.startedAt.IsZero() {
+		s.startedAt = time.Now()
+	}
 
 	defer s.ticker.Stop()
 	s.previouslyStarted.Store(true)
@@ -246,6 +858,7 @@ func (s *ScheduledJob) start(ctx context.Context) error {
 		defer wg.Done()
 		select {
 		case <-ctx.Done():
+			s.stopWith(StopContextDone)
 			return
 		case <-s.stopCh:
 			cancel()
@@ -253,116 +866,683 @@ func (s *ScheduledJob) start(ctx context.Context) error {
 		}
 	}()
 
-	var jobCh chan time.Time
+	// Stops the job once NotAfter passes
+	if notAfter := s.options.NotAfter; !notAfter.IsZero() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timer := time.NewTimer(time.Until(notAfter))
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+			case <-timer.C:
+				jobLogger().Info("not after time reached, stopping job", "scheduled_job", s)
+				s.stop(StopNotAfter)
+			}
+		}()
+	}
+
+	// Marks the job exhausted once the schedule has
+	// no more occurrences
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+		case <-s.ticker.Exhausted():
+			s.exhaust()
+		}
+	}()
+
+	// Waits for ticks on the Ticker.Ticks channel, then
+	// executes the job
+	ticks := s.tickSource(ctx, &wg)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if s.options.Pool != nil {
+			s.dispatchPool(ctx, &wg, ticks)
+			return
+		}
+		s.dispatch(ctx, &wg, ticks)
+	}()
+	wg.Wait()
+	return nil
+}
+
+// exhaust moves a started or suspended job to ScheduleExhausted
+func (s *ScheduledJob) exhaust() {
+	for _, from := range []ScheduleState{ScheduleStarted, ScheduleSuspended} {
+		if s.state.CompareAndSwap(int64(from), int64(ScheduleExhausted)) {
+			jobLogger().Warn(
+				"schedule has no more occurrences, job exhausted",
+				"scheduled_job", s,
+			)
+			return
+		}
+	}
+}
+
+// dispatch receives ticks from source (see tickSource) and starts
+// runs for them, queueing ticks while all workers are busy. Only
+// dispatch tracks the worker pool, so resizes are signaled on
+// s.resized.
+func (s *ScheduledJob) dispatch(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	source <-chan Tick,
+) {
+	var queue []queuedTick
+	if tk, ok := s.startupTick(); ok {
+		queue = append(queue, queuedTick{tick: tk, queued: time.Now()})
+	}
+	running := 0
+	finished := make(chan struct{})
+
+	for {
+		var expired <-chan time.Time
+		var expiry *time.Timer
+		queue = s.shedExpired(ctx, queue)
+		if len(queue) > 0 && s.options.MaxQueueAge > 0 {
+			expiry = time.NewTimer(
+				s.options.MaxQueueAge - time.Since(queue[0].queued),
+			)
+			expired = expiry.C
+		}
+
+		// once the job stops, queued ticks are dropped rather than
+		// started as workers free up. Serialized runs (n == 0) are
+		// started one at a time, so ticks wait in the queue (and
+		// are shed from it) until the previous run finishes.
+		n := int(s.maxConcurrent.Load())
+		for ctx.Err() == nil && len(queue) > 0 && running < max(n, 1) {
+			qt := queue[0]
+			queue = queue[1:]
+			tk := qt.tick
+			if s.alreadyRan(ctx, qt.tick) {
+				s.Duplicates.Add(1)
+				jobLogger().Info(
+					"already ran for occurrence, skipping tick",
+					"scheduled_job", s,
+					"tick", tk.Time,
+				)
+				s.settle(ctx, tk, false)
+				continue
+			}
 
-	if s.options.MaxConcurrent > 0 {
-		jobCh = make(chan time.Time)
-		defer close(jobCh)
-		for i := 0; i < s.options.MaxConcurrent; i++ {
+			running++
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				for {
-					select {
-					case <-ctx.Done():
-						return
-					case rt := <-jobCh:
-						s.execute(rt)
-					}
+				s.execute(ctx, tk)
+				select {
+				case <-ctx.Done():
+				case finished <- struct{}{}:
 				}
 			}()
 		}
+
+		// without a queue, the job waits for a worker
+		// before receiving the next tick
+		ticks := source
+		if s.options.MaxQueueDepth <= 0 && len(queue) > 0 {
+			ticks = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			s.dropped.Add(int64(len(queue)))
+			return
+		case <-finished:
+			running--
+		case <-s.resized:
+		case <-expired:
+		case tk := <-s.triggers:
+			queue = append(queue, queuedTick{tick: tk, queued: time.Now()})
+		case tk := <-ticks:
+			rt := tk.Time
+			switch {
+			case ScheduleState(s.state.Load()) == ScheduleSuspended:
+				jobLogger().Debug(
+					"execution suspended, skipping tick",
+					"scheduled_job", s,
+					"tick", rt,
+				)
+				s.settle(ctx, tk, false)
+			case s.outsideWindow(tk):
+				s.settle(ctx, tk, false)
+			case s.options.MaxQueueDepth > 0 &&
+				len(queue) >= s.options.MaxQueueDepth:
+				s.shed(rt, "queue full")
+				s.settle(ctx, tk, false)
+			default:
+				queue = append(queue, queuedTick{tick: tk, queued: time.Now()})
+			}
+		}
+		if expiry != nil {
+			expiry.Stop()
+		}
 	}
+}
 
-	// Waits for ticks on the Ticker.C channel, then
-	// executes the job
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case rt := <-s.ticker.C:
-				switch {
-				case ScheduleState(s.state.Load()) == ScheduleSuspended:
-					Logger.Debug(
-						"execution suspended, skipping tick",
+// dispatchPool receives ticks (see tickSource) and submits
+// runs for them to the shared worker pool. Runs still waiting in the
+// pool's queue when the job stops are removed.
+func (s *ScheduledJob) dispatchPool(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	ticks <-chan Tick,
+) {
+	pool := s.options.Pool
+	var pending []*poolTask
+	submit := func(tk Tick) {
+		pending = slices.DeleteFunc(pending, pool.isStarted)
+
+		wg.Add(1)
+		task := pool.submit(
+			s.options.Priority, func() {
+				defer wg.Done()
+				if s.alreadyRan(ctx, tk) {
+					s.Duplicates.Add(1)
+					jobLogger().Info(
+						"already ran for occurrence, skipping tick",
 						"scheduled_job", s,
-						"tick", rt,
+						"tick", tk.Time,
 					)
-				case jobCh == nil:
-					wg.Add(1)
-					go func() {
-						defer wg.Done()
-						s.execute(rt)
-					}()
-				default:
-					jobCh <- rt
+					s.settle(ctx, tk, false)
+					return
+				}
+				s.execute(ctx, tk)
+			},
+		)
+		pending = append(pending, task)
+	}
+	if tk, ok := s.startupTick(); ok {
+		submit(tk)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			for _, task := range pending {
+				if pool.remove(task) {
+					s.dropped.Add(1)
+					wg.Done()
 				}
 			}
+			return
+		case tk := <-ticks:
+			if ScheduleState(s.state.Load()) == ScheduleSuspended {
+				jobLogger().Debug(
+					"execution suspended, skipping tick",
+					"scheduled_job", s,
+					"tick", tk.Time,
+				)
+				s.settle(ctx, tk, false)
+				continue
+			}
+			if s.outsideWindow(tk) {
+				s.settle(ctx, tk, false)
+				continue
+			}
+			submit(tk)
+		case tk := <-s.triggers:
+			submit(tk)
+		}
+	}
+}
+
+// startupTick returns the tick for a startup (@reboot) job's single
+// run, which the dispatcher starts before receiving any other ticks,
+// as the ticker has nothing to send. It returns false for other jobs.
+func (s *ScheduledJob) startupTick() (Tick, bool) {
+	schedule := s.Schedule()
+	if !schedule.reboot {
+		return Tick{}, false
+	}
+	now := clockNow(s.options.Clock).In(schedule.loc)
+	jobLogger().Info("queued startup run", "scheduled_job", s)
+	return Tick{
+		Time:        now,
+		Occurrences: 1,
+		First:       now,
+		Last:        now,
+		Reason:      RunStartup,
+	}, true
+}
+
+// outsideWindow returns true, and logs the skipped tick, if the tick
+// is for occurrences before the job is ready to run (see readyAt),
+// or after its NotAfter time. Triggered ticks are never skipped.
+func (s *ScheduledJob) outsideWindow(tk Tick) bool {
+	ready := s.readyAt()
+	if notAfter := s.options.NotAfter; !tk.Triggered && !notAfter.IsZero() &&
+		tk.First.After(notAfter) {
+		jobLogger().Info(
+			"occurrence is after job's not after time, skipping tick",
+			"scheduled_job", s,
+			"tick", tk.Time,
+			"occurrence", tk.First,
+		)
+		return true
+	}
+	if tk.Triggered || !tk.Last.Before(ready) {
+		return false
+	}
+	jobLogger().Info(
+		"occurrence is before job is ready, skipping tick",
+		"scheduled_job", s,
+		"tick", tk.Time,
+		"occurrence", tk.Last,
+		"ready_at", ready,
+	)
+	return true
+}
 
+// readyAt returns the time of the earliest occurrence the job can
+// run for, as set by AlignStart, StartAfter and WarmupDelay, or the
+// zero time if none are set
+func (s *ScheduledJob) readyAt() time.Time {
+	var ready time.Time
+	if s.options.AlignStart {
+		ready = s.startedAt
+	}
+	if s.options.StartAfter.After(ready) {
+		ready = s.options.StartAfter
+	}
+	if d := s.options.WarmupDelay; d > 0 {
+		if warm := s.startedAt.Add(d); warm.After(ready) {
+			ready = warm
 		}
-	}()
-	wg.Wait()
+	}
+	return ready
+}
+
+// shedExpired sheds ticks at the front of the queue that
+// have waited longer than MaxQueueAge
+func (s *ScheduledJob) shedExpired(
+	ctx context.Context,
+	queue []queuedTick,
+) []queuedTick {
+	maxAge := s.options.MaxQueueAge
+	if maxAge <= 0 {
+		return queue
+	}
+	for len(queue) > 0 && time.Since(queue[0].queued) > maxAge {
+		if queue[0].tick.Triggered {
+			s.triggerPending.Store(false)
+		}
+		s.shed(queue[0].tick.Time, "max queue age exceeded")
+		s.settle(ctx, queue[0].tick, false)
+		queue = queue[1:]
+	}
+	return queue
+}
+
+// Schedule returns the job's schedule
+func (s *ScheduledJob) Schedule() *Schedule {
+	s.scheduleMu.RLock()
+	defer s.scheduleMu.RUnlock()
+	return s.schedule
+}
+
+// SetLocation switches the job's schedule to the given location, and
+// recomputes its next scheduled time (see [Ticker.SetLocation])
+func (s *ScheduledJob) SetLocation(loc *time.Location) {
+	s.scheduleMu.Lock()
+	s.schedule = s.schedule.WithLocation(loc)
+	s.scheduleMu.Unlock()
+	s.ticker.SetLocation(loc)
+}
+
+// SetMaxConcurrent changes the maximum number of concurrent job
+// executions (see [ScheduledJobOptions.MaxConcurrent]) while the
+// job is running. When growing, queued ticks start immediately.
+// When shrinking, busy workers finish their current run, and new
+// runs wait until fewer than n are running. Setting 0 removes the
+// worker pool, and queued ticks run one at a time, as with a
+// MaxConcurrent of 0.
+func (s *ScheduledJob) SetMaxConcurrent(n int) error {
+	if n < 0 {
+		return errors.New("max concurrent must be 0 or greater")
+	}
+	s.maxConcurrent.Store(int64(n))
+	select {
+	case s.resized <- struct{}{}:
+	default:
+		// a resize is already pending
+	}
 	return nil
 }
 
-func (s *ScheduledJob) execute(rt time.Time) {
+// MaxConcurrent returns the current maximum number of concurrent
+// job executions (0=no limit)
+func (s *ScheduledJob) MaxConcurrent() int {
+	return int(s.maxConcurrent.Load())
+}
+
+// alreadyRan returns true if the watermark store shows the job has
+// already run for the tick's occurrence. Otherwise, the occurrence
+// is recorded as the new watermark. Triggered ticks aren't for an
+// occurrence, and always run.
+func (s *ScheduledJob) alreadyRan(ctx context.Context, tk Tick) bool {
+	if s.coveredByTrigger(tk) {
+		return true
+	}
+	store := s.options.Watermarks
+	if store == nil || tk.Triggered || s.options.Locker != nil {
+		// with a Locker, the watermark is checked as the run
+		// starts (see claim)
+		return false
+	}
+	key := s.watermarkKey()
+	occurrence := tk.Last.Truncate(s.Schedule().resolution())
+
+	advanced, err := advanceWatermark(ctx, store, key, occurrence)
+	if err != nil {
+		jobLogger().Error(
+			"failed to advance watermark",
+			"error", err,
+			"key", key,
+			"scheduled_job", s,
+		)
+		return false
+	}
+	return !advanced
+}
+
+// coveredByTrigger returns true if the job was triggered up to
+// TriggerDedupeWindow before the tick's latest occurrence. A startup
+// (@reboot) tick is for no occurrence, so it's never covered.
+func (s *ScheduledJob) coveredByTrigger(tk Tick) bool {
+	window := s.options.TriggerDedupeWindow
+	last := s.lastTrigger.Load()
+	if window <= 0 || tk.Triggered || tk.Reason == RunStartup || last == 0 {
+		return false
+	}
+	triggered := time.Unix(0, last)
+	return !triggered.After(tk.Last) && tk.Last.Sub(triggered) <= window
+}
+
+// watermarkKey returns the key the job's watermark is stored under
+func (s *ScheduledJob) watermarkKey() string {
+	if s.options.WatermarkKey != "" {
+		return s.options.WatermarkKey
+	}
+	return s.Schedule().String()
+}
+
+// queuedTick is a tick waiting for a worker
+type queuedTick struct {
+	// tick is the tick received from the ticker
+	tick Tick
+
+	// queued is when the tick was queued
+	queued time.Time
+}
+
+// shed records a tick being discarded without running the job
+func (s *ScheduledJob) shed(rt time.Time, reason string) {
+	s.Shed.Add(1)
+	jobLogger().Warn(
+		"shedding tick",
+		"reason", reason,
+		"tick", rt,
+		"scheduled_job", s,
+	)
+}
+
+// call calls the job function, returning a [PanicError] if it panics,
+// so the panic is handled as a failed run
+func (s *ScheduledJob) call(ctx context.Context, t time.Time) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return s.f(ctx, t)
+}
+
+// execute runs the job for the given tick
+func (s *ScheduledJob) execute(ctx context.Context, tk Tick) {
+	finished := false
+	defer func() {
+		s.settle(ctx, tk, finished)
+	}()
+	if tk.Triggered {
+		s.triggerPending.Store(false)
+	}
+	if b := s.options.Budget; b != nil && b.exhausted(tk.Time) {
+		s.OverBudget.Add(1)
+		jobLogger().Warn(
+			"budget used up, skipping tick",
+			"tick", tk.Time,
+			"budget_limit", b.Limit(),
+			"budget_window", b.Window(),
+			"scheduled_job", s,
+		)
+		return
+	}
+	if s.scheduler != nil {
+		release, ok := s.admit(ctx, tk)
+		if !ok {
+			if ctx.Err() != nil {
+				s.dropped.Add(1)
+			}
+			return
+		}
+		defer release()
+	}
+	if !s.claim(ctx, tk) {
+		return
+	}
+
 	s.Runs.Add(1)
 
 	s.Running.Add(1)
 	defer s.Running.Add(-1)
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	rt := tk.Time
+	r := newRun(rt)
+	runtime := &JobRuntime{
+		RunID:     r.id,
+		Scheduled: tk.Last,
+		Triggered: tk.Triggered,
+		Reason:    tk.Reason,
+		Start:     rt,
+	}
+	ctx = context.WithValue(ctx, runKey{}, r)
 
-	runtime := &JobRuntime{Start: rt}
+	jobLogger().Info(
+		"running scheduled job",
+		"run_id", r.id,
+		"triggered", tk.Triggered,
+		"reason", tk.Reason.String(),
+		"scheduled_job", s,
+	)
 
-	Logger.Info("running scheduled job", "scheduled_job", s)
+	s.mu.Lock()
+	if s.active == nil {
+		s.active = make(map[*run]struct{})
+	}
+	s.active[r] = struct{}{}
+	s.mu.Unlock()
 
-	runtime.Error = s.f(rt)
-	if runtime.Error == nil {
+	started := r.started
+	if threshold := s.options.StuckRunThreshold; threshold > 0 {
+		watchdog := time.AfterFunc(
+			threshold, func() {
+				s.stuck(r.id, time.Since(started))
+			},
+		)
+		defer watchdog.Stop()
+	}
+	var sla *slaCheck
+	if s.options.SLA > 0 && !tk.Triggered {
+		sla = s.watchSLA(r.id, tk.Last)
+	}
+	var charge func(end time.Time)
+	if b := s.options.Budget; b != nil {
+		charge = b.begin(rt)
+	}
+	runtime.Error = s.call(ctx, rt)
+	// a run that returns the job's own cancellation was
+	// interrupted by the job stopping, rather than finished
+	finished = ctx.Err() == nil || !errors.Is(runtime.Error, ctx.Err())
+	elapsed := time.Since(started)
+	s.durations.add(elapsed)
+	if charge != nil {
+		charge(rt.Add(elapsed))
+	}
+	runtime.Attrs = r.Attrs()
+	progress := r.active()
+	runtime.LastHeartbeat = progress.LastHeartbeat
+	runtime.Checkpoint = progress.Checkpoint
+	counted, failed := true, false
+	switch {
+	case runtime.Error == nil:
 		s.ConsecutiveFailures.Store(0)
-	} else {
+	case s.failureClass(runtime.Error) == FailureIgnored:
+		counted = false
+		jobLogger().Info(
+			"ignoring job error",
+			"error", runtime.Error,
+			"run_id", r.id,
+			"scheduled_job", s,
+		)
+	default:
+		failed = true
 		failures := s.Failures.Add(1)
 		consecutiveFailures := s.ConsecutiveFailures.Add(1)
 
 		if s.options.MaxFailures > 0 && failures >= int64(s.options.MaxFailures) {
-			Logger.Warn(
+			jobLogger().Warn(
 				"max failures reached, stopping job",
+				"run_id", r.id,
 				"scheduled_job", s,
 			)
-			select {
-			case s.stopCh <- struct{}{}:
-			default:
-			}
+			s.stop(StopMaxFailures)
 		} else if s.options.MaxConsecutiveFailures > 0 &&
 			consecutiveFailures >= int64(s.options.MaxConsecutiveFailures) {
-			Logger.Warn(
+			jobLogger().Warn(
 				"max consecutive failures reached, stopping job",
+				"run_id", r.id,
 				"scheduled_job", s,
 			)
-			select {
-			case s.stopCh <- struct{}{}:
-			default:
-			}
+			s.stop(StopMaxConsecutiveFailures)
 		}
 	}
 
-	runtime.End = time.Now()
-	Logger.Info(
+	runtime.End = clockNow(s.options.Clock)
+	if sla != nil {
+		runtime.SLAMissed = sla.finish(s, r.id, runtime.End)
+	}
+	if counted {
+		s.recordOutcome(r.id, runtime.End, failed)
+	}
+	if err := ctx.Err(); err != nil {
+		// the job stopped during the run
+		if runtime.Error != nil && errors.Is(runtime.Error, err) {
+			s.cancelled.Add(1)
+		} else {
+			s.drained.Add(1)
+		}
+	}
+	jobLogger().LogAttrs(
+		ctx,
+		slog.LevelInfo,
 		"job finished",
-		"start", runtime.Start,
-		"end", runtime.End,
-		"scheduled_job", s,
+		slog.String("run_id", r.id),
+		slog.Time("start", runtime.Start),
+		slog.Time("end", runtime.End),
+		slog.Any("scheduled_job", s),
+		slog.Attr{Key: "run", Value: slog.GroupValue(runtime.Attrs...)},
 	)
+	s.mu.Lock()
+	delete(s.active, r)
 	s.runtimes = append(s.runtimes, runtime)
+	s.mu.Unlock()
+	s.archive(ctx)
+}
+
+// admit checks the job's scheduler allows a run for the given tick,
+// and waits for a run slot if it limits concurrent runs. The
+// returned function releases the slot.
+func (s *ScheduledJob) admit(ctx context.Context, tk Tick) (func(), bool) {
+	switch {
+	case s.scheduler.Suspended():
+		jobLogger().Debug(
+			"scheduler suspended, skipping tick",
+			"scheduled_job", s,
+			"tick", tk.Time,
+		)
+		return nil, false
+	case s.scheduler.blackedOut(tk.Time):
+		s.BlackedOut.Add(1)
+		jobLogger().Info(
+			"scheduler blackout, skipping tick",
+			"scheduled_job", s,
+			"tick", tk.Time,
+		)
+		return nil, false
+	}
+	return s.scheduler.acquire(ctx)
+}
+
+// ActiveRuns returns the runs currently in progress, in no
+// particular order
+func (s *ScheduledJob) ActiveRuns() []ActiveRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	runs := make([]ActiveRun, 0, len(s.active))
+	for r := range s.active {
+		runs = append(runs, r.active())
+	}
+	return runs
+}
+
+// stuck flags a run that exceeded StuckRunThreshold
+func (s *ScheduledJob) stuck(runID string, elapsed time.Duration) {
+	s.StuckRuns.Add(1)
+	jobLogger().Warn(
+		"run exceeded stuck run threshold",
+		"run_id", runID,
+		"elapsed", elapsed,
+		"scheduled_job", s,
+	)
+	if f := s.options.OnStuckRun; f != nil {
+		f(runID, elapsed)
+	}
+}
+
+// failureClass classifies the given error returned by the job function
+func (s *ScheduledJob) failureClass(err error) FailureClass {
+	if s.options.ClassifyFailure == nil {
+		return FailureCounted
+	}
+	return s.options.ClassifyFailure(err)
 }
 
 // JobRuntime is a record of a job's runtime and any error
 type JobRuntime struct {
-	// Start is the time the job started
+	// RunID uniquely identifies the run. It's included in the run's
+	// log records, and is available to the job function (see [RunID]).
+	RunID string
+
+	// Scheduled is the scheduled occurrence the run is for (the
+	// latest one, if the tick represented several), or the zero
+	// time if the run was triggered
+	Scheduled time.Time
+
+	// Triggered is true if the run was started by
+	// [ScheduledJob.Trigger] rather than the schedule
+	Triggered bool
+
+	// Reason is why the run happened (ex: [RunCatchUp] for missed
+	// occurrences), from the tick it ran for
+	Reason RunReason
+
+	// Start is the time the job started, which is the time of the
+	// tick it ran for
 	Start time.Time
 
 	// End is the time the job ended
@@ -370,4 +1550,29 @@ type JobRuntime struct {
 
 	// Error is any error that occurred during the job
 	Error error
+
+	// Attrs are attributes attached to the run by the job
+	// function (see [AddRunAttrs])
+	Attrs []slog.Attr
+
+	// LastHeartbeat is the last time the run reported progress
+	// (see [Heartbeat]), or the zero time if it didn't
+	LastHeartbeat time.Time
+
+	// Checkpoint is the data from the run's most recent
+	// [Checkpoint], or nil if it didn't record one
+	Checkpoint []byte
+
+	// SLAMissed is true if the run didn't finish within
+	// [ScheduledJobOptions.SLA]
+	SLAMissed bool
+}
+
+// withoutContext adapts a job function that doesn't take a context
+func withoutContext(
+	f func(t time.Time) error,
+) func(ctx context.Context, t time.Time) error {
+	return func(_ context.Context, t time.Time) error {
+		return f(t)
+	}
 }
@@ -2,7 +2,11 @@ package crong
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"log/slog"
+	"runtime"
+	"slices"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -132,7 +136,7 @@ func TestScheduledContext(t *testing.T) {
 		select {
 		case <-ctx.Done():
 			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-				t.Fatalf("expected results")
+				t.Errorf("expected results")
 			}
 		case <-ranCh:
 			sj.Stop(sctx)
@@ -285,7 +289,7 @@ func TestJobMaxFailures(t *testing.T) {
 		select {
 		case <-ctx.Done():
 			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-				t.Fatalf("expected results")
+				t.Errorf("expected results")
 			}
 		}
 	}()
@@ -300,6 +304,7 @@ func TestJobMaxFailures(t *testing.T) {
 	assertEqual(t, sj.Failures.Load(), int64(3))
 
 	assertEqual(t, sj.State(), ScheduleStopped)
+	assertEqual(t, sj.StopReason(), StopMaxFailures)
 }
 
 func TestJobConsecutiveFailures(t *testing.T) {
@@ -335,7 +340,7 @@ func TestJobConsecutiveFailures(t *testing.T) {
 		select {
 		case <-ctx.Done():
 			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-				t.Fatalf("expected results")
+				t.Errorf("expected results")
 			}
 		}
 	}()
@@ -413,3 +418,931 @@ func TestJobConsecutiveFailures(t *testing.T) {
 	assertEqual(t, sj.Runs.Load(), int64(6))
 	assertEqual(t, sj.State(), ScheduleStopped)
 }
+
+func TestJobClassifyFailure(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			TickerReceiveTimeout:   5 * time.Second,
+			MaxConsecutiveFailures: 1,
+			ClassifyFailure: func(err error) FailureClass {
+				if errors.Is(err, context.Canceled) {
+					return FailureIgnored
+				}
+				return FailureCounted
+			},
+		},
+		func(dt time.Time) error {
+			return context.Canceled
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+	assertEqual(t, sj.Failures.Load(), int64(0))
+	assertEqual(t, sj.ConsecutiveFailures.Load(), int64(0))
+	assertEqual(t, sj.State(), ScheduleStarted)
+	for _, rt := range sj.Runtimes() {
+		if !errors.Is(rt.Error, context.Canceled) {
+			t.Errorf("expected the error to be recorded, got %v", rt.Error)
+		}
+	}
+}
+
+func TestJobMaxQueueDepth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	startedCh := make(chan struct{}, 10)
+	releaseCh := make(chan struct{})
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			MaxConcurrent:        1,
+			MaxQueueDepth:        1,
+			TickerReceiveTimeout: 5 * time.Second,
+		},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			<-releaseCh
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	// occupies the only worker
+	sj.ticker.tick(ctx)
+	<-startedCh
+
+	// waits in the queue
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.ticker.ticksSent.Load() == 2
+		},
+	)
+
+	// shed, as the queue is full
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.Shed.Load() == 1
+		},
+	)
+
+	close(releaseCh)
+	<-startedCh
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+	assertEqual(t, sj.Runs.Load(), int64(2))
+	assertEqual(t, sj.Shed.Load(), int64(1))
+}
+
+// TestJobSerialQueueDepth checks ticks for a busy serialized job
+// (MaxConcurrent 0) wait in its queue, and are shed from it, rather
+// than each starting a goroutine that waits for the previous run
+func TestJobSerialQueueDepth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	startedCh := make(chan struct{}, 10)
+	releaseCh := make(chan struct{})
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			MaxQueueDepth:        2,
+			TickerReceiveTimeout: 5 * time.Second,
+		},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			<-releaseCh
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	<-startedCh
+	goroutines := runtime.NumGoroutine()
+
+	for range 50 {
+		sj.ticker.tick(ctx)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.Shed.Load() == 48
+		},
+	)
+	if n := runtime.NumGoroutine(); n > goroutines+5 {
+		t.Errorf("expected about %d goroutines, got %d", goroutines, n)
+	}
+
+	close(releaseCh)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 3
+		},
+	)
+	assertEqual(t, sj.Runs.Load(), int64(3))
+}
+
+func TestJobMaxQueueAge(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	startedCh := make(chan struct{}, 10)
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			MaxConcurrent:        1,
+			MaxQueueDepth:        5,
+			MaxQueueAge:          500 * time.Millisecond,
+			TickerReceiveTimeout: 5 * time.Second,
+		},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			time.Sleep(time.Second)
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	<-startedCh
+
+	// waits longer than MaxQueueAge for the busy worker
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.Shed.Load() == 1
+		},
+	)
+	assertEqual(t, sj.Runs.Load(), int64(1))
+}
+
+func TestJobMaxQueueAgeWithoutQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	startedCh := make(chan struct{}, 10)
+	releaseCh := make(chan struct{})
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			MaxConcurrent:        1,
+			MaxQueueAge:          200 * time.Millisecond,
+			TickerReceiveTimeout: 5 * time.Second,
+		},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			<-releaseCh
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	<-startedCh
+
+	// shed while the only worker is still busy
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.Shed.Load() == 1
+		},
+	)
+	assertEqual(t, sj.Running.Load(), int64(1))
+
+	close(releaseCh)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	assertEqual(t, sj.Runs.Load(), int64(1))
+}
+
+func TestJobSetMaxConcurrent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	startedCh := make(chan struct{}, 10)
+	releaseCh := make(chan struct{})
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			MaxConcurrent:        1,
+			MaxQueueDepth:        5,
+			TickerReceiveTimeout: 5 * time.Second,
+		},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			<-releaseCh
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	if err = sj.SetMaxConcurrent(-1); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	sj.ticker.tick(ctx)
+	<-startedCh
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.ticker.ticksSent.Load() == 2
+		},
+	)
+	assertEqual(t, sj.Running.Load(), int64(1))
+
+	// the queued tick should be picked up by the new worker
+	if err = sj.SetMaxConcurrent(2); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-startedCh
+	assertEqual(t, sj.Running.Load(), int64(2))
+	assertEqual(t, sj.MaxConcurrent(), 2)
+
+	// shrinking lets the busy workers finish their runs
+	if err = sj.SetMaxConcurrent(1); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// waits in the queue until both busy runs finish
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.ticker.ticksSent.Load() == 3
+		},
+	)
+	releaseCh <- struct{}{}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	select {
+	case <-startedCh:
+		t.Fatalf("expected queued tick to wait for a worker")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(releaseCh)
+	<-startedCh
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 3
+		},
+	)
+	assertEqual(t, sj.Running.Load(), int64(0))
+}
+
+func TestJobSetMaxConcurrentZero(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	startedCh := make(chan struct{}, 10)
+	releaseCh := make(chan struct{})
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			MaxConcurrent:        1,
+			TickerReceiveTimeout: 5 * time.Second,
+		},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			<-releaseCh
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	<-startedCh
+
+	// without a queue, this tick is pending until a worker is free
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.ticker.ticksSent.Load() == 2
+		},
+	)
+
+	// removing the pool starts the pending tick
+	if err = sj.SetMaxConcurrent(0); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	releaseCh <- struct{}{}
+	<-startedCh
+	close(releaseCh)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+	assertEqual(t, sj.Shed.Load(), int64(0))
+}
+
+func TestJobSerialRuns(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var active atomic.Int64
+	var overlapped atomic.Bool
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{TickerReceiveTimeout: 5 * time.Second},
+		func(dt time.Time) error {
+			if active.Add(1) > 1 {
+				overlapped.Store(true)
+			}
+			defer active.Add(-1)
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	for range 3 {
+		sj.ticker.tick(ctx)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 3
+		},
+	)
+	if overlapped.Load() {
+		t.Errorf("expected runs not to overlap")
+	}
+}
+
+func TestJobMissed(t *testing.T) {
+	s, err := New("0 * * * *", nil) // hourly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sj := &ScheduledJob{schedule: s}
+	since := time.Date(2024, 2, 21, 8, 30, 0, 0, time.UTC)
+	now := time.Date(2024, 2, 21, 12, 15, 0, 0, time.UTC)
+
+	// no runs, so everything after since was missed
+	missed := sj.missed(since, now)
+	expected := []time.Time{
+		time.Date(2024, 2, 21, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 21, 11, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC),
+	}
+	if len(missed) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, missed)
+	}
+	for i, m := range missed {
+		assertEqual(t, m, expected[i])
+	}
+
+	// failed runs don't count
+	sj.runtimes = []*JobRuntime{
+		{
+			Start: time.Date(2024, 2, 21, 9, 0, 1, 0, time.UTC),
+		},
+		{
+			Start: time.Date(2024, 2, 21, 10, 0, 1, 0, time.UTC),
+			Error: errors.New("job failed"),
+		},
+	}
+	missed = sj.missed(since, now)
+	if len(missed) != 3 {
+		t.Fatalf("expected %v, got %v", expected[1:], missed)
+	}
+	assertEqual(t, missed[0], expected[1])
+
+	// a successful run before since is ignored
+	missed = sj.missed(time.Date(2024, 2, 21, 11, 30, 0, 0, time.UTC), now)
+	if len(missed) != 1 {
+		t.Fatalf("expected %v, got %v", expected[3:], missed)
+	}
+	assertEqual(t, missed[0], expected[3])
+
+	// a run that fired early, within the tolerance, counts
+	// for the following occurrence
+	sj.options.Tolerance = time.Second
+	sj.runtimes = []*JobRuntime{
+		{
+			Start: time.Date(2024, 2, 21, 10, 59, 59, 500_000_000, time.UTC),
+		},
+	}
+	missed = sj.missed(since, now)
+	if len(missed) != 1 {
+		t.Fatalf("expected %v, got %v", expected[3:], missed)
+	}
+	assertEqual(t, missed[0], expected[3])
+
+	// a one-shot schedule that's already fired has nothing to backfill
+	at, err := New(At+" 2024-02-21T09:00:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sj = &ScheduledJob{schedule: at}
+	assertEqual(t, len(sj.missed(since, now)), 1)
+	sj.runtimes = []*JobRuntime{{Start: at.At()}}
+	assertEqual(t, len(sj.missed(since, now)), 0)
+}
+
+func TestJobExhausted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	at := time.Now().Add(time.Second)
+	s, err := New(At+" "+at.Format(time.RFC3339Nano), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{TickerReceiveTimeout: 5 * time.Second},
+		func(dt time.Time) error {
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.State() == ScheduleExhausted
+		},
+	)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	if sj.Resume() {
+		t.Errorf("expected an exhausted job not to resume")
+	}
+	assertEqual(t, sj.Stop(context.Background()), true)
+	assertEqual(t, sj.State(), ScheduleStopped)
+}
+
+func TestScheduleStateJSON(t *testing.T) {
+	for _, state := range []ScheduleState{
+		ScheduleStarted,
+		ScheduleSuspended,
+		ScheduleStopped,
+		ScheduleExhausted,
+	} {
+		t.Run(
+			state.String(), func(t *testing.T) {
+				data, err := json.Marshal(state)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, string(data), `"`+state.String()+`"`)
+
+				var decoded ScheduleState
+				if err = json.Unmarshal(data, &decoded); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, decoded, state)
+			},
+		)
+	}
+	assertEqual(t, ScheduleState(0).String(), "unknown")
+
+	var state ScheduleState
+	if err := json.Unmarshal([]byte(`"paused"`), &state); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestJobLogValue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{Name: "report", TickerReceiveTimeout: 5 * time.Second},
+		func(dt time.Time) error {
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	for _, v := range []slog.Value{sj.LogValue(), sj.ticker.LogValue()} {
+		attrs := map[string]slog.Value{}
+		for _, attr := range v.Group() {
+			attrs[attr.Key] = attr.Value
+		}
+		assertEqual(t, attrs["name"].String(), "report")
+		assertEqual(t, attrs["schedule"].String(), "* * * * *")
+		if next := attrs["next"]; next.Kind() != slog.KindTime || !next.Time().After(time.Now()) {
+			t.Errorf("expected the next scheduled time, got %v", next)
+		}
+		if _, ok := attrs["state"]; !ok {
+			t.Errorf("expected a state")
+		}
+	}
+	assertEqual(t, sj.ticker.state(), "running")
+}
+
+func TestJobStuckRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	stuckCh := make(chan string, 1)
+	releaseCh := make(chan struct{})
+	sj := ScheduleFuncContext(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			TickerReceiveTimeout: 5 * time.Second,
+			StuckRunThreshold:    50 * time.Millisecond,
+			OnStuckRun: func(runID string, elapsed time.Duration) {
+				if elapsed < 50*time.Millisecond {
+					t.Errorf("unexpected elapsed time: %s", elapsed)
+				}
+				stuckCh <- runID
+			},
+		},
+		func(ctx context.Context, dt time.Time) error {
+			<-releaseCh
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	var runID string
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected the run to be flagged")
+	case runID = <-stuckCh:
+	}
+	assertEqual(t, sj.StuckRuns.Load(), int64(1))
+
+	close(releaseCh)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	assertEqual(t, sj.Runtimes()[0].RunID, runID)
+}
+
+func TestJobClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	now := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			TickerReceiveTimeout: 5 * time.Second,
+			Clock: ClockFunc(
+				func() time.Time {
+					return now
+				},
+			),
+		},
+		func(dt time.Time) error {
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	scheduled := now.Add(-time.Minute)
+	sj.ticker.inject(ctx, scheduled)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+	// serialized runs may finish in either order
+	rt := sj.Runtimes()
+	if rt[0].Scheduled.Equal(scheduled) {
+		rt[0], rt[1] = rt[1], rt[0]
+	}
+	assertEqual(t, rt[0].Start, now)
+	assertEqual(t, rt[0].End, now)
+	assertEqual(t, rt[0].Scheduled, now)
+	assertEqual(t, rt[1].Scheduled, scheduled)
+	assertEqual(t, rt[1].End, now)
+}
+
+func TestJobSetLocation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 9 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sj := ScheduleFunc(
+		ctx, s, ScheduledJobOptions{}, func(dt time.Time) error {
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	plus2 := time.FixedZone("plus2", 2*60*60)
+	sj.SetLocation(plus2)
+	assertEqual(t, sj.Schedule().Location(), plus2)
+	assertEqual(t, sj.ticker.Schedule().Location(), plus2)
+	assertEqual(t, s.Location(), time.UTC)
+}
+
+func TestJobAlignStart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sj := NewScheduledJob(
+		s,
+		ScheduledJobOptions{AlignStart: true},
+		func(dt time.Time) error {
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	stale := time.Now().Truncate(time.Minute)
+	go func() {
+		_ = sj.Start(ctx)
+	}()
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.State() == ScheduleStarted
+		},
+	)
+
+	// the occurrence came due before the job started, so it's skipped,
+	// while the next occurrence and triggered runs aren't
+	sj.ticker.inject(ctx, stale)
+	next := s.Next(time.Now())
+	sj.ticker.inject(ctx, next)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	if err = sj.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+	rt := sj.Runtimes()
+	assertEqual(t, rt[0].Scheduled, next)
+	assertEqual(t, rt[1].Triggered, true)
+	assertEqual(t, sj.Runs.Load(), 2)
+}
+
+func TestJobStartDelay(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	now := time.Now()
+	type delayCase struct {
+		Name string
+		Opts ScheduledJobOptions
+	}
+	cases := []delayCase{
+		{Name: "start after", Opts: ScheduledJobOptions{StartAfter: now.Add(30 * time.Minute)}},
+		{Name: "warmup delay", Opts: ScheduledJobOptions{WarmupDelay: 30 * time.Minute}},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Name, func(t *testing.T) {
+				sj := ScheduleFunc(
+					ctx, s, tc.Opts, func(dt time.Time) error {
+						return nil
+					},
+				)
+				defer sj.Stop(context.Background())
+
+				// occurrences in the first 30 minutes are skipped
+				sj.ticker.inject(ctx, s.Next(now))
+				ready := s.Next(now.Add(time.Hour))
+				sj.ticker.inject(ctx, ready)
+				waitFor(
+					t, 5*time.Second, func() bool {
+						return len(sj.Runtimes()) == 1
+					},
+				)
+				assertEqual(t, sj.Runtimes()[0].Scheduled, ready)
+
+				if err = sj.Trigger(); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				waitFor(
+					t, 5*time.Second, func() bool {
+						return len(sj.Runtimes()) == 2
+					},
+				)
+			},
+		)
+	}
+}
+
+func TestJobNotAfter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(dt time.Time) error { return nil }
+
+	// occurrences after NotAfter are skipped
+	notAfter := s.Next(time.Now()).Add(time.Minute)
+	sj := ScheduleFunc(ctx, s, ScheduledJobOptions{NotAfter: notAfter}, f)
+	sj.ticker.inject(ctx, notAfter.Add(time.Minute))
+	sj.ticker.inject(ctx, notAfter)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	assertEqual(t, sj.Runtimes()[0].Scheduled, notAfter)
+	assertEqual(t, sj.StopReason(), StopReasonNone)
+	sj.Stop(context.Background())
+	assertEqual(t, sj.StopReason(), StopRequested)
+
+	// the job stops once NotAfter passes
+	sj = ScheduleFunc(
+		ctx, s, ScheduledJobOptions{NotAfter: time.Now().Add(100 * time.Millisecond)}, f,
+	)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.State() == ScheduleStopped
+		},
+	)
+	assertEqual(t, sj.StopReason(), StopNotAfter)
+	sj.Stop(context.Background())
+	assertEqual(t, sj.StopReason(), StopNotAfter)
+
+	// or right away, if it already passed
+	sj = ScheduleFunc(ctx, s, ScheduledJobOptions{NotAfter: time.Now().Add(-time.Hour)}, f)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.State() == ScheduleStopped
+		},
+	)
+	assertEqual(t, sj.StopReason(), StopNotAfter)
+
+	// canceling the job's context is recorded too
+	jobCtx, jobCancel := context.WithCancel(ctx)
+	sj = ScheduleFunc(jobCtx, s, ScheduledJobOptions{}, f)
+	jobCancel()
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.State() == ScheduleStopped
+		},
+	)
+	assertEqual(t, sj.StopReason(), StopContextDone)
+}
+
+func TestJobReboot(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s, err := New(Reboot, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(dt time.Time) error { return nil }
+
+	// runs once, when scheduled
+	sj := ScheduleFunc(ctx, s, ScheduledJobOptions{}, f)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.State() == ScheduleExhausted
+		},
+	)
+	time.Sleep(100 * time.Millisecond)
+	assertEqual(t, len(sj.Runtimes()), 1)
+	assertEqual(t, sj.Runtimes()[0].Reason, RunStartup)
+	sj.Stop(context.Background())
+
+	// or when started, rather than when created
+	sj = NewScheduledJob(s, ScheduledJobOptions{}, f)
+	time.Sleep(50 * time.Millisecond)
+	started := time.Now()
+	go func() {
+		_ = sj.Start(ctx)
+	}()
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	assertEqual(t, sj.Runtimes()[0].Scheduled.Before(started), false)
+	sj.Stop(context.Background())
+
+	// a trigger right away runs as well, rather than being held up
+	// by the startup run, or covering it
+	sj = ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{TriggerDedupeWindow: time.Minute},
+		f,
+	)
+	if err = sj.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+	assertEqual(t, sj.Duplicates.Load(), 0)
+	reasons := []RunReason{sj.Runtimes()[0].Reason, sj.Runtimes()[1].Reason}
+	slices.Sort(reasons)
+	assertEqual(t, reasons[0], RunTriggered)
+	assertEqual(t, reasons[1], RunStartup)
+	sj.Stop(context.Background())
+}
@@ -0,0 +1,183 @@
+package crong
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// LintPolicy sets how [New] handles expressions that are valid,
+// but likely to be a mistake (ex: duplicate list entries)
+type LintPolicy int
+
+const (
+	// LintIgnore accepts the expression silently
+	LintIgnore LintPolicy = iota
+	// LintWarn accepts the expression, and reports the
+	// problem in [Schedule.Warnings]
+	LintWarn
+	// LintError rejects the expression with a [ValidationError]
+	LintError
+	// LintNormalize rewrites the expression to avoid the problem,
+	// for checks that support it (ex: WithFullRangeCheck). Other
+	// checks treat it as LintIgnore.
+	LintNormalize
+)
+
+func (p LintPolicy) String() string {
+	switch p {
+	case LintIgnore:
+		return "ignore"
+	case LintWarn:
+		return "warn"
+	case LintError:
+		return "error"
+	case LintNormalize:
+		return "normalize"
+	default:
+		return fmt.Sprintf("LintPolicy(%d)", int(p))
+	}
+}
+
+// Warnings returns the problems found in the expression by checks
+// set to LintWarn (ex: WithDuplicateCheck(LintWarn)). The schedule
+// is still usable.
+func (s *Schedule) Warnings() []*FieldError {
+	return s.warnings
+}
+
+// lint runs the checks enabled by the schedule's parse options,
+// recording problems as warnings or validation errors
+func (s *Schedule) lint(verr *ValidationError) {
+	if s.options.duplicates == LintIgnore && s.options.fullRange == LintIgnore {
+		return
+	}
+	for _, fv := range s.fieldValues() {
+		if s.options.duplicates != LintIgnore {
+			for _, problem := range fv.field.duplicates(fv.value) {
+				s.report(verr, s.options.duplicates, fv.field, fv.value, problem)
+			}
+		}
+		if s.options.fullRange != LintIgnore && fv.field.isFullRange(fv.value) {
+			s.report(
+				verr,
+				s.options.fullRange,
+				fv.field,
+				fv.value,
+				fmt.Sprintf("'%s' includes every value, use '%c'", fv.value, Any),
+			)
+		}
+	}
+}
+
+// normalize rewrites the schedule's fields as enabled by its
+// parse options, before they're parsed
+func (s *Schedule) normalize() {
+	if s.options.fullRange != LintNormalize {
+		return
+	}
+	if s.options.seconds && secondOpts.isFullRange(s.second) {
+		s.second = string(Any)
+	}
+	for _, fv := range s.fieldValues() {
+		if fv.field.Index != secondInd && fv.field.isFullRange(fv.value) {
+			s.values[fv.field.Index] = string(Any)
+		}
+	}
+}
+
+// report records a problem found by a check with the given policy
+func (s *Schedule) report(
+	verr *ValidationError,
+	policy LintPolicy,
+	f field,
+	value string,
+	problem string,
+) {
+	switch policy {
+	case LintWarn:
+		s.warnings = append(
+			s.warnings,
+			&FieldError{Field: f.Name, Value: value, Err: f.error(problem)},
+		)
+	case LintError:
+		verr.add(f, value, f.error(problem))
+	}
+}
+
+// fieldValue pairs a field with its value in an expression
+type fieldValue struct {
+	field field
+	value string
+}
+
+// fieldValues returns each field of the schedule with its
+// value, in the order they appear in the expression
+func (s *Schedule) fieldValues() []fieldValue {
+	values := make([]fieldValue, 0, 6)
+	if s.options.seconds {
+		values = append(values, fieldValue{secondOpts, s.Second()})
+	}
+	return append(
+		values,
+		fieldValue{minuteOpts, s.Minute()},
+		fieldValue{hourOpts, s.Hour()},
+		fieldValue{dayOpts, s.Day()},
+		fieldValue{monthOpts, s.Month()},
+		fieldValue{s.weekdayField(), s.Weekday()},
+	)
+}
+
+// duplicates returns a description of each list entry in the given
+// value that repeats values from an earlier entry. Entries that
+// can't be parsed are left to validation.
+func (f field) duplicates(s string) []string {
+	entries := strings.Split(s, string(ListSeparator))
+	if len(entries) < 2 {
+		return nil
+	}
+
+	var problems []string
+	// the entry each value was first seen in
+	seen := map[int]string{}
+	for _, entry := range entries {
+		values, err := f.parse(entry)
+		if err != nil {
+			return nil
+		}
+		values = f.canonical(values)
+		overlapping := ""
+		for _, v := range values {
+			if prev, ok := seen[v]; ok {
+				overlapping = prev
+				break
+			}
+		}
+		switch {
+		case overlapping == "":
+		case strings.EqualFold(overlapping, entry):
+			problems = append(problems, fmt.Sprintf("duplicate entry '%s'", entry))
+		default:
+			problems = append(
+				problems,
+				fmt.Sprintf("'%s' overlaps '%s'", entry, overlapping),
+			)
+		}
+		for _, v := range values {
+			if _, ok := seen[v]; !ok {
+				seen[v] = entry
+			}
+		}
+	}
+	return problems
+}
+
+// isFullRange returns true if the given value includes every
+// allowed value for the field, without being a wildcard
+func (f field) isFullRange(s string) bool {
+	if s == string(Any) || s == string(Blank) {
+		return false
+	}
+	values, err := f.parse(s)
+	return err == nil && slices.Equal(f.canonical(values), f.canonical(f.Allowed))
+}
@@ -0,0 +1,93 @@
+package crong
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDuplicateCheck(t *testing.T) {
+	expr := "1,1,1-3 9,10 * * MON,1"
+
+	// ignored by default
+	s, err := New(expr, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, len(s.Warnings()), 0)
+
+	s, err = New(expr, nil, WithDuplicateCheck(LintWarn))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	warnings := s.Warnings()
+	assertEqual(t, len(warnings), 3)
+	assertEqual(t, warnings[0].Field, "minute")
+	assertEqual(t, warnings[0].Error(), "invalid minute entry: duplicate entry '1'")
+	assertEqual(t, warnings[1].Error(), "invalid minute entry: '1-3' overlaps '1'")
+	assertEqual(t, warnings[2].Field, "weekday")
+	assertEqual(t, warnings[2].Error(), "invalid weekday entry: '1' overlaps 'MON'")
+
+	_, err = New(expr, nil, WithDuplicateCheck(LintError))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	assertEqual(t, len(verr.Fields), 3)
+
+	s, err = New("1,2,3-5 * * * *", nil, WithDuplicateCheck(LintError))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, len(s.Warnings()), 0)
+}
+
+func TestFullRangeCheck(t *testing.T) {
+	expr := "0-59 */1 1-31 JAN-DEC SUN-SAT"
+
+	s, err := New(expr, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), expr)
+	assertEqual(t, len(s.Warnings()), 0)
+
+	s, err = New(expr, nil, WithFullRangeCheck(LintNormalize))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "* * * * *")
+	assertEqual(t, s.allowAnyMinute, true)
+	assertEqual(t, len(s.Warnings()), 0)
+
+	s, err = New(expr, nil, WithFullRangeCheck(LintWarn))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), expr)
+	assertEqual(t, len(s.Warnings()), 5)
+	assertEqual(
+		t,
+		s.Warnings()[0].Error(),
+		"invalid minute entry: '0-59' includes every value, use '*'",
+	)
+
+	_, err = New(expr, nil, WithFullRangeCheck(LintError))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	assertEqual(t, len(verr.Fields), 5)
+
+	s, err = New("0-59/1 0 12 * * ?", nil, WithSeconds(), WithFullRangeCheck(LintNormalize))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "* 0 12 * * ?")
+
+	// partial ranges are left alone
+	s, err = New("0-58 * 1-30 * MON-SAT", nil, WithFullRangeCheck(LintNormalize))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "0-58 * 1-30 * MON-SAT")
+}
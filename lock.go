@@ -0,0 +1,147 @@
+package crong
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultLockTTL is how long a job holds the lock for an occurrence,
+// if [ScheduledJobOptions.LockTTL] isn't set
+const defaultLockTTL = time.Minute
+
+// Locker provides locks shared by replicas of a job (ex: backed by a
+// database or Redis), so only one replica runs each occurrence (see
+// [ScheduledJobOptions.Locker]). Implementations must be safe for
+// concurrent use.
+type Locker interface {
+	// TryLock acquires the lock for key on behalf of owner, for up
+	// to ttl, without waiting. It returns false if the lock is held
+	// by another owner, and hasn't expired.
+	TryLock(ctx context.Context, key string, owner string, ttl time.Duration) (bool, error)
+
+	// Unlock releases the lock for key, if it's held by owner
+	Unlock(ctx context.Context, key string, owner string) error
+}
+
+// MemoryLocker is a [Locker] that keeps locks in memory. Locks are
+// shared by jobs using the same locker, in the same process, which
+// is mostly useful for tests.
+type MemoryLocker struct {
+	locks map[string]heldLock
+	mu    sync.Mutex
+}
+
+// heldLock is a lock held in a MemoryLocker
+type heldLock struct {
+	owner   string
+	expires time.Time
+}
+
+// NewMemoryLocker returns a [MemoryLocker] with no locks held
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: map[string]heldLock{}}
+}
+
+// TryLock acquires the lock for key, unless another owner holds it
+// and it hasn't expired
+func (m *MemoryLocker) TryLock(
+	_ context.Context,
+	key string,
+	owner string,
+	ttl time.Duration,
+) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if held, ok := m.locks[key]; ok && held.owner != owner && now.Before(held.expires) {
+		return false, nil
+	}
+	m.locks[key] = heldLock{owner: owner, expires: now.Add(ttl)}
+	return true, nil
+}
+
+// Unlock releases the lock for key, if it's held by owner
+func (m *MemoryLocker) Unlock(_ context.Context, key string, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if held, ok := m.locks[key]; ok && held.owner == owner {
+		delete(m.locks, key)
+	}
+	return nil
+}
+
+// claim returns true if this replica of the job should run the tick's
+// occurrence. The job's watermark key is locked while the watermark
+// is advanced, so claims for different occurrences exclude each other
+// too, and only one replica sees each occurrence as new. The
+// watermark only moves forward, so a replica whose clock lags can't
+// move it back and rerun a later occurrence. If the Locker or the
+// watermark store returns an error, the tick is skipped, so the
+// occurrence is missed rather than run twice. Triggered ticks aren't
+// for an occurrence, and always run.
+func (s *ScheduledJob) claim(ctx context.Context, tk Tick) bool {
+	locker, store := s.options.Locker, s.options.Watermarks
+	if locker == nil || store == nil || tk.Triggered {
+		return true
+	}
+	key := s.watermarkKey()
+	occurrence := tk.Last.Truncate(s.Schedule().resolution())
+	owner := randomID()
+	ttl := s.options.LockTTL
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+
+	ok, err := locker.TryLock(ctx, key, owner, ttl)
+	switch {
+	case err != nil:
+		jobLogger().Error(
+			"failed to lock watermark, skipping tick",
+			"error", err,
+			"key", key,
+			"scheduled_job", s,
+		)
+		return false
+	case !ok:
+		s.Duplicates.Add(1)
+		jobLogger().Info(
+			"watermark locked by another replica, skipping tick",
+			"key", key,
+			"tick", tk.Time,
+			"scheduled_job", s,
+		)
+		return false
+	}
+	defer func() {
+		if err := locker.Unlock(context.WithoutCancel(ctx), key, owner); err != nil {
+			jobLogger().Error(
+				"failed to unlock watermark",
+				"error", err,
+				"key", key,
+				"scheduled_job", s,
+			)
+		}
+	}()
+
+	advanced, err := advanceWatermark(ctx, store, key, occurrence)
+	switch {
+	case err != nil:
+		jobLogger().Error(
+			"failed to advance watermark, skipping tick",
+			"error", err,
+			"key", key,
+			"scheduled_job", s,
+		)
+		return false
+	case !advanced:
+		s.Duplicates.Add(1)
+		jobLogger().Info(
+			"another replica already ran for occurrence, skipping tick",
+			"tick", tk.Time,
+			"scheduled_job", s,
+		)
+		return false
+	}
+	return true
+}
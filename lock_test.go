@@ -0,0 +1,252 @@
+package crong
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryLocker(t *testing.T) {
+	ctx := context.Background()
+	locker := NewMemoryLocker()
+
+	ok, err := locker.TryLock(ctx, "foo", "a", time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, ok, true)
+
+	ok, _ = locker.TryLock(ctx, "foo", "b", time.Hour)
+	assertEqual(t, ok, false)
+
+	// only the owner can unlock
+	_ = locker.Unlock(ctx, "foo", "b")
+	ok, _ = locker.TryLock(ctx, "foo", "b", time.Hour)
+	assertEqual(t, ok, false)
+
+	_ = locker.Unlock(ctx, "foo", "a")
+	ok, _ = locker.TryLock(ctx, "foo", "b", time.Millisecond)
+	assertEqual(t, ok, true)
+
+	// expired locks can be taken by another owner
+	time.Sleep(5 * time.Millisecond)
+	ok, _ = locker.TryLock(ctx, "foo", "c", time.Hour)
+	assertEqual(t, ok, true)
+}
+
+func TestJobLockerReplicas(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	opts := ScheduledJobOptions{
+		TickerReceiveTimeout: 5 * time.Second,
+		Locker:               NewMemoryLocker(),
+		Watermarks:           NewMemoryWatermarkStore(),
+		WatermarkKey:         "job",
+	}
+
+	var mu sync.Mutex
+	var ran []time.Time
+	replicas := make([]*ScheduledJob, 3)
+	for i := range replicas {
+		replicas[i] = ScheduleFunc(
+			ctx, s, opts, func(dt time.Time) error {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, dt)
+				return nil
+			},
+		)
+		defer replicas[i].Stop(context.Background())
+	}
+
+	at := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for _, sj := range replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sj.ticker.inject(ctx, at)
+		}()
+	}
+	wg.Wait()
+
+	duplicates := func() int64 {
+		var n int64
+		for _, sj := range replicas {
+			n += sj.Duplicates.Load()
+		}
+		return n
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return duplicates() == 2
+		},
+	)
+	var runs int64
+	for _, sj := range replicas {
+		runs += sj.Runs.Load()
+	}
+	assertEqual(t, runs, int64(1))
+
+	// a lagging replica ticking for an earlier occurrence is
+	// behind the watermark
+	replicas[0].ticker.inject(ctx, at.Add(-time.Minute))
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return duplicates() == 3
+		},
+	)
+
+	// the next occurrence runs once
+	for _, sj := range replicas {
+		sj.ticker.inject(ctx, at.Add(time.Minute))
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return duplicates() == 5
+		},
+	)
+	mu.Lock()
+	defer mu.Unlock()
+	assertEqual(t, len(ran), 2)
+}
+
+// failingLocker is a Locker that always returns an error
+type failingLocker struct{}
+
+func (failingLocker) TryLock(context.Context, string, string, time.Duration) (bool, error) {
+	return false, errors.New("unavailable")
+}
+
+func (failingLocker) Unlock(context.Context, string, string) error {
+	return errors.New("unavailable")
+}
+
+func TestJobLockerError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	store := NewMemoryWatermarkStore()
+	doneCh := make(chan time.Time, 10)
+	sj := ScheduleFunc(
+		ctx, s, ScheduledJobOptions{
+			TickerReceiveTimeout: 5 * time.Second,
+			Locker:               failingLocker{},
+			Watermarks:           store,
+			WatermarkKey:         "job",
+		}, func(dt time.Time) error {
+			doneCh <- dt
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	// the tick is skipped rather than run without the lock
+	sj.ticker.inject(ctx, time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC))
+	if err = sj.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-doneCh
+	assertEqual(t, sj.Runs.Load(), int64(1))
+
+	wm, err := store.Watermark(ctx, "job")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, wm.IsZero(), true)
+}
+
+// pausingWatermarkStore is a WatermarkStore that can only be read and
+// set (not advanced), whose next read waits for resume once pause is
+// set, so tests can interleave replicas' claims
+type pausingWatermarkStore struct {
+	store  *MemoryWatermarkStore
+	pause  atomic.Bool
+	paused chan struct{}
+	resume chan struct{}
+}
+
+func (p *pausingWatermarkStore) Watermark(ctx context.Context, key string) (time.Time, error) {
+	wm, err := p.store.Watermark(ctx, key)
+	if p.pause.CompareAndSwap(true, false) {
+		close(p.paused)
+		<-p.resume
+	}
+	return wm, err
+}
+
+func (p *pausingWatermarkStore) SetWatermark(ctx context.Context, key string, t time.Time) error {
+	return p.store.SetWatermark(ctx, key, t)
+}
+
+func TestJobLockerOutOfOrder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	store := &pausingWatermarkStore{
+		store:  NewMemoryWatermarkStore(),
+		paused: make(chan struct{}),
+		resume: make(chan struct{}),
+	}
+	opts := ScheduledJobOptions{
+		Locker:       NewMemoryLocker(),
+		Watermarks:   store,
+		WatermarkKey: "job",
+	}
+	f := func(time.Time) error { return nil }
+	lagging := NewScheduledJob(s, opts, f)
+	current := NewScheduledJob(s, opts, f)
+
+	at := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	next := at.Add(time.Minute)
+	claims := map[time.Time]int{}
+	claim := func(sj *ScheduledJob, occurrence time.Time) bool {
+		ok := sj.claim(ctx, Tick{Time: occurrence, Last: occurrence})
+		if ok {
+			claims[occurrence]++
+		}
+		return ok
+	}
+
+	// the lagging replica claims 10:00, and is paused after reading
+	// the watermark, while the current replica claims 10:01
+	store.pause.Store(true)
+	laggingClaimed := make(chan bool, 1)
+	go func() {
+		laggingClaimed <- lagging.claim(ctx, Tick{Time: at, Last: at})
+	}()
+	<-store.paused
+	assertEqual(t, claim(current, next), false)
+	close(store.resume)
+	if <-laggingClaimed {
+		claims[at]++
+	}
+
+	// each replica then ticks for the other occurrence
+	claim(current, at)
+	claim(lagging, next)
+
+	assertEqual(t, claims[at], 1)
+	assertEqual(t, claims[next], 1)
+	wm, err := store.Watermark(ctx, "job")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, wm, next)
+}
@@ -0,0 +1,100 @@
+package crong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseMode sets which variations on standard cron syntax [New]
+// accepts (see [WithParseMode])
+type ParseMode int
+
+const (
+	// ParseDefault accepts the syntax described in the package
+	// documentation, including the non-standard step shorthand
+	// (ex: "5/10"). Fields must be separated by single spaces.
+	ParseDefault ParseMode = iota
+	// ParseStrict rejects non-standard constructs that other cron
+	// implementations interpret differently, or reject: the step
+	// shorthand, where a step follows a single value (ex: "5/10",
+	// rather than "5-59/10"), and '?' in the month field
+	ParseStrict
+	// ParseLenient accepts common variations in formatting: fields
+	// separated by any run of spaces or tabs, and a trailing comment,
+	// starting with a '#' after whitespace (ex: "0 0 * * *  # daily").
+	// String returns the expression with single spaces, and without
+	// the comment.
+	ParseLenient
+)
+
+func (m ParseMode) String() string {
+	switch m {
+	case ParseDefault:
+		return "default"
+	case ParseStrict:
+		return "strict"
+	case ParseLenient:
+		return "lenient"
+	default:
+		return fmt.Sprintf("ParseMode(%d)", int(m))
+	}
+}
+
+// WithParseMode sets which variations on standard cron syntax are
+// accepted. By default (ParseDefault), the step shorthand is accepted,
+// but fields must be separated by single spaces.
+func WithParseMode(mode ParseMode) ParseOption {
+	return func(o *parseOptions) {
+		o.mode = mode
+	}
+}
+
+// lenientExpr rewrites an expression parsed with ParseLenient with
+// its fields separated by single spaces, dropping any trailing comment
+func lenientExpr(cron string) string {
+	fields := strings.Fields(cron)
+	for i, f := range fields {
+		if strings.HasPrefix(f, "#") {
+			fields = fields[:i]
+			break
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// validateStrict checks the schedule for the non-standard
+// constructs rejected by ParseStrict
+func (s *Schedule) validateStrict(verr *ValidationError) {
+	if s.Month() == string(Blank) {
+		verr.add(
+			monthOpts,
+			s.Month(),
+			monthOpts.error(
+				fmt.Sprintf("'%c' in the month field is non-standard", Blank),
+			),
+		)
+	}
+	for _, fv := range s.fieldValues() {
+		for _, entry := range strings.Split(fv.value, string(ListSeparator)) {
+			start, _, found := strings.Cut(entry, string(Step))
+			if !found || start == string(Any) || strings.ContainsRune(start, Range) {
+				continue
+			}
+			verr.add(
+				fv.field,
+				fv.value,
+				fv.field.error(
+					fmt.Sprintf(
+						"step shorthand '%s' is non-standard (use '%s%c%d%s')",
+						entry,
+						start,
+						Range,
+						fv.field.Max(),
+						entry[len(start):],
+					),
+				),
+			)
+			break
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package crong
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// ErrNotScheduled is returned by [Schedule.IndexOf] for a time the
+// schedule doesn't fire at, or that's before the epoch
+var ErrNotScheduled = errors.New("not a scheduled time")
+
+// IndexOf returns the sequence number of the scheduled time t, counting
+// from the first scheduled time at or after epoch, which is #0. As long
+// as every process uses the same epoch (ex: a fixed date, or when the
+// job was created), the number is the same across restarts and
+// replicas, so it can be used as an idempotency key (ex: "job X,
+// occurrence #4821"). Days without a daylight saving time transition
+// are counted whole, so this doesn't step through every occurrence.
+// If t isn't a scheduled time at or after epoch, [ErrNotScheduled]
+// is returned.
+func (s *Schedule) IndexOf(t time.Time, epoch time.Time) (int64, error) {
+	if t.Before(epoch) || !s.Ceil(t).Equal(t) {
+		return 0, fmt.Errorf(
+			"%w: %s (epoch %s)",
+			ErrNotScheduled,
+			t.Format(time.RFC3339Nano),
+			epoch.Format(time.RFC3339Nano),
+		)
+	}
+
+	if s.every > 0 {
+		return (t.Unix() - s.Ceil(epoch).Unix()) / s.everySeconds(), nil
+	}
+
+	var n int64
+	perDay := s.perDay()
+	for next := s.Ceil(epoch); next.Before(t); {
+		if end, ok := s.wholeDay(next, epoch); ok && !end.After(t) {
+			n += perDay
+			next = s.Ceil(end)
+			continue
+		}
+		n++
+		next = s.Next(next)
+	}
+	return n, nil
+}
+
+// AtIndex returns the scheduled time with sequence number n, counting
+// from the first scheduled time at or after epoch, which is #0. It's
+// the inverse of IndexOf. If the schedule has fewer than n+1 scheduled
+// times after epoch, [ErrScheduleExhausted] is returned for a one-shot
+// (@at) or startup (@reboot) schedule, and [ErrUnreachableSchedule]
+// otherwise.
+func (s *Schedule) AtIndex(n int64, epoch time.Time) (time.Time, error) {
+	if n < 0 {
+		return time.Time{}, fmt.Errorf("invalid occurrence index %d", n)
+	}
+	if s.every > 0 {
+		return s.everyAtIndex(n, epoch)
+	}
+
+	perDay := s.perDay()
+	next := s.Ceil(epoch)
+	for !next.IsZero() {
+		if end, ok := s.wholeDay(next, epoch); ok && n >= perDay {
+			n -= perDay
+			next = s.Ceil(end)
+			continue
+		}
+		if n == 0 {
+			return next, nil
+		}
+		n--
+		next = s.Next(next)
+	}
+	if !s.at.IsZero() || s.reboot {
+		return time.Time{}, ErrScheduleExhausted
+	}
+	return time.Time{}, ErrUnreachableSchedule
+}
+
+// wholeDay returns the end of the day of the scheduled time next,
+// and whether all the day's scheduled times can be counted at
+// once: next is the day's first scheduled time (the day starts at or
+// after epoch), and the day is 24 hours long. Days with a daylight
+// saving time transition skip or repeat times, so they're stepped
+// through instead.
+func (s *Schedule) wholeDay(next time.Time, epoch time.Time) (time.Time, bool) {
+	if !s.at.IsZero() || s.every > 0 {
+		return time.Time{}, false
+	}
+	day := time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, s.loc)
+	end := day.AddDate(0, 0, 1)
+	return end, !day.Before(epoch) && end.Sub(day) == 24*time.Hour
+}
+
+// perDay returns the number of times the schedule fires on a day it
+// runs on, without a daylight saving time transition
+func (s *Schedule) perDay() int64 {
+	sets := s.valueSets()
+	return int64(bits.OnesCount64(uint64(sets[minuteInd]))) *
+		int64(bits.OnesCount64(uint64(sets[hourInd]))) *
+		int64(bits.OnesCount64(uint64(sets[len(sets)-1])))
+}
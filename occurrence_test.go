@@ -0,0 +1,146 @@
+package crong
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexOf(t *testing.T) {
+	s, err := New("0 */6 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	epoch := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	// the first scheduled time at or after the epoch is #0
+	first := time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)
+	n, err := s.IndexOf(first, epoch)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, n, 0)
+
+	// four a day, from 06:00 on the first
+	later := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	n, err = s.IndexOf(later, epoch)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, n, 3+30*4+2)
+
+	at, err := s.AtIndex(n, epoch)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, at, later)
+
+	for _, tc := range []time.Time{
+		time.Date(2024, 2, 1, 12, 30, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 12, 0, 1, 0, time.UTC),
+		time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC),
+	} {
+		_, err = s.IndexOf(tc, epoch)
+		if !errors.Is(err, ErrNotScheduled) {
+			t.Errorf("%s: expected ErrNotScheduled, got %v", tc, err)
+		}
+	}
+
+	_, err = s.AtIndex(-1, epoch)
+	requireErr(t, err)
+}
+
+func TestAtIndexExhausted(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at, err := New("@at 2024-06-01T00:00:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next, err := at.AtIndex(0, epoch)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, next, at.At())
+	n, err := at.IndexOf(at.At(), epoch)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, n, 0)
+	_, err = at.AtIndex(1, epoch)
+	if !errors.Is(err, ErrScheduleExhausted) {
+		t.Errorf("expected ErrScheduleExhausted, got %v", err)
+	}
+
+	never, err := New("0 0 30 2 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = never.AtIndex(0, epoch)
+	if !errors.Is(err, ErrUnreachableSchedule) {
+		t.Errorf("expected ErrUnreachableSchedule, got %v", err)
+	}
+}
+
+// TestIndexOfMatchesStepping compares IndexOf and AtIndex against
+// stepping through each scheduled time with Next, across daylight
+// saving time transitions
+func TestIndexOfMatchesStepping(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exprs := []string{
+		"*/30 * * * *",
+		"30 1,2,3 * * *",
+		"0 0 L * *",
+		"*/20 */7 * * * *",
+	}
+	r := rand.New(rand.NewSource(1))
+	for range 10 {
+		cron, err := NewRandom(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		exprs = append(exprs, cron)
+	}
+
+	// spans the spring and fall transitions
+	epoch := time.Date(2024, 3, 8, 13, 17, 0, 0, newYork)
+	until := time.Date(2024, 11, 5, 0, 0, 0, 0, newYork)
+	for _, cron := range exprs {
+		var opts []ParseOption
+		if len(strings.Fields(cron)) == 6 {
+			opts = append(opts, WithSeconds())
+		}
+		s, err := New(cron, newYork, opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", cron, err)
+		}
+
+		var n int64
+		checked := 0
+		for next := s.Ceil(epoch); !next.IsZero() && next.Before(until); next = s.Next(next) {
+			// check a sample, since every occurrence takes too long
+			if n%997 == 0 || checked < 50 {
+				checked++
+				index, err := s.IndexOf(next, epoch)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %s", cron, err)
+				}
+				if index != n {
+					t.Fatalf("%s: expected %s to be #%d, got #%d", cron, next, n, index)
+				}
+				at, err := s.AtIndex(n, epoch)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %s", cron, err)
+				}
+				if !at.Equal(next) {
+					t.Fatalf("%s: expected #%d to be %s, got %s", cron, n, next, at)
+				}
+			}
+			n++
+		}
+	}
+}
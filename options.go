@@ -0,0 +1,361 @@
+package crong
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseOption configures how [New] parses a cron expression
+type ParseOption func(*parseOptions)
+
+// parseOptions holds the options set by each ParseOption
+type parseOptions struct {
+	// strictBlank only allows '?' in one of the day and weekday fields
+	strictBlank bool
+
+	// seconds expects a leading seconds field
+	seconds bool
+
+	// isoWeekdays numbers weekdays from 1 (Monday) to 7 (Sunday)
+	isoWeekdays bool
+
+	// domDowUnion runs on days matching either the day or weekday
+	// field, when both are restricted
+	domDowUnion bool
+
+	// duplicates is how duplicate and overlapping
+	// list entries are handled
+	duplicates LintPolicy
+
+	// fullRange is how fields that include every
+	// value (other than '*') are handled
+	fullRange LintPolicy
+
+	// ranges restricts the values allowed in each field
+	ranges []fieldRange
+
+	// maxLength is the maximum expression length (0=no limit)
+	maxLength int
+
+	// maxListEntries is the maximum number of list
+	// entries in each field (0=no limit)
+	maxListEntries int
+
+	// maxValues is the maximum number of values across
+	// all fields, once expanded (0=no limit)
+	maxValues int
+
+	// hashKey is the key "H" entries are derived from
+	hashKey string
+
+	// mode sets which variations on standard syntax are accepted
+	mode ParseMode
+}
+
+// fieldRange restricts a field to a range of values
+type fieldRange struct {
+	name   string
+	bounds fieldBounds
+}
+
+// WithStrictBlank validates the '?' (no specific value) character as
+// Quartz does: exactly one of the day of month and day of week fields
+// must be '?', which marks that field as unspecified so the other
+// field determines the days the schedule runs. Macros expand with '?'
+// in the field they leave unspecified (ex: "@daily" is "0 0 * * ?"),
+// and one-shot (@at) schedules aren't checked. By default, '?' behaves
+// like '*', and is also allowed in the month field.
+func WithStrictBlank() ParseOption {
+	return func(o *parseOptions) {
+		o.strictBlank = true
+	}
+}
+
+// WithSeconds expects cron expressions to have a leading seconds
+// field (0-59), as in "30 0 12 * * *" (12:00:30 every day). The field
+// supports the same syntax as the minute field. Macros run on the
+// first second of the minute. Next and Prev return times to the
+// second, and MatchesSecond checks the seconds field.
+func WithSeconds() ParseOption {
+	return func(o *parseOptions) {
+		o.seconds = true
+	}
+}
+
+// WithISOWeekdays numbers the weekday field as ISO 8601 does, from
+// 1 (Monday) to 7 (Sunday), rather than from 0 (Sunday) to 6
+// (Saturday), so "1-5" is still Monday to Friday, but "5-7" is Friday
+// to Sunday and 0 is invalid. Names (ex: "MON") are unchanged. The
+// expression returned by String keeps ISO numbering (ex: "@weekly" is
+// "0 0 * * 7"), as do [Schedule.Fields], [Diff] and WithFieldRange
+// bounds for the weekday field. Matching is unaffected: Sunday is
+// [time.Sunday].
+func WithISOWeekdays() ParseOption {
+	return func(o *parseOptions) {
+		o.isoWeekdays = true
+	}
+}
+
+// WithDomDowUnion matches days as Vixie cron does: if both the day of
+// month and day of week fields are restricted (they don't start with
+// '*' or '?'), the schedule runs on days matching either field, so
+// "0 0 1 * MON" is midnight on the 1st, and on every Monday. If either
+// field starts with '*' or '?', only days matching both fields are
+// included, as without the option (ex: "0 0 */2 * MON" is midnight
+// on Mondays with an odd day of the month). By default, days must
+// match both fields.
+func WithDomDowUnion() ParseOption {
+	return func(o *parseOptions) {
+		o.domDowUnion = true
+	}
+}
+
+// weekdayField returns the weekday field, as numbered
+// by the schedule's parse options
+func (s *Schedule) weekdayField() field {
+	if s.options.isoWeekdays {
+		return isoWeekdayOpts
+	}
+	return vixieWeekdayOpts
+}
+
+// fromISOWeekdays converts ISO weekday numbers (1-7) to
+// time.Weekday numbers (0-6), sorted
+func fromISOWeekdays(values []int) []int {
+	weekdays := make([]int, len(values))
+	for i, v := range values {
+		weekdays[i] = v % 7
+	}
+	slices.Sort(weekdays)
+	return weekdays
+}
+
+// isoMacro rewrites a macro's expansion to use ISO weekday numbers
+func isoMacro(expr string) string {
+	values := strings.Split(expr, " ")
+	if values[weekdayInd] == strconv.Itoa(sundayInd) {
+		values[weekdayInd] = strconv.Itoa(isoWeekdayOpts.Conversions[Sunday])
+	}
+	return strings.Join(values, " ")
+}
+
+// WithDuplicateCheck sets how list entries that repeat values from
+// earlier entries are handled, whether duplicates (ex: "1,1") or
+// overlapping ranges (ex: "1,1-3"). By default (LintIgnore), they're
+// accepted silently, as the repeated values have no effect.
+func WithDuplicateCheck(policy LintPolicy) ParseOption {
+	return func(o *parseOptions) {
+		o.duplicates = policy
+	}
+}
+
+// WithFullRangeCheck sets how fields that include every allowed value
+// without using '*' are handled (ex: "0-59" for minutes, or "*/1").
+// With LintNormalize, those fields are replaced by '*', which changes
+// the expression returned by String, but not when the schedule runs.
+// By default (LintIgnore), they're left as written.
+func WithFullRangeCheck(policy LintPolicy) ParseOption {
+	return func(o *parseOptions) {
+		o.fullRange = policy
+	}
+}
+
+// WithFieldRange restricts the named field ("second", "minute",
+// "hour", "day", "month" or "weekday") to values from min to max,
+// inclusive. Expressions that include any value outside the range are
+// rejected, including wildcards: with WithFieldRange("hour", 8, 18),
+// "0 9-17 * * *" is accepted, but "0 * * * *" is not. The day field's
+// 'L' is treated as including days 28-31.
+func WithFieldRange(name string, min int, max int) ParseOption {
+	return func(o *parseOptions) {
+		o.ranges = append(
+			o.ranges,
+			fieldRange{name: name, bounds: fieldBounds{min: min, max: max}},
+		)
+	}
+}
+
+// WithMaxLength rejects expressions longer than n bytes with a
+// [LimitError], before they're parsed. Along with WithMaxListEntries
+// and WithMaxValues, it bounds the work done parsing untrusted input.
+// The expression returned by String (ex: a macro's expansion) is
+// also checked, so it can always be parsed with the same options.
+func WithMaxLength(n int) ParseOption {
+	return func(o *parseOptions) {
+		o.maxLength = n
+	}
+}
+
+// WithMaxListEntries rejects fields with more than n comma-separated
+// entries (ex: "1,5,10-20" has 3) with a [LimitError], before the
+// field is parsed
+func WithMaxListEntries(n int) ParseOption {
+	return func(o *parseOptions) {
+		o.maxListEntries = n
+	}
+}
+
+// WithMaxValues rejects expressions whose fields include more than n
+// values in total, once ranges and steps are expanded, with a
+// [LimitError] (ex: "*/15 9-17 * * *" has 4 minutes and 9 hours, for
+// a total of 13). Wildcards ('*' and '?') aren't expanded, so they
+// don't count toward the limit.
+func WithMaxValues(n int) ParseOption {
+	return func(o *parseOptions) {
+		o.maxValues = n
+	}
+}
+
+// checkLength checks the expression against WithMaxLength
+func (o parseOptions) checkLength(cron string) error {
+	if o.maxLength > 0 && len(cron) > o.maxLength {
+		return &LimitError{Limit: "length", Max: o.maxLength, Actual: len(cron)}
+	}
+	return nil
+}
+
+// validateListEntries checks each field against WithMaxListEntries,
+// and returns false if any field exceeds it
+func (s *Schedule) validateListEntries(verr *ValidationError) bool {
+	maxEntries := s.options.maxListEntries
+	if maxEntries <= 0 {
+		return true
+	}
+	ok := true
+	for _, fv := range s.fieldValues() {
+		if n := strings.Count(fv.value, ",") + 1; n > maxEntries {
+			verr.add(
+				fv.field,
+				fv.value,
+				fv.field.wrapErr(
+					&LimitError{Limit: "list entries", Max: maxEntries, Actual: n},
+				),
+			)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// checkValues checks the expanded field values against WithMaxValues
+func (s *Schedule) checkValues() error {
+	if s.options.maxValues <= 0 {
+		return nil
+	}
+	n := len(s.seconds) + len(s.minutes) + len(s.hours) +
+		len(s.days) + len(s.months) + len(s.weekdays)
+	if n > s.options.maxValues {
+		return &LimitError{Limit: "values", Max: s.options.maxValues, Actual: n}
+	}
+	return nil
+}
+
+// validateRanges checks the schedule's fields against the
+// ranges set by WithFieldRange
+func (s *Schedule) validateRanges(verr *ValidationError) {
+	if len(s.options.ranges) == 0 {
+		return
+	}
+	fvs := s.fieldValues()
+	for _, r := range s.options.ranges {
+		i := slices.IndexFunc(fvs, func(fv fieldValue) bool {
+			return fv.field.Name == r.name
+		})
+		if i == -1 {
+			verr.add(
+				field{Name: r.name},
+				"",
+				fmt.Errorf("unknown field '%s' for allowed range", r.name),
+			)
+			continue
+		}
+
+		f, value := fvs[i].field, fvs[i].value
+		values, err := f.parse(value)
+		if err != nil {
+			// already reported
+			continue
+		}
+		if f.Index == dayInd && (s.lastDay || s.nearestWeekdaySet.has(0)) {
+			values = append(values, 28, 29, 30, 31)
+		}
+		if f.Index == dayInd {
+			for _, entry := range strings.Split(value, string(ListSeparator)) {
+				if day, ok := nearestWeekdayDay(entry); ok && day > 0 {
+					values = append(values, day)
+				}
+			}
+		}
+		if f.Index == weekdayInd {
+			for _, entry := range strings.Split(value, string(ListSeparator)) {
+				if weekday, ok := f.lastWeekday(entry); ok {
+					values = append(values, weekday)
+				}
+				if weekday, _, ok := f.nthWeekday(entry); ok {
+					values = append(values, weekday)
+				}
+			}
+			values = f.canonical(values)
+		}
+		for _, v := range values {
+			if v < r.bounds.min || v > r.bounds.max {
+				verr.add(
+					f,
+					value,
+					f.error(
+						fmt.Sprintf(
+							"'%s' includes %d, outside the allowed range %d-%d",
+							value,
+							v,
+							r.bounds.min,
+							r.bounds.max,
+						),
+					),
+				)
+				break
+			}
+		}
+	}
+}
+
+// validateStrictBlank checks the schedule's use of '?' when
+// the WithStrictBlank option is set
+func (s *Schedule) validateStrictBlank(verr *ValidationError) {
+	blankStr := string(Blank)
+	dayBlank := s.Day() == blankStr
+	weekdayBlank := s.Weekday() == blankStr
+
+	if s.Month() == blankStr {
+		verr.add(
+			monthOpts,
+			s.Month(),
+			monthOpts.error(
+				fmt.Sprintf("'%c' only allowed in day or weekday fields", Blank),
+			),
+		)
+	}
+	switch {
+	case s.macro != "" || !s.at.IsZero() || s.every > 0:
+		//
+	case dayBlank && weekdayBlank:
+		verr.add(
+			weekdayOpts,
+			s.Weekday(),
+			fmt.Errorf(
+				"invalid day/weekday entries: '%c' only allowed in one of the day or weekday fields",
+				Blank,
+			),
+		)
+	case !dayBlank && !weekdayBlank:
+		verr.add(
+			weekdayOpts,
+			s.Weekday(),
+			fmt.Errorf(
+				"invalid day/weekday entries: '%c' required in one of the day or weekday fields",
+				Blank,
+			),
+		)
+	}
+}
@@ -0,0 +1,510 @@
+package crong
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStrictBlank(t *testing.T) {
+	type blankCase struct {
+		Cron        string
+		ExpectError bool
+	}
+	cases := []blankCase{
+		{Cron: "0 12 ? * MON"},
+		{Cron: "0 12 15 * ?"},
+		{Cron: "0 12 ? * *"},
+		{Cron: "@daily"},
+		{Cron: "@at 2024-02-21T12:00:00Z"},
+		{Cron: "0 12 * * *", ExpectError: true},
+		{Cron: "0 12 15 * MON", ExpectError: true},
+		{Cron: "0 12 ? * ?", ExpectError: true},
+		{Cron: "0 12 1 ? *", ExpectError: true},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				// the default, lenient behavior accepts all of these
+				if _, err := New(tc.Cron, nil); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				_, err := New(tc.Cron, nil, WithStrictBlank())
+				switch {
+				case tc.ExpectError && err == nil:
+					t.Fatalf("expected error")
+				case !tc.ExpectError && err != nil:
+					t.Fatalf("unexpected error: %s", err)
+				}
+			},
+		)
+	}
+
+	// '?' leaves the day unspecified, so the weekday decides
+	s, err := New("0 12 ? * MON", nil, WithStrictBlank())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(
+		t,
+		s.Next(time.Date(2024, 2, 21, 0, 0, 0, 0, time.UTC)),
+		time.Date(2024, 2, 26, 12, 0, 0, 0, time.UTC),
+	)
+}
+
+func TestWithSeconds(t *testing.T) {
+	s, err := New("15,45 30 12 * * *", nil, WithSeconds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "15,45 30 12 * * *")
+	assertEqual(t, s.Second(), "15,45")
+	assertEqual(t, s.Minute(), "30")
+
+	start := time.Date(2024, 1, 1, 12, 30, 15, 0, time.UTC)
+	assertEqual(t, s.Next(start), time.Date(2024, 1, 1, 12, 30, 45, 0, time.UTC))
+	assertEqual(t, s.Next(start.Add(-time.Second)), start)
+	assertEqual(
+		t,
+		s.Next(start.Add(time.Minute)),
+		time.Date(2024, 1, 2, 12, 30, 15, 0, time.UTC),
+	)
+	assertEqual(
+		t,
+		s.Prev(start),
+		time.Date(2023, 12, 31, 12, 30, 45, 0, time.UTC),
+	)
+	assertEqual(t, s.Prev(start.Add(500*time.Millisecond)), start)
+	assertEqual(t, s.Prev(start.Add(time.Minute)), start.Add(30*time.Second))
+
+	assertEqual(t, s.Matches(start.Add(5*time.Second)), true)
+	assertEqual(t, s.MatchesSecond(start.Add(5*time.Second)), false)
+	assertEqual(t, s.MatchesSecond(start.Add(500*time.Millisecond)), true)
+
+	// macros run on the first second of the minute
+	s, err = New(Hourly, nil, WithSeconds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "0 0 * * * *")
+	assertEqual(
+		t,
+		s.Next(start),
+		time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+	)
+
+	for _, cron := range []string{"0 12 * * *", "? 0 12 * * *", "60 0 12 * * *"} {
+		if _, err = New(cron, nil, WithSeconds()); err == nil {
+			t.Errorf("expected error for %q", cron)
+		}
+	}
+}
+
+func TestMatchesSecond(t *testing.T) {
+	s, err := New("0 12 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	noon := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	assertEqual(t, s.Matches(noon.Add(30*time.Second)), true)
+	assertEqual(t, s.MatchesSecond(noon.Add(30*time.Second)), false)
+	assertEqual(t, s.MatchesSecond(noon), true)
+	assertEqual(t, s.Second(), "")
+
+	s, err = New(At+" 2024-01-01T12:00:30Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.MatchesSecond(noon), false)
+	assertEqual(t, s.MatchesSecond(noon.Add(30*time.Second)), true)
+}
+
+func TestWithFieldRange(t *testing.T) {
+	businessHours := WithFieldRange("hour", 8, 18)
+
+	type rangeCase struct {
+		Cron        string
+		ExpectError bool
+	}
+	cases := []rangeCase{
+		{Cron: "0 9-17 * * *"},
+		{Cron: "0 8,18 * * *"},
+		{Cron: "*/15 12 * * MON-FRI"},
+		{Cron: "0 * * * *", ExpectError: true},
+		{Cron: "0 7-9 * * *", ExpectError: true},
+		{Cron: "0 19 * * *", ExpectError: true},
+		{Cron: Daily, ExpectError: true},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				_, err := New(tc.Cron, nil, businessHours)
+				switch {
+				case tc.ExpectError && err == nil:
+					t.Fatalf("expected error")
+				case !tc.ExpectError && err != nil:
+					t.Fatalf("unexpected error: %s", err)
+				}
+			},
+		)
+	}
+
+	_, err := New("0 7-9 * * *", nil, businessHours)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	assertEqual(
+		t,
+		verr.Field("hour").Error(),
+		"invalid hour entry: '7-9' includes 7, outside the allowed range 8-18",
+	)
+
+	// 'L' may be any of days 28-31
+	_, err = New("0 12 L * *", nil, WithFieldRange("day", 1, 28))
+	if err == nil {
+		t.Errorf("expected error")
+	}
+	_, err = New("0 12 L * *", nil, WithFieldRange("day", 15, 31))
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	_, err = New("30 0 12 * * *", nil, WithSeconds(), WithFieldRange("second", 0, 0))
+	if err == nil {
+		t.Errorf("expected error")
+	}
+
+	_, err = New("0 12 * * *", nil, WithFieldRange("hours", 8, 18))
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	assertEqual(t, verr.Fields[0].Field, "hours")
+}
+
+func TestLimits(t *testing.T) {
+	type limitCase struct {
+		Cron   string
+		Opts   []ParseOption
+		Limit  string
+		Actual int
+	}
+	cases := []limitCase{
+		{Cron: "0 9 * * *", Opts: []ParseOption{WithMaxLength(9)}},
+		{
+			Cron:   "0 9 * * MON",
+			Opts:   []ParseOption{WithMaxLength(9)},
+			Limit:  "length",
+			Actual: 11,
+		},
+		{
+			Cron:   "@at 2025-07-01T09:00:00Z",
+			Opts:   []ParseOption{WithMaxLength(9)},
+			Limit:  "length",
+			Actual: 24,
+		},
+		{Cron: "0 1,2,3 * * *", Opts: []ParseOption{WithMaxListEntries(3)}},
+		{
+			Cron:   "0 1,2,3,4 * * *",
+			Opts:   []ParseOption{WithMaxListEntries(3)},
+			Limit:  "list entries",
+			Actual: 4,
+		},
+		{
+			Cron:   "0,1,2,3 0 * * * *",
+			Opts:   []ParseOption{WithSeconds(), WithMaxListEntries(3)},
+			Limit:  "list entries",
+			Actual: 4,
+		},
+		{Cron: "*/15 9-17 * * *", Opts: []ParseOption{WithMaxValues(13)}},
+		{
+			Cron:   "*/15 9-17 * * MON",
+			Opts:   []ParseOption{WithMaxValues(13)},
+			Limit:  "values",
+			Actual: 14,
+		},
+		{
+			// macros are expanded before they're checked
+			Cron:   Daily,
+			Opts:   []ParseOption{WithMaxValues(1)},
+			Limit:  "values",
+			Actual: 2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				_, err := New(tc.Cron, nil, tc.Opts...)
+				if tc.Limit == "" {
+					if err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+					return
+				}
+				var lerr *LimitError
+				if !errors.As(err, &lerr) {
+					t.Fatalf("expected a *LimitError, got %v", err)
+				}
+				assertEqual(t, lerr.Limit, tc.Limit)
+				assertEqual(t, lerr.Actual, tc.Actual)
+			},
+		)
+	}
+
+	_, err := New("0 1,2,3,4 * * *", nil, WithMaxListEntries(3))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	assertEqual(
+		t,
+		verr.Field("hour").Error(),
+		"invalid hour entry: list entries exceeds the limit of 3 (got 4)",
+	)
+
+	// derived schedules are checked too
+	s, err := New("0 9 * * *", nil, WithMaxListEntries(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = s.WithHour("1,2,3"); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestISOWeekdays(t *testing.T) {
+	// 2024-03-07 is a Thursday
+	thursday := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 9, 0, 0, 0, time.UTC)
+	}
+	type isoCase struct {
+		Cron   string
+		Expect []time.Time
+	}
+	cases := []isoCase{
+		{Cron: "0 9 * * 1-5", Expect: []time.Time{day(8), day(11), day(12)}},
+		{Cron: "0 9 * * 5-7", Expect: []time.Time{day(8), day(9), day(10), day(15)}},
+		{Cron: "0 9 * * 7", Expect: []time.Time{day(10), day(17)}},
+		{Cron: "0 9 * * SUN", Expect: []time.Time{day(10), day(17)}},
+		{Cron: "0 9 * * */2", Expect: []time.Time{day(8), day(10), day(11), day(13)}},
+		{Cron: "0 9 * * FRI,7", Expect: []time.Time{day(8), day(10), day(15)}},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil, WithISOWeekdays())
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, s.String(), tc.Cron)
+				next := thursday
+				for _, expect := range tc.Expect {
+					next = s.Next(next)
+					assertEqual(t, next, expect)
+					assertEqual(t, s.Matches(next), true)
+				}
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			},
+		)
+	}
+
+	for _, cron := range []string{"0 9 * * 0", "0 9 * * 0-5", "0 9 * * 8"} {
+		_, err := New(cron, nil, WithISOWeekdays())
+		requireErr(t, err, cron)
+	}
+
+	weekly, err := New("@weekly", nil, WithISOWeekdays())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, weekly.String(), "0 0 * * 7")
+	assertEqual(t, weekly.Next(thursday), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC))
+
+	// Sunday is 7 as written, but the same day as 0 without the option
+	iso, err := New("0 9 * * 5-7", nil, WithISOWeekdays())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	standard, err := New("0 9 * * 0,5,6", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(iso, standard), true)
+
+	weekday := iso.Fields()[weekdayInd]
+	assertEqual(t, weekday.Start, 5)
+	assertEqual(t, weekday.End, 7)
+	if !slices.Equal(weekday.Values, []int{5, 6, 7}) {
+		t.Errorf("expected values [5 6 7], got %v", weekday.Values)
+	}
+
+	friday, err := New("0 9 * * 5", nil, WithISOWeekdays())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Diff(friday, iso).String(), "weekday: added 6,7")
+	assertEqual(t, Diff(standard, iso).String(), "")
+
+	_, err = New("0 9 * * 6-7", nil, WithISOWeekdays(), WithFieldRange("weekday", 1, 5))
+	requireErr(t, err)
+
+	s, err := New(
+		"0 9 * * 1-7",
+		nil,
+		WithISOWeekdays(),
+		WithFullRangeCheck(LintNormalize),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "0 9 * * *")
+}
+
+func TestDomDowUnion(t *testing.T) {
+	// 2024-03-01 is a Friday
+	from := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		Cron string
+		Want []time.Time
+	}{
+		{
+			// the 1st, or any Monday
+			Cron: "0 0 1 * MON",
+			Want: []time.Time{
+				time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// a wildcard weekday leaves the day field to decide
+			Cron: "0 0 1 * *",
+			Want: []time.Time{
+				time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// a field starting with '*' isn't unioned
+			Cron: "0 0 */2 * MON",
+			Want: []time.Time{
+				time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// the last day of the month, or the first Monday
+			Cron: "0 0 L * MON#1",
+			Want: []time.Time{
+				time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	} {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil, WithDomDowUnion())
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				next := from
+				for _, want := range tc.Want {
+					next = s.Next(next)
+					assertEqual(t, next, want)
+					assertEqual(t, s.Matches(want), true)
+					assertEqual(t, s.Prev(want.Add(time.Minute)), want)
+				}
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			},
+		)
+	}
+
+	union, err := New("0 0 1 * MON", nil, WithDomDowUnion())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	intersection, err := New("0 0 1 * MON", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(union, intersection), false)
+	// by default, only a Monday the 1st matches
+	assertEqual(t, intersection.Next(from), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+
+	// any weekday can fall on the 1st
+	_, ok := union.EarliestTimeOfDayOn(time.Tuesday)
+	assertEqual(t, ok, true)
+
+	// a week of March has the 1st and a Monday
+	h := union.Histogram(
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+	)
+	assertEqual(t, h.Total, 2)
+}
+
+func TestParseMode(t *testing.T) {
+	type modeCase struct {
+		Cron    string
+		Default bool
+		Strict  bool
+		Lenient bool
+	}
+	cases := []modeCase{
+		{Cron: "0 12 * * MON-FRI", Default: true, Strict: true, Lenient: true},
+		{Cron: "*/10 5-17/2 * * *", Default: true, Strict: true, Lenient: true},
+		{Cron: "5/10 * * * *", Default: true, Lenient: true},
+		{Cron: "0 0 1,15/5 * *", Default: true, Lenient: true},
+		{Cron: "0 0 * ? *", Default: true, Lenient: true},
+		{Cron: "0  12 * * *", Lenient: true},
+		{Cron: "0\t12 * * *", Lenient: true},
+		{Cron: "0 12 * * * # noon", Lenient: true},
+		{Cron: "0 12 * * MON#1 # first monday", Lenient: true},
+		{Cron: "# noon"},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				for _, m := range []struct {
+					mode ParseMode
+					ok   bool
+				}{
+					{ParseDefault, tc.Default},
+					{ParseStrict, tc.Strict},
+					{ParseLenient, tc.Lenient},
+				} {
+					_, err := New(tc.Cron, nil, WithParseMode(m.mode))
+					switch {
+					case m.ok && err != nil:
+						t.Fatalf("%s: unexpected error: %s", m.mode, err)
+					case !m.ok && err == nil:
+						t.Fatalf("%s: expected error", m.mode)
+					}
+				}
+			},
+		)
+	}
+
+	s, err := New("0\t12  * * MON#1   # first monday", nil, WithParseMode(ParseLenient))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "0 12 * * MON#1")
+
+	_, err = New("5/10 * * * *", nil, WithParseMode(ParseStrict))
+	requireErr(t, err)
+	assertEqual(
+		t,
+		strings.Contains(err.Error(), "use '5-59/10'"),
+		true,
+	)
+}
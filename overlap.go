@@ -0,0 +1,137 @@
+package crong
+
+import (
+	"time"
+)
+
+const (
+	// overlapRiskRatio is the fraction of the schedule's shortest
+	// period a run can take on average before it's at risk of
+	// overlapping the next occurrence
+	overlapRiskRatio = 0.8
+
+	// overlapSamples is the number of upcoming occurrences
+	// searched for the shortest period
+	overlapSamples = 1024
+)
+
+// suggestedIntervals are the intervals tried, shortest first, when
+// suggesting a safer schedule (see [FromInterval])
+var suggestedIntervals = []time.Duration{
+	time.Minute,
+	2 * time.Minute,
+	3 * time.Minute,
+	4 * time.Minute,
+	5 * time.Minute,
+	6 * time.Minute,
+	10 * time.Minute,
+	12 * time.Minute,
+	15 * time.Minute,
+	20 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	3 * time.Hour,
+	4 * time.Hour,
+	6 * time.Hour,
+	8 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// OverlapAdvice compares a job's observed run durations to its
+// schedule (see [Schedule.OverlapAdvice])
+type OverlapAdvice struct {
+	// MeanRuntime is the mean duration of the runs analyzed
+	MeanRuntime time.Duration
+
+	// MinPeriod is the shortest time between consecutive
+	// upcoming occurrences, or 0 if the schedule doesn't
+	// occur at least twice
+	MinPeriod time.Duration
+
+	// Utilization is MeanRuntime as a fraction of MinPeriod. Runs
+	// start overlapping the next occurrence as it approaches 1.
+	Utilization float64
+
+	// AtRisk is true if Utilization is 0.8 or more
+	AtRisk bool
+
+	// Suggestion is a cron expression running at the shortest regular
+	// interval (see [FromInterval]) that keeps Utilization under 0.8,
+	// if AtRisk is true and there is one
+	Suggestion string
+}
+
+// OverlapAdvice flags the schedule as at risk of runs overlapping
+// if the mean duration of the given runtimes approaches or exceeds
+// the shortest time between its occurrences after from (ex: a
+// 50-minute job on an hourly schedule), and suggests a safer
+// expression. Runtimes that haven't ended are ignored.
+func (s *Schedule) OverlapAdvice(runtimes []*JobRuntime, from time.Time) OverlapAdvice {
+	var total time.Duration
+	var count int
+	for _, rt := range runtimes {
+		if rt.End.IsZero() || rt.End.Before(rt.Start) {
+			continue
+		}
+		total += rt.End.Sub(rt.Start)
+		count++
+	}
+	if count == 0 {
+		return s.overlapAdvice(0, from)
+	}
+	return s.overlapAdvice(total/time.Duration(count), from)
+}
+
+// OverlapAdvice returns [Schedule.OverlapAdvice] for the job's
+// schedule, using the mean duration of all its finished runs
+// (see [ScheduledJob.Stats])
+func (s *ScheduledJob) OverlapAdvice() OverlapAdvice {
+	return s.Schedule().overlapAdvice(
+		s.Stats().Mean,
+		clockNow(s.options.Clock),
+	)
+}
+
+// overlapAdvice returns OverlapAdvice for the given mean runtime
+func (s *Schedule) overlapAdvice(mean time.Duration, from time.Time) OverlapAdvice {
+	advice := OverlapAdvice{
+		MeanRuntime: mean,
+		MinPeriod:   s.minPeriod(from),
+	}
+	if advice.MinPeriod == 0 || mean == 0 {
+		return advice
+	}
+	advice.Utilization = float64(mean) / float64(advice.MinPeriod)
+	advice.AtRisk = advice.Utilization >= overlapRiskRatio
+	if !advice.AtRisk {
+		return advice
+	}
+	for _, d := range suggestedIntervals {
+		if float64(mean) < overlapRiskRatio*float64(d) {
+			advice.Suggestion, _ = FromInterval(d)
+			break
+		}
+	}
+	return advice
+}
+
+// minPeriod returns the shortest time between consecutive upcoming
+// occurrences after from, or 0 if there are fewer than two
+func (s *Schedule) minPeriod(from time.Time) time.Duration {
+	var shortest time.Duration
+	prev := s.Next(from)
+	for i := 0; i < overlapSamples && !prev.IsZero(); i++ {
+		next := s.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if gap := next.Sub(prev); shortest == 0 || gap < shortest {
+			shortest = gap
+		}
+		prev = next
+	}
+	return shortest
+}
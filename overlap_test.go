@@ -0,0 +1,79 @@
+package crong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverlapAdvice(t *testing.T) {
+	from := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	runtimes := func(durations ...time.Duration) []*JobRuntime {
+		var rts []*JobRuntime
+		for _, d := range durations {
+			rts = append(rts, &JobRuntime{Start: from, End: from.Add(d)})
+		}
+		// unfinished runs are ignored
+		return append(rts, &JobRuntime{Start: from})
+	}
+
+	testCases := []struct {
+		expr        string
+		runtimes    []*JobRuntime
+		minPeriod   time.Duration
+		atRisk      bool
+		suggestion  string
+		utilization float64
+	}{
+		{
+			expr:        "@hourly",
+			runtimes:    runtimes(45*time.Minute, 55*time.Minute),
+			minPeriod:   time.Hour,
+			atRisk:      true,
+			suggestion:  "0 */2 * * *",
+			utilization: 50.0 / 60.0,
+		},
+		{
+			expr:        "@hourly",
+			runtimes:    runtimes(10 * time.Minute),
+			minPeriod:   time.Hour,
+			utilization: 10.0 / 60.0,
+		},
+		{
+			// the shortest gap is from :55 to the top of the next hour
+			expr:        "0,55 * * * *",
+			runtimes:    runtimes(5 * time.Minute),
+			minPeriod:   5 * time.Minute,
+			atRisk:      true,
+			suggestion:  "*/10 * * * *",
+			utilization: 1,
+		},
+		{
+			expr:      "*/5 * * * *",
+			minPeriod: 5 * time.Minute,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(
+			tc.expr, func(t *testing.T) {
+				s, err := New(tc.expr, time.UTC)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				advice := s.OverlapAdvice(tc.runtimes, from)
+				assertEqual(t, advice.MinPeriod, tc.minPeriod)
+				assertEqual(t, advice.AtRisk, tc.atRisk)
+				assertEqual(t, advice.Suggestion, tc.suggestion)
+				assertEqual(t, advice.Utilization, tc.utilization)
+			},
+		)
+	}
+
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	s, err := New("@at "+at.Format(time.RFC3339), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	advice := s.OverlapAdvice(runtimes(time.Hour), from)
+	assertEqual(t, advice.MinPeriod, 0)
+	assertEqual(t, advice.AtRisk, false)
+}
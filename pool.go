@@ -0,0 +1,119 @@
+package crong
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// WorkerPool limits how many runs can be in progress at once across
+// the [ScheduledJob]s sharing it (see [ScheduledJobOptions.Pool]).
+// When ticks from several jobs are waiting for a worker, ticks from
+// jobs with a higher [ScheduledJobOptions.Priority] start first, and
+// ticks with the same priority start in the order they arrived.
+type WorkerPool struct {
+	size    int
+	running int
+	queue   []*poolTask
+	seq     uint64
+	mu      sync.Mutex
+}
+
+// poolTask is a run waiting for (or holding) a pool worker
+type poolTask struct {
+	priority int
+	seq      uint64
+	run      func()
+	started  bool
+}
+
+// NewWorkerPool returns a WorkerPool running up to size runs at once
+func NewWorkerPool(size int) (*WorkerPool, error) {
+	if size < 1 {
+		return nil, errors.New("worker pool size must be at least 1")
+	}
+	return &WorkerPool{size: size}, nil
+}
+
+// Size returns the maximum number of runs in progress at once
+func (p *WorkerPool) Size() int {
+	return p.size
+}
+
+// Queued returns the number of runs waiting for a worker
+func (p *WorkerPool) Queued() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
+
+// submit queues run with the given priority, starting it right away
+// if a worker is free
+func (p *WorkerPool) submit(priority int, run func()) *poolTask {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	task := &poolTask{priority: priority, seq: p.seq, run: run}
+	if p.running < p.size {
+		p.running++
+		task.started = true
+		go p.work(task)
+		return task
+	}
+
+	// ordered by priority (highest first), then by arrival
+	i, _ := slices.BinarySearchFunc(
+		p.queue, task, func(a *poolTask, b *poolTask) int {
+			if a.priority != b.priority {
+				return cmp.Compare(b.priority, a.priority)
+			}
+			return cmp.Compare(a.seq, b.seq)
+		},
+	)
+	p.queue = slices.Insert(p.queue, i, task)
+	return task
+}
+
+// work runs the given task, then queued tasks
+// until the queue is empty
+func (p *WorkerPool) work(task *poolTask) {
+	for task != nil {
+		task.run()
+
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.running--
+			task = nil
+		} else {
+			task = p.queue[0]
+			p.queue = p.queue[1:]
+			task.started = true
+		}
+		p.mu.Unlock()
+	}
+}
+
+// remove removes the task from the queue, returning false
+// if it has already started
+func (p *WorkerPool) remove(task *poolTask) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if task.started {
+		return false
+	}
+	p.queue = slices.DeleteFunc(
+		p.queue, func(t *poolTask) bool {
+			return t == task
+		},
+	)
+	task.started = true
+	return true
+}
+
+// isStarted returns true if the task has started (or been removed)
+func (p *WorkerPool) isStarted(task *poolTask) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return task.started
+}
@@ -0,0 +1,126 @@
+package crong
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolPriority(t *testing.T) {
+	if _, err := NewWorkerPool(0); err == nil {
+		t.Fatalf("expected error")
+	}
+	pool, err := NewWorkerPool(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	releaseCh := make(chan struct{})
+	pool.submit(0, func() { <-releaseCh })
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for _, tc := range []struct {
+		name     string
+		priority int
+	}{
+		{"low1", 0},
+		{"high", 10},
+		{"low2", 0},
+		{"mid", 5},
+	} {
+		wg.Add(1)
+		pool.submit(
+			tc.priority, func() {
+				defer wg.Done()
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, tc.name)
+			},
+		)
+	}
+	assertEqual(t, pool.Queued(), 4)
+
+	close(releaseCh)
+	wg.Wait()
+	expected := []string{"high", "mid", "low1", "low2"}
+	for i, name := range expected {
+		assertEqual(t, order[i], name)
+	}
+}
+
+func TestJobPool(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pool, err := NewWorkerPool(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	startedCh := make(chan string, 10)
+	releaseCh := make(chan struct{})
+	newJob := func(name string, priority int) *ScheduledJob {
+		return ScheduleFunc(
+			ctx,
+			s,
+			ScheduledJobOptions{
+				Name:                 name,
+				TickerReceiveTimeout: 5 * time.Second,
+				Pool:                 pool,
+				Priority:             priority,
+			},
+			func(dt time.Time) error {
+				startedCh <- name
+				<-releaseCh
+				return nil
+			},
+		)
+	}
+	cache := newJob("cache", 0)
+	billing := newJob("billing", 10)
+	defer cache.Stop(context.Background())
+	defer billing.Stop(context.Background())
+
+	// occupies the only worker
+	cache.ticker.tick(ctx)
+	assertEqual(t, <-startedCh, "cache")
+
+	cache.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return pool.Queued() == 1
+		},
+	)
+	billing.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return pool.Queued() == 2
+		},
+	)
+
+	// billing's tick arrived later, but starts first
+	releaseCh <- struct{}{}
+	assertEqual(t, <-startedCh, "billing")
+
+	// stopping the job removes its queued tick
+	cache.Stop(context.Background())
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return pool.Queued() == 0
+		},
+	)
+	close(releaseCh)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(billing.Runtimes()) == 1
+		},
+	)
+	assertEqual(t, cache.Runs.Load(), int64(1))
+}
@@ -0,0 +1,487 @@
+package crong
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnknownDelivery is returned by [MemoryTickQueue.Ack] for a
+// delivery that isn't awaiting acknowledgment, because it was already
+// acknowledged, or it timed out and was redelivered
+var ErrUnknownDelivery = errors.New("unknown delivery")
+
+// queueRetryDelay is how long a job waits to dequeue again
+// after its TickQueue returns an error
+const queueRetryDelay = time.Second
+
+// TickQueue carries a [ScheduledJob]'s ticks from the job scheduling
+// them to the jobs running them (see [ScheduledJobOptions.Queue]).
+// Backed by an external queue, this lets one process schedule work
+// that separate worker processes run. Implementations must be safe
+// for concurrent use.
+type TickQueue interface {
+	// Enqueue adds a tick to the queue for the job identified by key
+	Enqueue(ctx context.Context, key string, tk Tick) error
+
+	// Dequeue waits for the next tick queued for the job identified
+	// by key and removes it, returning ctx's error if ctx is done
+	// first
+	Dequeue(ctx context.Context, key string) (Tick, error)
+}
+
+// AckTickQueue is a [TickQueue] that delivers ticks at least once. A
+// received tick stays in the queue, hidden from other consumers,
+// until it's acknowledged, and is redelivered if it isn't
+// acknowledged in time (ex: the worker running it crashed). A
+// [ScheduledJob] with an AckTickQueue receives its ticks with
+// Receive rather than Dequeue, and acknowledges each once it's done
+// with it (see [ScheduledJobOptions.Queue]).
+type AckTickQueue interface {
+	TickQueue
+
+	// Receive waits for the next tick queued for the job identified
+	// by key, and hides it until it's acknowledged or redelivered,
+	// returning ctx's error if ctx is done first
+	Receive(ctx context.Context, key string) (Delivery, error)
+
+	// Ack acknowledges the delivery with the given ID, removing its
+	// tick from the queue
+	Ack(ctx context.Context, key string, id string) error
+}
+
+// Delivery is a tick received from an [AckTickQueue]
+type Delivery struct {
+	// ID identifies the delivery, to acknowledge it
+	ID string
+
+	// Tick is the tick delivered
+	Tick Tick
+
+	// Attempt is how many times the tick has been delivered,
+	// starting at 1
+	Attempt int
+}
+
+// QueueRole is what a [ScheduledJob] with a [TickQueue] does with it
+// (see [ScheduledJobOptions.QueueRole])
+type QueueRole int
+
+const (
+	// QueueProduceConsume enqueues the job's ticks, and runs
+	// the ticks dequeued for it
+	QueueProduceConsume QueueRole = iota
+
+	// QueueProduce enqueues the job's ticks, without running
+	// any (ex: on the node scheduling work)
+	QueueProduce
+
+	// QueueConsume runs the ticks dequeued for the job, ignoring
+	// its own schedule (ex: in a worker process)
+	QueueConsume
+)
+
+func (r QueueRole) String() string {
+	switch r {
+	case QueueProduceConsume:
+		return "produce_consume"
+	case QueueProduce:
+		return "produce"
+	case QueueConsume:
+		return "consume"
+	default:
+		return "unknown"
+	}
+}
+
+// produces returns true if the role enqueues the job's ticks
+func (r QueueRole) produces() bool {
+	return r != QueueConsume
+}
+
+// consumes returns true if the role runs dequeued ticks
+func (r QueueRole) consumes() bool {
+	return r != QueueProduce
+}
+
+// MemoryTickQueueOptions configures a [MemoryTickQueue]
+type MemoryTickQueueOptions struct {
+	// AckTimeout, if set, is how long a received tick can go without
+	// being acknowledged before it's redelivered. If it isn't set,
+	// Receive removes ticks as Dequeue does, and Ack does nothing.
+	AckTimeout time.Duration
+}
+
+// MemoryTickQueue is an [AckTickQueue] that keeps ticks in memory,
+// for jobs in the same process, and tests
+type MemoryTickQueue struct {
+	options MemoryTickQueueOptions
+	ticks   map[string][]pendingTick
+	ready   map[string]chan struct{}
+
+	// unacked holds received ticks awaiting acknowledgment,
+	// by delivery ID
+	unacked map[string]unackedTick
+	// deliveries counts deliveries, for their IDs
+	deliveries int
+	mu         sync.Mutex
+}
+
+// pendingTick is a tick waiting in a MemoryTickQueue
+type pendingTick struct {
+	tick Tick
+
+	// attempts is how many times the tick was delivered before
+	attempts int
+}
+
+// unackedTick is a received tick awaiting acknowledgment
+type unackedTick struct {
+	pendingTick
+	key      string
+	deadline time.Time
+}
+
+// NewMemoryTickQueue returns an empty [MemoryTickQueue]
+func NewMemoryTickQueue() *MemoryTickQueue {
+	return NewMemoryTickQueueWithOptions(MemoryTickQueueOptions{})
+}
+
+// NewMemoryTickQueueWithOptions returns an empty [MemoryTickQueue]
+// with the given options
+func NewMemoryTickQueueWithOptions(opts MemoryTickQueueOptions) *MemoryTickQueue {
+	return &MemoryTickQueue{
+		options: opts,
+		ticks:   map[string][]pendingTick{},
+		ready:   map[string]chan struct{}{},
+		unacked: map[string]unackedTick{},
+	}
+}
+
+// readyCh returns the channel signaled when ticks are queued for
+// the given key. The caller must hold mu.
+func (q *MemoryTickQueue) readyCh(key string) chan struct{} {
+	ch, ok := q.ready[key]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		q.ready[key] = ch
+	}
+	return ch
+}
+
+// wake wakes a waiting Dequeue, if there isn't one pending already
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Enqueue adds a tick to the end of the queue for the given key
+func (q *MemoryTickQueue) Enqueue(_ context.Context, key string, tk Tick) error {
+	q.mu.Lock()
+	q.ticks[key] = append(q.ticks[key], pendingTick{tick: tk})
+	ch := q.readyCh(key)
+	q.mu.Unlock()
+	wake(ch)
+	return nil
+}
+
+// Dequeue waits for a tick to be queued for the given key, and
+// removes the oldest one
+func (q *MemoryTickQueue) Dequeue(ctx context.Context, key string) (Tick, error) {
+	pt, err := q.next(ctx, key)
+	return pt.tick, err
+}
+
+// Receive waits for a tick to be queued for the given key, and
+// delivers the oldest one. If AckTimeout is set, the tick is
+// redelivered unless it's acknowledged within AckTimeout.
+func (q *MemoryTickQueue) Receive(ctx context.Context, key string) (Delivery, error) {
+	pt, err := q.next(ctx, key)
+	if err != nil {
+		return Delivery{}, err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.deliveries++
+	id := strconv.Itoa(q.deliveries)
+	if q.options.AckTimeout > 0 {
+		q.unacked[id] = unackedTick{
+			pendingTick: pt,
+			key:         key,
+			deadline:    time.Now().Add(q.options.AckTimeout),
+		}
+	}
+	return Delivery{ID: id, Tick: pt.tick, Attempt: pt.attempts + 1}, nil
+}
+
+// Ack acknowledges the delivery with the given ID. It returns
+// ErrUnknownDelivery if the delivery isn't awaiting acknowledgment.
+func (q *MemoryTickQueue) Ack(_ context.Context, key string, id string) error {
+	if q.options.AckTimeout <= 0 {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if u, ok := q.unacked[id]; !ok || u.key != key {
+		return ErrUnknownDelivery
+	}
+	delete(q.unacked, id)
+	return nil
+}
+
+// next waits for a tick to be queued for the given key (including
+// ticks that weren't acknowledged in time), and removes the oldest
+func (q *MemoryTickQueue) next(ctx context.Context, key string) (pendingTick, error) {
+	for {
+		q.mu.Lock()
+		ch := q.readyCh(key)
+		redeliver := q.expire(key)
+		if ticks := q.ticks[key]; len(ticks) > 0 {
+			pt := ticks[0]
+			q.ticks[key] = ticks[1:]
+			if len(ticks) > 1 {
+				// wake another consumer for the rest
+				wake(ch)
+			}
+			q.mu.Unlock()
+			return pt, nil
+		}
+		q.mu.Unlock()
+
+		// wait for a tick, or for an unacknowledged
+		// tick to be redelivered
+		var expired <-chan time.Time
+		var timer *time.Timer
+		if !redeliver.IsZero() {
+			timer = time.NewTimer(time.Until(redeliver))
+			expired = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-ch:
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return pendingTick{}, err
+		}
+	}
+}
+
+// expire moves the key's unacknowledged ticks past their deadline
+// back to the front of its queue, and returns the earliest deadline
+// of those left, or the zero time. The caller must hold mu.
+func (q *MemoryTickQueue) expire(key string) time.Time {
+	var expired []unackedTick
+	var earliest time.Time
+	now := time.Now()
+	for id, u := range q.unacked {
+		switch {
+		case u.key != key:
+		case !u.deadline.After(now):
+			delete(q.unacked, id)
+			expired = append(expired, u)
+		case earliest.IsZero() || u.deadline.Before(earliest):
+			earliest = u.deadline
+		}
+	}
+	if len(expired) == 0 {
+		return earliest
+	}
+	slices.SortFunc(
+		expired, func(a, b unackedTick) int {
+			return a.tick.Time.Compare(b.tick.Time)
+		},
+	)
+	redelivered := make([]pendingTick, 0, len(expired)+len(q.ticks[key]))
+	for _, u := range expired {
+		u.attempts++
+		redelivered = append(redelivered, u.pendingTick)
+	}
+	q.ticks[key] = append(redelivered, q.ticks[key]...)
+	return earliest
+}
+
+// Len returns the number of ticks queued for the given key,
+// including ticks awaiting acknowledgment
+func (q *MemoryTickQueue) Len(key string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.ticks[key])
+	for _, u := range q.unacked {
+		if u.key == key {
+			n++
+		}
+	}
+	return n
+}
+
+// tickSource returns the channel the job's dispatcher receives
+// ticks on. Without a Queue, it's the ticker's channel. With one,
+// the ticker's ticks are enqueued (unless the job only consumes),
+// and ticks are dequeued onto the returned channel (unless the job
+// only produces, in which case it's nil).
+func (s *ScheduledJob) tickSource(ctx context.Context, wg *sync.WaitGroup) <-chan Tick {
+	queue := s.options.Queue
+	if queue == nil {
+		return s.ticker.Ticks
+	}
+	role := s.options.QueueRole
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.produce(ctx, queue, role.produces())
+	}()
+
+	if !role.consumes() {
+		return nil
+	}
+	ticks := make(chan Tick)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.consume(ctx, queue, ticks)
+	}()
+	return ticks
+}
+
+// produce receives ticks from the job's ticker, and enqueues them,
+// or discards them if enqueue is false
+func (s *ScheduledJob) produce(ctx context.Context, queue TickQueue, enqueue bool) {
+	for {
+		var tk Tick
+		select {
+		case <-ctx.Done():
+			return
+		case tk = <-s.ticker.Ticks:
+		}
+		switch {
+		case !enqueue:
+			jobLogger().Debug(
+				"job consumes its queue only, skipping tick",
+				"scheduled_job", s,
+				"tick", tk.Time,
+			)
+			continue
+		case ScheduleState(s.state.Load()) == ScheduleSuspended:
+			jobLogger().Debug(
+				"execution suspended, skipping tick",
+				"scheduled_job", s,
+				"tick", tk.Time,
+			)
+			continue
+		case s.outsideWindow(tk):
+			continue
+		}
+		if err := queue.Enqueue(ctx, s.queueKey(), tk); err != nil {
+			jobLogger().Error(
+				"failed to enqueue tick",
+				"error", err,
+				"scheduled_job", s,
+				"tick", tk.Time,
+			)
+		}
+	}
+}
+
+// queueKey returns the key the job's ticks are queued under: its
+// Name, or its schedule's cron expression if it doesn't have one,
+// so unnamed jobs on different schedules don't take each other's
+// ticks
+func (s *ScheduledJob) queueKey() string {
+	if s.options.Name != "" {
+		return s.options.Name
+	}
+	return s.Schedule().String()
+}
+
+// consume dequeues the job's ticks, and sends them to the
+// dispatcher. Ticks from an AckTickQueue are received instead, and
+// acknowledged once the job is done with them (see settle).
+func (s *ScheduledJob) consume(ctx context.Context, queue TickQueue, ticks chan<- Tick) {
+	for ctx.Err() == nil {
+		tk, err := s.dequeue(ctx, queue)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			jobLogger().Error(
+				"failed to dequeue tick, retrying",
+				"error", err,
+				"scheduled_job", s,
+				"retry_in", queueRetryDelay,
+			)
+			timer := time.NewTimer(queueRetryDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			s.dropped.Add(1)
+			return
+		case ticks <- tk:
+		}
+	}
+}
+
+// dequeue returns the job's next tick from the queue
+func (s *ScheduledJob) dequeue(ctx context.Context, queue TickQueue) (Tick, error) {
+	aq, ok := queue.(AckTickQueue)
+	if !ok {
+		return queue.Dequeue(ctx, s.queueKey())
+	}
+	d, err := aq.Receive(ctx, s.queueKey())
+	if err != nil {
+		return Tick{}, err
+	}
+	if d.Attempt > 1 {
+		jobLogger().Warn(
+			"tick wasn't acknowledged, received again",
+			"scheduled_job", s,
+			"tick", d.Tick.Time,
+			"attempt", d.Attempt,
+		)
+	}
+	tk := d.Tick
+	tk.delivery = d.ID
+	return tk, nil
+}
+
+// settle acknowledges a tick received from an AckTickQueue once the
+// job is done with it, whether it ran or was skipped. If its run
+// finished, it's acknowledged even if the job is stopping, so runs
+// that finish while the job shuts down aren't redelivered. Other
+// ticks the job stopped before finishing with aren't acknowledged,
+// so they're redelivered.
+func (s *ScheduledJob) settle(ctx context.Context, tk Tick, finished bool) {
+	if tk.delivery == "" {
+		return
+	}
+	if finished {
+		ctx = context.WithoutCancel(ctx)
+	} else if ctx.Err() != nil {
+		return
+	}
+	aq := s.options.Queue.(AckTickQueue)
+	if err := aq.Ack(ctx, s.queueKey(), tk.delivery); err != nil {
+		jobLogger().Error(
+			"failed to acknowledge tick",
+			"error", err,
+			"scheduled_job", s,
+			"tick", tk.Time,
+		)
+	}
+}
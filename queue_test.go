@@ -0,0 +1,307 @@
+package crong
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryTickQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	q := NewMemoryTickQueue()
+	first := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		at := first.Add(time.Duration(i) * time.Minute)
+		if err := q.Enqueue(ctx, "a", Tick{Time: at}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	assertEqual(t, q.Len("a"), 3)
+	assertEqual(t, q.Len("b"), 0)
+
+	// each tick is dequeued once, with several consumers
+	results := make(chan time.Time, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			tk, err := q.Dequeue(ctx, "a")
+			if err == nil {
+				results <- tk.Time
+			}
+		}()
+	}
+	seen := map[time.Time]bool{}
+	for i := 0; i < 3; i++ {
+		seen[<-results] = true
+	}
+	assertEqual(t, len(seen), 3)
+	assertEqual(t, q.Len("a"), 0)
+
+	// without ticks, Dequeue waits for ctx
+	waitCtx, waitCancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer waitCancel()
+	_, err := q.Dequeue(waitCtx, "a")
+	requireErr(t, err)
+}
+
+func TestJobQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	queue := NewMemoryTickQueue()
+	opts := ScheduledJobOptions{
+		Name:                 "report",
+		TickerReceiveTimeout: 5 * time.Second,
+		Queue:                queue,
+	}
+
+	// the scheduling node only enqueues
+	produced := make(chan time.Time, 2)
+	opts.QueueRole = QueueProduce
+	producer := ScheduleFunc(
+		ctx, s, opts, func(dt time.Time) error {
+			produced <- dt
+			return nil
+		},
+	)
+	defer producer.Stop(context.Background())
+
+	at := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	producer.ticker.inject(ctx, at)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return queue.Len("report") == 1
+		},
+	)
+	assertEqual(t, producer.Runs.Load(), int64(0))
+
+	// the worker runs the queued tick, but not its own
+	consumed := make(chan time.Time, 2)
+	opts.QueueRole = QueueConsume
+	consumer := ScheduleFunc(
+		ctx, s, opts, func(dt time.Time) error {
+			consumed <- dt
+			return nil
+		},
+	)
+	defer consumer.Stop(context.Background())
+	consumer.ticker.inject(ctx, at.Add(time.Minute))
+	if dt := <-consumed; !dt.Equal(at) {
+		t.Errorf("expected a run for %s, got %s", at, dt)
+	}
+	assertEqual(t, queue.Len("report"), 0)
+	assertEqual(t, len(produced), 0)
+	assertEqual(t, len(consumed), 0)
+
+	// by default, a job both enqueues and runs its ticks
+	opts.QueueRole = QueueProduceConsume
+	opts.Name = "both"
+	both := ScheduleFunc(
+		ctx, s, opts, func(dt time.Time) error {
+			return nil
+		},
+	)
+	defer both.Stop(context.Background())
+	both.ticker.inject(ctx, at)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return both.Runs.Load() == 1
+		},
+	)
+}
+
+func TestJobQueueUnnamed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	yearly, err := New("0 0 1 1 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	july, err := New("0 0 1 7 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	queue := NewMemoryTickQueue()
+	f := func(dt time.Time) error { return nil }
+
+	// unnamed jobs are keyed by their schedules, so a job on another
+	// schedule doesn't take the producer's ticks
+	producer := ScheduleFunc(
+		ctx, yearly, ScheduledJobOptions{
+			TickerReceiveTimeout: 5 * time.Second,
+			Queue:                queue,
+			QueueRole:            QueueProduce,
+		}, f,
+	)
+	defer producer.Stop(context.Background())
+	other := ScheduleFunc(
+		ctx, july, ScheduledJobOptions{
+			Queue:     queue,
+			QueueRole: QueueConsume,
+		}, f,
+	)
+	defer other.Stop(context.Background())
+
+	producer.ticker.inject(ctx, time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC))
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return queue.Len(yearly.String()) == 1
+		},
+	)
+	time.Sleep(100 * time.Millisecond)
+	assertEqual(t, queue.Len(yearly.String()), 1)
+	assertEqual(t, other.Runs.Load(), int64(0))
+
+	consumer := ScheduleFunc(
+		ctx, yearly, ScheduledJobOptions{
+			Queue:     queue,
+			QueueRole: QueueConsume,
+		}, f,
+	)
+	defer consumer.Stop(context.Background())
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return consumer.Runs.Load() == 1
+		},
+	)
+	assertEqual(t, other.Runs.Load(), int64(0))
+}
+
+func TestMemoryTickQueueAck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	q := NewMemoryTickQueueWithOptions(
+		MemoryTickQueueOptions{AckTimeout: 50 * time.Millisecond},
+	)
+	at := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	if err := q.Enqueue(ctx, "a", Tick{Time: at}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	first, err := q.Receive(ctx, "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, first.Attempt, 1)
+	assertEqual(t, first.Tick.Time, at)
+	// hidden from other consumers, but still queued
+	assertEqual(t, q.Len("a"), 1)
+
+	// it isn't acknowledged in time, so it's delivered again
+	second, err := q.Receive(ctx, "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, second.Attempt, 2)
+	assertEqual(t, second.Tick.Time, at)
+	if err = q.Ack(ctx, "a", first.ID); err != ErrUnknownDelivery {
+		t.Errorf("expected ErrUnknownDelivery, got %v", err)
+	}
+	if err = q.Ack(ctx, "a", second.ID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, q.Len("a"), 0)
+}
+
+func TestJobQueueAckStopping(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	queue := NewMemoryTickQueueWithOptions(
+		MemoryTickQueueOptions{AckTimeout: 50 * time.Millisecond},
+	)
+	at := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	if err = queue.Enqueue(ctx, "report", Tick{Time: at, Last: at}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the run finishes after Stop cancels the job's context
+	started := make(chan struct{})
+	job := ScheduleFuncContext(
+		ctx, s, ScheduledJobOptions{
+			Name:      "report",
+			Queue:     queue,
+			QueueRole: QueueConsume,
+		},
+		func(ctx context.Context, dt time.Time) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		},
+	)
+	<-started
+	job.Stop(ctx)
+
+	// so it's acknowledged, rather than redelivered
+	time.Sleep(100 * time.Millisecond)
+	assertEqual(t, queue.Len("report"), 0)
+	receiveCtx, receiveCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer receiveCancel()
+	if d, err := queue.Receive(receiveCtx, "report"); err == nil {
+		t.Errorf("expected no redelivery, got %s (attempt %d)", d.Tick.Time, d.Attempt)
+	}
+}
+
+func TestJobQueueAck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	queue := NewMemoryTickQueueWithOptions(
+		MemoryTickQueueOptions{AckTimeout: 100 * time.Millisecond},
+	)
+	opts := ScheduledJobOptions{
+		Name:                 "report",
+		TickerReceiveTimeout: 5 * time.Second,
+		Queue:                queue,
+		QueueRole:            QueueConsume,
+	}
+	at := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	if err = queue.Enqueue(ctx, "report", Tick{Time: at, Last: at}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the first worker stops before its run finishes
+	started := make(chan struct{})
+	crashed := ScheduleFuncContext(
+		ctx, s, opts, func(ctx context.Context, dt time.Time) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+	<-started
+	crashed.Shutdown(ctx)
+	assertEqual(t, queue.Len("report"), 1)
+
+	// so the tick is redelivered to the next
+	ran := make(chan time.Time, 1)
+	worker := ScheduleFunc(
+		ctx, s, opts, func(dt time.Time) error {
+			ran <- dt
+			return nil
+		},
+	)
+	defer worker.Stop(context.Background())
+	if dt := <-ran; !dt.Equal(at) {
+		t.Errorf("expected a run for %s, got %s", at, dt)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return queue.Len("report") == 0
+		},
+	)
+}
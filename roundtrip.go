@@ -0,0 +1,103 @@
+package crong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckRoundTrip parses the schedule's String with the location and
+// parse options the schedule was created with (and its validity
+// window, which isn't part of the expression), and returns an error
+// if that fails, or doesn't produce an equivalent schedule (see
+// [Equivalent]). String is meant to be stored as the source of truth
+// for a schedule, so this should always return nil. It's exported so
+// code persisting schedules can assert that in its own tests.
+func CheckRoundTrip(s *Schedule) error {
+	opts := s.options
+	parsed, err := new(Schedule).parse(
+		s.String(),
+		s.loc,
+		[]ParseOption{func(o *parseOptions) { *o = opts }},
+	)
+	if err != nil {
+		return fmt.Errorf("schedule '%s' doesn't round-trip: %w", s, err)
+	}
+	parsed.notBefore, parsed.notAfter = s.notBefore, s.notAfter
+	if parsed.String() != s.String() {
+		return fmt.Errorf(
+			"schedule '%s' doesn't round-trip: parsed as '%s'",
+			s,
+			parsed,
+		)
+	}
+	if !Equivalent(s, parsed) {
+		return fmt.Errorf(
+			"schedule '%s' doesn't round-trip: parsed schedule isn't equivalent",
+			s,
+		)
+	}
+	return nil
+}
+
+// Equivalent returns true if the given schedules fire at the same
+// times: they're in the same location, have the same validity window,
+// and their fields include the same values, however they were written (ex: "1-3" and "1,2,3"
+// are equivalent, as are "@daily" and "0 0 * * *").
+func Equivalent(a *Schedule, b *Schedule) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.loc.String() != b.loc.String() || !a.at.Equal(b.at) || a.every != b.every ||
+		a.reboot != b.reboot {
+		return false
+	}
+	if !a.notBefore.Equal(b.notBefore) || !a.notAfter.Equal(b.notAfter) {
+		return false
+	}
+	as, bs := a.valueSets(), b.valueSets()
+	return as == bs &&
+		a.lastDay == b.lastDay &&
+		a.weekOfYearSet == b.weekOfYearSet &&
+		a.nearestWeekdaySet == b.nearestWeekdaySet &&
+		a.lastWeekdaySet == b.lastWeekdaySet &&
+		a.nthWeekdaySet == b.nthWeekdaySet &&
+		a.union == b.union
+}
+
+// valueSets returns the values each field includes, indexed by field
+// position, with the seconds field last. Wildcards include every
+// value, and without a seconds field, only the first second is
+// included.
+func (s *Schedule) valueSets() [6]valueSet {
+	anySet := func(anyValue bool, set valueSet, f field) valueSet {
+		if anyValue {
+			return newValueSet(f.Allowed)
+		}
+		return set
+	}
+	seconds := newValueSet([]int{0})
+	if s.options.seconds {
+		seconds = s.secondSet
+	}
+	return [6]valueSet{
+		minuteInd:  anySet(s.allowAnyMinute, s.minuteSet, minuteOpts),
+		hourInd:    anySet(s.allowAnyHour, s.hourSet, hourOpts),
+		dayInd:     anySet(s.allowAnyDay, s.daySet, dayOpts),
+		monthInd:   anySet(s.allowAnyMonth, s.monthSet, monthOpts),
+		weekdayInd: anySet(s.allowAnyWeekday, s.weekdaySet, weekdayOpts),
+		5:          seconds,
+	}
+}
+
+// blankMacro rewrites a macro's expansion to use '?' in the day or
+// weekday field it leaves unspecified, so the expression returned by
+// String parses with WithStrictBlank
+func blankMacro(expr string) string {
+	values := strings.Split(expr, " ")
+	if values[weekdayInd] == string(Any) {
+		values[weekdayInd] = string(Blank)
+	} else {
+		values[dayInd] = string(Blank)
+	}
+	return strings.Join(values, " ")
+}
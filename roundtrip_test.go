@@ -0,0 +1,227 @@
+package crong
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEquivalent(t *testing.T) {
+	type equivalentCase struct {
+		A      string
+		B      string
+		Expect bool
+	}
+	cases := []equivalentCase{
+		{A: "1-3 * * * *", B: "1,2,3 * * * *", Expect: true},
+		{A: "*/15 * * * *", B: "0,15,30,45 * * * *", Expect: true},
+		{A: "@daily", B: "0 0 * * *", Expect: true},
+		{A: "0 0 ? * *", B: "0 0 * * *", Expect: true},
+		{A: "0 0 * * MON-FRI", B: "0 0 * * 1-5", Expect: true},
+		{A: "0 0 L * *", B: "0 0 L * *", Expect: true},
+		{A: "1 * * * *", B: "2 * * * *", Expect: false},
+		{A: "0 0 L * *", B: "0 0 31 * *", Expect: false},
+		{A: "0-59 * * * *", B: "* * * * *", Expect: true},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.A+"|"+tc.B, func(t *testing.T) {
+				a, err := New(tc.A, nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				b, err := New(tc.B, nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, Equivalent(a, b), tc.Expect)
+				assertEqual(t, Equivalent(b, a), tc.Expect)
+			},
+		)
+	}
+
+	s, err := New("0 0 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(s, s.WithLocation(newYork)), false)
+	assertEqual(t, Equivalent(s, nil), false)
+	assertEqual(t, Equivalent(nil, nil), true)
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	type roundTripCase struct {
+		Cron   string
+		Opts   []ParseOption
+		Expect string
+	}
+	cases := []roundTripCase{
+		{Cron: "*/5 1-3 * JAN-MAR MON", Expect: "*/5 1-3 * JAN-MAR MON"},
+		{Cron: "@hourly", Expect: "0 * * * *"},
+		{
+			Cron:   "@daily",
+			Opts:   []ParseOption{WithSeconds()},
+			Expect: "0 0 0 * * *",
+		},
+		{
+			// macros expand with '?' in their unspecified field
+			Cron:   "@daily",
+			Opts:   []ParseOption{WithStrictBlank()},
+			Expect: "0 0 * * ?",
+		},
+		{
+			Cron:   "@weekly",
+			Opts:   []ParseOption{WithStrictBlank()},
+			Expect: "0 0 ? * 0",
+		},
+		{
+			Cron:   "0-59 0-23 * * *",
+			Opts:   []ParseOption{WithFullRangeCheck(LintNormalize)},
+			Expect: "* * * * *",
+		},
+		{
+			Cron:   "@at 2016-12-31T23:59:60Z",
+			Opts:   []ParseOption{WithSeconds()},
+			Expect: "@at 2017-01-01T00:00:00Z",
+		},
+		{
+			Cron:   "@at 2024-03-01T12:00:00.5+05:30",
+			Expect: "@at 2024-03-01T06:30:00.5Z",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil, tc.Opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, s.String(), tc.Expect)
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			},
+		)
+	}
+
+	t.Run(
+		"max length", func(t *testing.T) {
+			// the expansion is checked too, since it's what's stored
+			_, err := New("@daily", nil, WithMaxLength(8))
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("expected LimitError, got %v", err)
+			}
+			assertEqual(t, limitErr.Actual, len("0 0 * * *"))
+		},
+	)
+}
+
+// randomRoundTrip returns a random expression, location and parse
+// options, covering the options that change what String returns
+func randomRoundTrip(r *rand.Rand) (string, *time.Location, []ParseOption) {
+	locs := []string{"UTC", "America/New_York", "Asia/Kolkata", "Australia/Lord_Howe"}
+	loc, err := time.LoadLocation(locs[r.Intn(len(locs))])
+	if err != nil {
+		panic(err)
+	}
+
+	var opts []ParseOption
+	if r.Intn(4) == 0 {
+		ts := time.Date(
+			2020+r.Intn(10),
+			time.Month(1+r.Intn(12)),
+			1+r.Intn(28),
+			r.Intn(24),
+			r.Intn(60),
+			r.Intn(60),
+			r.Intn(4)*250_000_000,
+			loc,
+		)
+		if r.Intn(2) == 0 {
+			opts = append(opts, WithSeconds())
+		}
+		if r.Intn(2) == 0 {
+			return At + " " + ts.Format("2006-01-02T15:04:05"), loc, opts
+		}
+		return At + " " + ts.Format(time.RFC3339Nano), loc, opts
+	}
+
+	if r.Intn(5) == 0 {
+		macro := macros[r.Intn(len(macros))]
+		if r.Intn(2) == 0 {
+			opts = append(opts, WithSeconds())
+		}
+		if r.Intn(2) == 0 {
+			opts = append(opts, WithStrictBlank())
+		}
+		return macro, loc, opts
+	}
+
+	cron, err := NewRandom(r)
+	if err != nil {
+		panic(err)
+	}
+	values := strings.Split(cron, " ")
+	if len(values) != 5 {
+		return cron, loc, opts
+	}
+	if r.Intn(4) == 0 {
+		values[r.Intn(2)] = []string{"0-59", "0-23"}[r.Intn(2)]
+		opts = append(opts, WithFullRangeCheck(LintNormalize))
+	}
+	if r.Intn(4) == 0 {
+		values[dayInd+r.Intn(2)*(weekdayInd-dayInd)] = string(Blank)
+		opts = append(opts, WithStrictBlank())
+	}
+	if r.Intn(3) == 0 {
+		values = append([]string{secondOpts.randomNoList(r)}, values...)
+		opts = append(opts, WithSeconds())
+	}
+	return strings.Join(values, " "), loc, opts
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	for i := range 500 {
+		f.Add(int64(i))
+	}
+	f.Fuzz(
+		func(t *testing.T, seed int64) {
+			cron, loc, opts := randomRoundTrip(rand.New(rand.NewSource(seed)))
+			s, err := New(cron, loc, opts...)
+			if err != nil {
+				// only expressions that parse need to round-trip
+				t.Skipf("%d (%s): %s", seed, cron, err)
+			}
+			if err = CheckRoundTrip(s); err != nil {
+				t.Fatalf("%d (%s): %s", seed, cron, err)
+			}
+
+			// parsing String with the original arguments reproduces
+			// an equivalent schedule, which fires at the same times
+			parsed, err := New(s.String(), loc, opts...)
+			if err != nil {
+				t.Fatalf("%d (%s): %s", seed, cron, err)
+			}
+			if !Equivalent(s, parsed) {
+				t.Fatalf("%d (%s): '%s' isn't equivalent", seed, cron, parsed)
+			}
+			assertEqual(t, parsed.String(), s.String())
+			next := time.Date(2020, 1, 1, 0, 0, 0, 0, loc)
+			for i := 0; i < 5; i++ {
+				expected := s.Next(next)
+				assertEqual(t, parsed.Next(next), expected)
+				if expected.IsZero() {
+					break
+				}
+				next = expected
+			}
+		},
+	)
+}
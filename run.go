@@ -0,0 +1,185 @@
+package crong
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PanicError is the error recorded for a run whose job function
+// panicked (see [JobRuntime.Error]). Panics are recovered, so they're
+// handled as failed runs (ex: counted toward MaxFailures) rather
+// than crashing the process.
+type PanicError struct {
+	// Value is the value the function panicked with
+	Value any
+
+	// Stack is the stack trace of the goroutine that panicked
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("job panicked: %v", e.Value)
+}
+
+// Unwrap returns the value the function panicked with,
+// if it's an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// runKey is the context key for the current [ScheduledJob] run
+type runKey struct{}
+
+// run holds state for a single [ScheduledJob] run, which job
+// functions can access through the run's context
+type run struct {
+	id      string
+	tick    time.Time
+	started time.Time
+
+	attrs      []slog.Attr
+	heartbeat  time.Time
+	checkpoint []byte
+	mu         sync.Mutex
+}
+
+// newRun returns a run for the given tick, starting now,
+// with a new random identifier
+func newRun(tick time.Time) *run {
+	return &run{
+		id:      randomID(),
+		tick:    tick,
+		started: time.Now(),
+	}
+}
+
+// randomID returns a new random hex identifier
+func randomID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ActiveRun describes a [ScheduledJob] run in progress
+// (see [ScheduledJob.ActiveRuns])
+type ActiveRun struct {
+	// RunID identifies the run (see [RunID])
+	RunID string
+
+	// Tick is the time of the tick the run is for, which
+	// becomes the run's JobRuntime.Start
+	Tick time.Time
+
+	// Started is when the job function was called
+	Started time.Time
+
+	// LastHeartbeat is the last time the run reported progress with
+	// [Heartbeat] or [Checkpoint], or the zero time if it hasn't
+	LastHeartbeat time.Time
+
+	// Checkpoint is the data from the run's most recent [Checkpoint]
+	Checkpoint []byte
+}
+
+// active returns the run's current progress
+func (r *run) active() ActiveRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ActiveRun{
+		RunID:         r.id,
+		Tick:          r.tick,
+		Started:       r.started,
+		LastHeartbeat: r.heartbeat,
+		Checkpoint:    r.checkpoint,
+	}
+}
+
+// Attrs returns a copy of the attributes attached to the run
+func (r *run) Attrs() []slog.Attr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.attrs) == 0 {
+		return nil
+	}
+	attrs := make([]slog.Attr, len(r.attrs))
+	copy(attrs, r.attrs)
+	return attrs
+}
+
+// runFromContext returns the run for the given context, or nil
+// if the context doesn't belong to a run
+func runFromContext(ctx context.Context) *run {
+	r, _ := ctx.Value(runKey{}).(*run)
+	return r
+}
+
+// RunID returns the identifier of the current run of a [ScheduledJob],
+// given the context passed to the job function, so it can be passed
+// along to downstream work. It matches the run's [JobRuntime.RunID]
+// and the run_id attribute of the run's log records. ok is false if
+// ctx doesn't belong to a run.
+func RunID(ctx context.Context) (id string, ok bool) {
+	r := runFromContext(ctx)
+	if r == nil {
+		return "", false
+	}
+	return r.id, true
+}
+
+// Heartbeat records that the current run of a [ScheduledJob] is still
+// making progress, given the context passed to the job function, so
+// supervisors can tell a slow run from a hung one (see
+// [ScheduledJob.ActiveRuns]). It returns false if ctx doesn't belong
+// to a run.
+func Heartbeat(ctx context.Context) bool {
+	r := runFromContext(ctx)
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeat = time.Now()
+	return true
+}
+
+// Checkpoint records a heartbeat (see [Heartbeat]) along with data
+// describing the current run's progress, replacing any previous
+// checkpoint. The most recent checkpoint is recorded on the run's
+// [JobRuntime], so a later run can pick up where a failed one left
+// off. data is copied. It returns false if ctx doesn't belong to a run.
+func Checkpoint(ctx context.Context, data []byte) bool {
+	r := runFromContext(ctx)
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeat = time.Now()
+	r.checkpoint = slices.Clone(data)
+	return true
+}
+
+// AddRunAttrs attaches structured attributes to the current run of a
+// [ScheduledJob], given the context passed to the job function (see
+// [ScheduleFuncContext]). The attributes are recorded on the run's
+// [JobRuntime] and included in the log record for the finished run.
+// It returns false if ctx doesn't belong to a run.
+//
+//	crong.AddRunAttrs(ctx, slog.Int("rows", 1234))
+func AddRunAttrs(ctx context.Context, attrs ...slog.Attr) bool {
+	r := runFromContext(ctx)
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attrs = append(r.attrs, attrs...)
+	return true
+}
@@ -0,0 +1,186 @@
+package crong
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestAddRunAttrs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if AddRunAttrs(ctx, slog.Int("rows", 1)) {
+		t.Fatalf("expected false outside of a run")
+	}
+
+	sj := ScheduleFuncContext(
+		ctx,
+		s,
+		ScheduledJobOptions{TickerReceiveTimeout: 5 * time.Second},
+		func(ctx context.Context, dt time.Time) error {
+			if !AddRunAttrs(ctx, slog.Int("rows", 1234)) {
+				t.Errorf("expected attrs to be added")
+			}
+			AddRunAttrs(ctx, slog.String("table", "users"))
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+
+	attrs := sj.Runtimes()[0].Attrs
+	if len(attrs) != 2 {
+		t.Fatalf("expected 2 attrs, got %d", len(attrs))
+	}
+	assertEqual(t, attrs[0].Key, "rows")
+	assertEqual(t, attrs[0].Value.Int64(), int64(1234))
+	assertEqual(t, attrs[1].Key, "table")
+	assertEqual(t, attrs[1].Value.String(), "users")
+}
+
+func TestRunID(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := RunID(ctx); ok {
+		t.Fatalf("expected no run ID outside of a run")
+	}
+
+	idCh := make(chan string, 2)
+	sj := ScheduleFuncContext(
+		ctx,
+		s,
+		ScheduledJobOptions{TickerReceiveTimeout: 5 * time.Second},
+		func(ctx context.Context, dt time.Time) error {
+			id, _ := RunID(ctx)
+			idCh <- id
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+
+	runtimes := sj.Runtimes()
+	first, second := <-idCh, <-idCh
+	if first == "" || first == second {
+		t.Fatalf("expected unique run IDs, got %q and %q", first, second)
+	}
+	assertEqual(t, runtimes[0].RunID, first)
+	assertEqual(t, runtimes[1].RunID, second)
+}
+
+func TestCheckpoint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if Heartbeat(ctx) || Checkpoint(ctx, nil) {
+		t.Fatalf("expected false outside of a run")
+	}
+
+	checkpointedCh := make(chan struct{})
+	releaseCh := make(chan struct{})
+	sj := ScheduleFuncContext(
+		ctx,
+		s,
+		ScheduledJobOptions{TickerReceiveTimeout: 5 * time.Second},
+		func(ctx context.Context, dt time.Time) error {
+			if !Heartbeat(ctx) {
+				t.Errorf("expected heartbeat to be recorded")
+			}
+			Checkpoint(ctx, []byte("page=3"))
+			close(checkpointedCh)
+			<-releaseCh
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	<-checkpointedCh
+	active := sj.ActiveRuns()
+	if len(active) != 1 {
+		t.Fatalf("expected 1 active run, got %d", len(active))
+	}
+	assertEqual(t, string(active[0].Checkpoint), "page=3")
+	if active[0].LastHeartbeat.Before(active[0].Started) {
+		t.Errorf("expected a heartbeat after the run started")
+	}
+
+	close(releaseCh)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	assertEqual(t, len(sj.ActiveRuns()), 0)
+	rt := sj.Runtimes()[0]
+	assertEqual(t, rt.RunID, active[0].RunID)
+	assertEqual(t, string(rt.Checkpoint), "page=3")
+	assertEqual(t, rt.LastHeartbeat, active[0].LastHeartbeat)
+}
+
+func TestJobPanic(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s, err := New(Yearly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	errBoom := errors.New("boom")
+	job := ScheduleFunc(
+		ctx, s, ScheduledJobOptions{},
+		func(dt time.Time) error {
+			panic(errBoom)
+		},
+	)
+	defer job.Stop(ctx)
+
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(job.Runtimes()) == 1
+		},
+	)
+	rt := job.Runtimes()[0]
+	var panicErr *PanicError
+	if !errors.As(rt.Error, &panicErr) {
+		t.Fatalf("expected a PanicError, got %v", rt.Error)
+	}
+	if !errors.Is(rt.Error, errBoom) {
+		t.Errorf("expected the panic value to be unwrapped")
+	}
+	assertEqual(t, rt.Error.Error(), "job panicked: boom")
+	assertEqual(t, job.Failures.Load(), 1)
+	assertEqual(t, job.State(), ScheduleStarted)
+}
@@ -8,7 +8,9 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 )
 
 const (
@@ -20,6 +22,17 @@ const (
 	Step          = '/'
 	Blank         = '?'
 	Last          = 'L'
+	Week          = 'W'
+	Nth           = '#'
+
+	// Hash is replaced by a value derived from the key set with
+	// WithHashKey, as in Jenkins: "H" alone is a value in the
+	// field's range (1-28 in the day field), "H(0-7)" is a value
+	// in the given range, and "H/15" (or "H(0-29)/10") is a step
+	// starting at a value within the first step. String returns
+	// the expression with the values filled in, so it can be
+	// parsed without the key.
+	Hash = 'H'
 
 	// Cron macros
 
@@ -31,6 +44,38 @@ const (
 	Midnight = "@midnight"
 	Hourly   = "@hourly"
 
+	// At is a one-shot macro, followed by an RFC 3339 timestamp
+	// (ex: "@at 2025-07-01T09:00:00Z"). If the timestamp has no UTC
+	// offset (ex: "@at 2025-07-01T09:00:00"), it's interpreted in the
+	// schedule's location. A leap second (ex: 23:59:60) is treated as
+	// the first second of the next minute.
+	At = "@at"
+
+	// Every is a fixed-interval macro, followed by a whole number of
+	// seconds in time.ParseDuration syntax (ex: "@every 90s" or
+	// "@every 4h30m"). Occurrences are multiples of the interval since
+	// the Unix epoch, so they don't depend on when the schedule was
+	// created, or its location. Its fields are wildcards.
+	Every = "@every"
+
+	// Reboot is a startup macro, as in crontab: a [ScheduledJob] with
+	// the schedule runs once, when it's started. The schedule itself
+	// has no scheduled times, so Next returns the zero time, and its
+	// fields are wildcards.
+	Reboot = "@reboot"
+
+	// CronTZ is a prefix setting the schedule's location, followed by
+	// "=", an IANA time zone name and the expression, as in cronie and
+	// robfig/cron (ex: "CRON_TZ=America/New_York 0 9 * * MON-FRI").
+	// It overrides the location given to New. TZ is accepted as well.
+	// String returns the expression without the prefix (see
+	// [Schedule.Location]).
+	CronTZ = "CRON_TZ"
+
+	// TZ is an alternative to the [CronTZ] prefix
+	// (ex: "TZ=Europe/London 0 9 * * *")
+	TZ = "TZ"
+
 	// String representations for weekdays
 
 	Sunday    = "SUN"
@@ -57,6 +102,20 @@ const (
 	December  = "DEC"
 )
 
+// maxNextSteps is the maximum number of field-advance steps
+// taken when searching for the next scheduled time
+const maxNextSteps = 100_000
+
+var (
+	// ErrUnreachableSchedule is returned when no upcoming scheduled
+	// time can be found for a schedule (ex: "0 0 30 2 *")
+	ErrUnreachableSchedule = errors.New("schedule has no reachable occurrence")
+
+	// ErrScheduleExhausted is returned when a one-shot (@at)
+	// schedule has no upcoming scheduled time
+	ErrScheduleExhausted = errors.New("schedule has no more occurrences")
+)
+
 // cron expression positions
 const (
 	minuteInd int = iota
@@ -66,6 +125,10 @@ const (
 	weekdayInd
 )
 
+// secondInd is the index used for the seconds field, which
+// isn't one of the standard cron expression positions
+const secondInd int = -1
+
 // weekday indices
 const (
 	sundayInd int = iota
@@ -169,6 +232,11 @@ var (
 			59,
 		},
 	}
+	secondOpts = field{
+		Name:    "second",
+		Index:   secondInd,
+		Allowed: minuteOpts.Allowed,
+	}
 	hourOpts = field{
 		Name:  "hour",
 		Index: hourInd,
@@ -290,6 +358,32 @@ var (
 			Saturday:  saturdayInd,
 		},
 	}
+	// vixieWeekdayOpts is the weekday field as it's parsed, which
+	// also accepts 7 for Sunday, as Vixie cron does (ex: "1-7" is
+	// Monday to Sunday)
+	vixieWeekdayOpts = field{
+		Name:        "weekday",
+		Index:       weekdayInd,
+		Allowed:     []int{0, 1, 2, 3, 4, 5, 6, 7},
+		Conversions: weekdayOpts.Conversions,
+		Aliases:     map[int]int{7: sundayInd},
+	}
+	// isoWeekdayOpts is the weekday field parsed with
+	// WithISOWeekdays, numbered from 1 (Monday) to 7 (Sunday)
+	isoWeekdayOpts = field{
+		Name:    "weekday",
+		Index:   weekdayInd,
+		Allowed: []int{1, 2, 3, 4, 5, 6, 7},
+		Conversions: map[string]int{
+			Monday:    1,
+			Tuesday:   2,
+			Wednesday: 3,
+			Thursday:  4,
+			Friday:    5,
+			Saturday:  6,
+			Sunday:    7,
+		},
+	}
 	// cronShortcut is a map of cron macros to their
 	// respective cron expressions
 	cronShortcut = map[string]string{
@@ -323,6 +417,12 @@ var (
 //	if s.Matches(time.Now()) {
 //		fmt.Println("It's time!")
 //	}
+//
+// # Concurrency
+//
+// A Schedule isn't modified after it's created (methods like
+// WithHour return a new Schedule), so a single Schedule is safe
+// for concurrent use by any number of goroutines.
 type Schedule struct {
 	// values holds the parsed cron expression
 	values [5]string
@@ -333,6 +433,48 @@ type Schedule struct {
 	// created is the time this cron schedule was initialized
 	created time.Time
 
+	// at is the time a one-shot (@at) schedule fires, and is
+	// zero for recurring schedules
+	at time.Time
+
+	// every is the interval of a fixed-interval (@every)
+	// schedule, and is zero for other schedules
+	every time.Duration
+
+	// reboot is true for a startup (@reboot) schedule,
+	// which has no scheduled times
+	reboot bool
+
+	// notBefore and notAfter bound the schedule's validity
+	// window, if set (see WithNotBefore and WithNotAfter)
+	notBefore time.Time
+	notAfter  time.Time
+
+	// options are the options the schedule was parsed with
+	options parseOptions
+
+	// expr is the canonical expression returned by String,
+	// set when the schedule is validated
+	expr string
+
+	// macro is the macro the schedule was created from
+	// (ex: "@daily"), if any
+	macro string
+
+	// bounds holds the smallest and largest values of each
+	// field, indexed by field position
+	bounds [5]fieldBounds
+
+	// warnings holds the problems found by lint checks
+	// set to LintWarn
+	warnings []*FieldError
+
+	// second is the string value of the seconds field, and is
+	// only set when parsed with WithSeconds
+	second string
+	// seconds is the parsed values of the seconds field
+	seconds []int
+
 	// minute is the string value of the minute field
 	minute string
 	// minutes is the parsed values of the minute field
@@ -354,6 +496,16 @@ type Schedule struct {
 	days []int
 	// allowAnyDay indicates a wildcard day
 	allowAnyDay bool
+	// lastDay indicates the day field includes "L", the
+	// last day of the month
+	lastDay bool
+	// weekOfYearSet holds the ISO 8601 weeks of the year
+	// included in the day field (ex: "WY10")
+	weekOfYearSet valueSet
+	// nearestWeekdaySet holds the days whose nearest weekday is
+	// included in the day field (ex: 15 for "15W"), with 0 for
+	// "LW", the last weekday of the month
+	nearestWeekdaySet valueSet
 
 	// month is the string value of the month field
 	month string
@@ -368,24 +520,128 @@ type Schedule struct {
 	weekdays []int
 	// allowAnyWeekday indicates a wildcard weekday
 	allowAnyWeekday bool
+	// union indicates days matching either the day or the weekday
+	// field are included, rather than both (see WithDomDowUnion)
+	union bool
+	// lastWeekdaySet holds the weekdays whose last occurrence
+	// in the month is included (ex: 5 for "5L")
+	lastWeekdaySet valueSet
+	// nthWeekdaySet holds the nth occurrences of weekdays in the
+	// month that are included (ex: "5#3"), as nthWeekdayBit bits
+	nthWeekdaySet valueSet
+
+	// sets hold the parsed values of each field, for matching
+	// times without scanning the value slices
+	secondSet  valueSet
+	minuteSet  valueSet
+	hourSet    valueSet
+	daySet     valueSet
+	monthSet   valueSet
+	weekdaySet valueSet
 }
 
 // New creates a new Schedule from a cron expression. loc is the
-// location to use for the schedule (if nil, defaults to time.UTC)
-func New(cron string, loc *time.Location) (*Schedule, error) {
+// location to use for the schedule (if nil, defaults to time.UTC),
+// unless the expression has a [CronTZ] prefix.
+// opts can be provided to change how the expression is parsed.
+func New(cron string, loc *time.Location, opts ...ParseOption) (*Schedule, error) {
+	return new(Schedule).parse(cron, loc, opts)
+}
+
+// schedulePool holds schedules reused by Validate
+var schedulePool = sync.Pool{
+	New: func() any {
+		return new(Schedule)
+	},
+}
+
+// Validate checks a cron expression as [New] does, returning the same
+// errors, without returning a Schedule. It reuses schedules from a
+// pool, so it allocates less than New, for workloads that check many
+// expressions and discard the results (ex: validating requests).
+func Validate(cron string, opts ...ParseOption) error {
+	s := schedulePool.Get().(*Schedule)
+	defer schedulePool.Put(s)
+	// drop references to the parsed fields, so they can be collected
+	defer s.reset(nil)
+	_, err := s.parse(cron, nil, opts)
+	return err
+}
+
+// reset clears the schedule, keeping only the given location
+func (s *Schedule) reset(loc *time.Location) {
+	*s = Schedule{loc: loc}
+}
+
+// parse resets the schedule, then parses the given expression into it
+// as described by New, returning the schedule (or nil, if the
+// expression couldn't be split into fields)
+func (s *Schedule) parse(
+	cron string,
+	loc *time.Location,
+	opts []ParseOption,
+) (*Schedule, error) {
 	if loc == nil {
 		loc = time.UTC
 	}
 
-	s := &Schedule{values: [5]string{}, loc: loc}
+	s.reset(loc)
+	for _, opt := range opts {
+		opt(&s.options)
+	}
 	s.created = time.Now().In(s.loc)
+	if err := s.options.checkLength(cron); err != nil {
+		return nil, err
+	}
+	if s.options.mode == ParseLenient {
+		cron = lenientExpr(cron)
+	}
 	cron = strings.TrimSpace(cron)
+	tz, cron, err := cutTZ(cron)
+	if err != nil {
+		return nil, err
+	}
+	if tz != nil {
+		s.loc = tz
+		s.created = s.created.In(tz)
+	}
+	if ts, ok := strings.CutPrefix(cron, At+" "); ok {
+		return newAt(s, strings.TrimSpace(ts))
+	}
+	if ds, ok := strings.CutPrefix(cron, Every+" "); ok {
+		return newEvery(s, strings.TrimSpace(ds))
+	}
+	if cron == Reboot {
+		return newReboot(s)
+	}
 	cs, ok := cronShortcut[cron]
 	if ok {
+		s.macro = cron
 		cron = cs
+		if s.options.strictBlank {
+			cron = blankMacro(cron)
+		}
+		if s.options.isoWeekdays {
+			cron = isoMacro(cron)
+		}
 	}
 
 	values := strings.Split(cron, " ")
+	if s.options.seconds {
+		if ok {
+			values = append([]string{"0"}, values...)
+		}
+		if len(values) != 6 {
+			return nil, fmt.Errorf(
+				"invalid cron schedule '%s' (expected 6 values, got %d): %s",
+				cron,
+				len(values),
+				cron,
+			)
+		}
+		s.second = values[0]
+		values = values[1:]
+	}
 	if len(values) != 5 {
 		return nil, fmt.Errorf(
 			"invalid cron schedule '%s' (expected 5 values, got %d): %s",
@@ -398,10 +654,82 @@ func New(cron string, loc *time.Location) (*Schedule, error) {
 		s.values[i] = v
 	}
 
-	err := s.validate()
+	err = s.validate()
 	return s, err
 }
 
+// cutTZ splits a CRON_TZ (or TZ) prefix from the expression,
+// returning the prefix's location (or nil, if there's no prefix)
+// and the rest of the expression
+func cutTZ(cron string) (*time.Location, string, error) {
+	for _, prefix := range []string{CronTZ, TZ} {
+		spec, found := strings.CutPrefix(cron, prefix+"=")
+		if !found {
+			continue
+		}
+		name, rest, _ := strings.Cut(spec, " ")
+		if name == "" {
+			return nil, "", fmt.Errorf("empty %s time zone", prefix)
+		}
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid %s time zone '%s': %w", prefix, name, err)
+		}
+		return loc, strings.TrimSpace(rest), nil
+	}
+	return nil, cron, nil
+}
+
+// newAt finishes a one-shot (@at) schedule firing at the given timestamp
+func newAt(s *Schedule, ts string) (*Schedule, error) {
+	at, err := parseAt(ts, s.loc)
+	if err != nil {
+		// time can't represent a leap second (ex: 23:59:60), so
+		// it's treated as the first second of the next minute
+		leap, ok := strings.CutPrefix(ts[min(len(ts), 17):], "60")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s timestamp '%s': %w", At, ts, err)
+		}
+		at, err = parseAt(ts[:17]+"59"+leap, s.loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s timestamp '%s': %w", At, ts, err)
+		}
+		at = at.Add(time.Second)
+	}
+	s.at = at.In(s.loc)
+
+	// the fields describe the minute the schedule fires, so the
+	// field accessors and Matches work as they do for other schedules
+	s.values = [5]string{
+		strconv.Itoa(s.at.Minute()),
+		strconv.Itoa(s.at.Hour()),
+		strconv.Itoa(s.at.Day()),
+		strconv.Itoa(int(s.at.Month())),
+		string(Any),
+	}
+	if s.options.seconds {
+		s.second = strconv.Itoa(s.at.Second())
+	}
+	return s, s.validate()
+}
+
+// newReboot finishes a startup (@reboot) schedule
+func newReboot(s *Schedule) (*Schedule, error) {
+	s.reboot = true
+	s.macro = Reboot
+	s.values = [5]string{
+		string(Any),
+		string(Any),
+		string(Any),
+		string(Any),
+		string(Any),
+	}
+	if s.options.seconds {
+		s.second = string(Any)
+	}
+	return s, s.validate()
+}
+
 // NewRandom creates a new Schedule with a random cron expression
 func NewRandom(r *rand.Rand) (string, error) {
 	if r == nil {
@@ -440,136 +768,338 @@ func NewRandom(r *rand.Rand) (string, error) {
 	return strings.Join(cronFields, " "), errors.Join(errs...)
 }
 
-// Next returns the next scheduled time after the given time
+// parseAt parses an @at timestamp, either in RFC 3339 format, or
+// without a UTC offset (ex: "2024-03-01T12:00:00") in loc
+func parseAt(ts string, loc *time.Location) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		var localErr error
+		at, localErr = time.ParseInLocation("2006-01-02T15:04:05", ts, loc)
+		if localErr != nil {
+			return time.Time{}, err
+		}
+	}
+	return at, nil
+}
+
+// Next returns the next scheduled time after the given time.
+// For a one-shot (@at) schedule, the zero time is returned once
+// the given time isn't before the scheduled time. The zero time
+// is also returned if the schedule can never occur (see NextErr),
+// or the next scheduled time is after the schedule's NotAfter time.
+// Before the schedule's NotBefore time, the first scheduled time at
+// or after it is returned.
 func (s *Schedule) Next(t time.Time) time.Time {
+	return s.windowed(s.next(s.clampNotBefore(t)))
+}
+
+// next does the same thing as Next, without the schedule's
+// validity window
+func (s *Schedule) next(t time.Time) time.Time {
+	if s.reboot {
+		return time.Time{}
+	}
+	if !s.at.IsZero() {
+		if t.Before(s.at) {
+			return s.at
+		}
+		return time.Time{}
+	}
+	if s.every > 0 {
+		return s.everyFloor(t).Add(s.every)
+	}
+	if s.options.seconds {
+		next, _ := s.nextSecond(t.In(s.loc))
+		return next
+	}
 	return s.nextNoTruncate(t.In(s.loc).Truncate(time.Minute))
 }
 
-// Prev returns the previous scheduled time before the given time
+// Prev returns the previous scheduled time before the given time.
+// For a one-shot (@at) schedule, the zero time is returned if the
+// given time isn't after the scheduled time. The zero time is also
+// returned if the schedule can never occur, or the previous scheduled
+// time is before the schedule's NotBefore time. After the schedule's
+// NotAfter time, the latest scheduled time at or before it is returned.
 func (s *Schedule) Prev(t time.Time) time.Time {
-	t = t.In(s.loc).Truncate(time.Minute)
-	for {
-		t = t.Add(-time.Minute)
-		if s.Matches(t) {
-			return t
+	if !s.notAfter.IsZero() && t.After(s.notAfter) {
+		return s.Floor(s.notAfter)
+	}
+	return s.windowed(s.prev(t))
+}
+
+// prev does the same thing as Prev, without the schedule's
+// validity window
+func (s *Schedule) prev(t time.Time) time.Time {
+	if s.reboot {
+		return time.Time{}
+	}
+	if !s.at.IsZero() {
+		if t.After(s.at) {
+			return s.at
+		}
+		return time.Time{}
+	}
+	if s.every > 0 {
+		if prev := s.everyFloor(t); prev.Before(t) {
+			return prev
+		}
+		return s.everyFloor(t).Add(-s.every)
+	}
+	if s.options.seconds {
+		prev, _ := s.prevSecond(t.In(s.loc))
+		return prev
+	}
+	prev, _ := s.seek(t.In(s.loc).Truncate(time.Minute).Add(-time.Minute), false)
+	return prev
+}
+
+// Floor returns the latest scheduled time at or before the given time
+// (ex: 12:00 for 12:07:30 with "*/15 * * * *"), which is the run an
+// event at that time belongs to. Unlike Prev, a scheduled time is its
+// own floor. The zero time is returned if there's no such time within
+// the schedule's validity window (see [Schedule.WithNotBefore]).
+func (s *Schedule) Floor(t time.Time) time.Time {
+	if !s.notAfter.IsZero() && t.After(s.notAfter) {
+		t = s.notAfter
+	}
+	return s.windowed(s.floor(t))
+}
+
+// floor does the same thing as Floor, without the schedule's
+// validity window
+func (s *Schedule) floor(t time.Time) time.Time {
+	if s.reboot {
+		return time.Time{}
+	}
+	if !s.at.IsZero() {
+		if t.Before(s.at) {
+			return time.Time{}
+		}
+		return s.at
+	}
+	if s.every > 0 {
+		return s.everyFloor(t)
+	}
+	floor := t.In(s.loc).Truncate(s.resolution())
+	if s.MatchesSecond(floor) {
+		return floor
+	}
+	return s.Prev(floor)
+}
+
+// Ceil returns the earliest scheduled time at or after the given
+// time. Unlike Next, a scheduled time is its own ceiling. The zero
+// time is returned if there's no such time within the schedule's
+// validity window (see [Schedule.WithNotBefore]).
+func (s *Schedule) Ceil(t time.Time) time.Time {
+	if !s.notBefore.IsZero() && t.Before(s.notBefore) {
+		t = s.notBefore
+	}
+	return s.windowed(s.ceil(t))
+}
+
+// ceil does the same thing as Ceil, without the schedule's
+// validity window
+func (s *Schedule) ceil(t time.Time) time.Time {
+	if s.reboot {
+		return time.Time{}
+	}
+	if !s.at.IsZero() {
+		if t.After(s.at) {
+			return time.Time{}
+		}
+		return s.at
+	}
+	if s.every > 0 {
+		if floor := s.everyFloor(t); floor.Equal(t) {
+			return floor
+		}
+		return s.Next(t)
+	}
+	t = t.In(s.loc)
+	if t.Equal(t.Truncate(s.resolution())) && s.MatchesSecond(t) {
+		return t
+	}
+	return s.Next(t)
+}
+
+// NextDue returns the next scheduled time after the occurrence that
+// last fired at lastFired, which may be the occurrence's scheduled time
+// or the time the run actually started (ex: 12:00:05 for a 12:00
+// occurrence). A run that fired early, up to tolerance before a
+// scheduled time (see TickerOptions.Tolerance), is treated as having
+// fired for that scheduled time. The result may be in the past, if
+// occurrences were missed (ex: across a restart). For a schedule that
+// hasn't fired yet, use Next with the current time instead.
+func (s *Schedule) NextDue(lastFired time.Time, tolerance time.Duration) time.Time {
+	fired := lastFired
+	if next := s.Next(lastFired); !next.IsZero() && !next.After(lastFired.Add(tolerance)) {
+		fired = next
+	}
+	return s.Next(fired)
+}
+
+// NextErr returns the next scheduled time after the given time, as
+// with Next. If no scheduled time can be found within a bounded number
+// of steps (ex: "0 0 30 2 *", which never occurs), it returns
+// [ErrUnreachableSchedule] rather than searching indefinitely. This
+// should be preferred over Next for expressions from untrusted sources.
+// For a one-shot (@at) schedule that has already fired, or once the
+// next scheduled time is after the schedule's NotAfter time,
+// [ErrScheduleExhausted] is returned.
+func (s *Schedule) NextErr(t time.Time) (time.Time, error) {
+	next, err := s.unboundedNextErr(s.clampNotBefore(t))
+	if err == nil && s.windowed(next).IsZero() {
+		return time.Time{}, ErrScheduleExhausted
+	}
+	return next, err
+}
+
+// unboundedNextErr does the same thing as NextErr, without the
+// schedule's validity window
+func (s *Schedule) unboundedNextErr(t time.Time) (time.Time, error) {
+	if s.reboot {
+		return time.Time{}, ErrScheduleExhausted
+	}
+	if !s.at.IsZero() {
+		if t.Before(s.at) {
+			return s.at, nil
 		}
+		return time.Time{}, ErrScheduleExhausted
+	}
+	if s.every > 0 {
+		return s.Next(t), nil
+	}
+	if s.options.seconds {
+		return s.nextSecond(t.In(s.loc))
 	}
+	return s.nextErr(t.In(s.loc).Truncate(time.Minute))
 }
 
 // nextNoTruncate does the same thing as Next, but assumes
 // that the given time had already been truncated to the minute
 // and does not truncate it again
 func (s *Schedule) nextNoTruncate(t time.Time) time.Time {
-	// Given we already know all the months/days/weekdays/hours/minutes
-	// in the schedule, there's probably a more efficient or clever
-	// way to do a lot of this. For now, I'll stick to checking
-	// for the 'heavy' calls (@yearly, @monthly), and increment
-	// the rest. Adding a minute is a cheap operation, and
-	// I feel like hourly/daily schedules are probably the
-	// most common
-
-	switch cronExpr := s.String(); cronExpr {
-	case cronShortcut[Yearly]:
-		// if the schedule is yearly, we can just add a year
-		// to the given time and return it
-		return time.Date(
-			t.Year()+1,
-			time.Month(januaryInd),
-			1,
-			0,
-			0,
-			0,
-			0,
-			t.Location(),
-		)
-	case Monthly:
-		if int(t.Month()) == decemberInd {
-			return time.Date(
-				t.Year()+1,
-				time.Month(januaryInd),
-				1,
-				0,
-				0,
-				0,
-				0,
-				t.Location(),
-			)
+	next, _ := s.nextErr(t)
+	return next
+}
+
+// nextErr does the same thing as NextErr, but assumes that the
+// given time has already been truncated to the minute
+func (s *Schedule) nextErr(t time.Time) (time.Time, error) {
+	if !s.at.IsZero() {
+		if t.Before(s.at) {
+			return s.at, nil
+		}
+		return time.Time{}, ErrScheduleExhausted
+	}
+
+	return s.seek(t.Add(time.Minute), true)
+}
+
+// seek returns the first scheduled time at or after the given time
+// (or at or before it, if forward is false), which is assumed to
+// have already been truncated to the minute. Each step jumps to the
+// nearest boundary of the first mismatched field (the start of the
+// next month, day or hour when moving forward, or the last minute
+// of the previous one when moving backward), or by a minute if only
+// the minute doesn't match. An unreachable schedule spends a step
+// on (roughly) each day, so maxNextSteps covers centuries.
+func (s *Schedule) seek(t time.Time, forward bool) (time.Time, error) {
+	for range maxNextSteps {
+		var next time.Time
+		switch {
+		case !s.isMonth(t):
+			next = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			if !forward {
+				next = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+			}
+		case !s.isScheduledDay(t):
+			next = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			if !forward {
+				next = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+			}
+		case !s.isHour(t):
+			next = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			if !forward {
+				next = t.Add(-time.Duration(t.Minute()+1) * time.Minute)
+			}
+			// past the last (or before the first) scheduled
+			// hour, so nothing else matches today
+			switch {
+			case forward && t.Hour() > s.bounds[hourInd].max:
+				next = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			case !forward && t.Hour() < s.bounds[hourInd].min:
+				next = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+			}
+		case !s.isMinute(t):
+			next = t.Add(time.Minute)
+			if !forward {
+				next = t.Add(-time.Minute)
+			}
+			switch {
+			case forward && t.Minute() > s.bounds[minuteInd].max:
+				next = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			case !forward && t.Minute() < s.bounds[minuteInd].min:
+				next = t.Add(-time.Duration(t.Minute()+1) * time.Minute)
+			}
+		default:
+			return t, nil
+		}
+		// time.Date may normalize a time that doesn't exist (ex: a
+		// midnight skipped by a DST transition) to a time that isn't
+		// in the direction we're moving
+		switch {
+		case forward && !next.After(t):
+			next = t.Add(time.Minute)
+		case !forward && !next.Before(t):
+			next = t.Add(-time.Minute)
+		}
+		t = next
+	}
+	return time.Time{}, ErrUnreachableSchedule
+}
+
+// nextSecond returns the next scheduled time after the given
+// time for a schedule parsed with WithSeconds
+func (s *Schedule) nextSecond(t time.Time) (time.Time, error) {
+	t = t.Truncate(time.Second)
+	minute := t.Truncate(time.Minute)
+	if s.Matches(minute) {
+		for _, sec := range s.seconds {
+			if sec > t.Second() {
+				return minute.Add(time.Duration(sec) * time.Second), nil
+			}
 		}
-		return time.Date(
-			t.Year(),
-			t.Month()+1,
-			1,
-			0,
-			0,
-			0,
-			0,
-			t.Location(),
-		)
 	}
+	next, err := s.nextErr(minute)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return next.Add(time.Duration(s.seconds[0]) * time.Second), nil
+}
 
-	// if s.allowAnyMonth {
-	// 	maxMonth = decemberInd
-	// 	minMonth = januaryInd
-	// } else {
-	// 	maxMonth = slices.Max(s.months)
-	// 	minMonth = slices.Min(s.months)
-	// }
-
-	for {
-		// if !s.isMonth(t) {
-		// 	currentMonth := int(t.Month())
-		// 	if currentMonth < minMonth {
-		// 		t = time.Date(
-		// 			t.Year(),
-		// 			time.Month(minMonth),
-		// 			1,
-		// 			0,
-		// 			0,
-		// 			0,
-		// 			0,
-		// 			t.Location(),
-		// 		)
-		// 	} else if currentMonth > maxMonth {
-		// 		t = time.Date(
-		// 			t.Year()+1,
-		// 			time.Month(minMonth),
-		// 			1,
-		// 			0,
-		// 			0,
-		// 			0,
-		// 			0,
-		// 			t.Location(),
-		// 		)
-		// 	} else {
-		// 		var foundMonth int
-		// 		for _, m := range s.months {
-		// 			if m > currentMonth {
-		// 				foundMonth = m
-		// 				break
-		//
-		// 			}
-		// 		}
-		// 		if foundMonth == 0 {
-		// 			panic("couldn't find month")
-		// 		}
-		// 		t = time.Date(
-		// 			t.Year(),
-		// 			time.Month(foundMonth),
-		// 			1,
-		// 			0,
-		// 			0,
-		// 			0,
-		// 			0,
-		// 			t.Location(),
-		// 		)s
-		// 	}
-		// }
-
-		t = t.Add(time.Minute)
-		if s.Matches(t) {
-			return t
+// prevSecond returns the previous scheduled time before the given
+// time for a schedule parsed with WithSeconds
+func (s *Schedule) prevSecond(t time.Time) (time.Time, error) {
+	second := t.Truncate(time.Second)
+	if second.Equal(t) {
+		second = second.Add(-time.Second)
+	}
+	minute := second.Truncate(time.Minute)
+	if s.Matches(minute) {
+		for i := len(s.seconds) - 1; i >= 0; i-- {
+			if sec := s.seconds[i]; sec <= second.Second() {
+				return minute.Add(time.Duration(sec) * time.Second), nil
+			}
 		}
 	}
+	prev, err := s.seek(minute.Add(-time.Minute), false)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return prev.Add(time.Duration(s.seconds[len(s.seconds)-1]) * time.Second), nil
 }
 
 // UntilNext returns the duration until the next scheduled time
@@ -578,17 +1108,407 @@ func (s *Schedule) UntilNext(t time.Time) time.Duration {
 	return s.Next(t).Sub(t)
 }
 
-// Matches returns true if the schedule matches the given time
+// NextAny returns the earliest next scheduled time after t among
+// the given schedules, and the index of the schedule it belongs to.
+// Ties go to the lowest index. If none of the schedules have an
+// upcoming scheduled time, it returns the zero time and -1.
+func NextAny(t time.Time, schedules ...*Schedule) (time.Time, int) {
+	var earliest time.Time
+	index := -1
+	for i, next := range NextAll(t, schedules...) {
+		if next.IsZero() {
+			continue
+		}
+		if index == -1 || next.Before(earliest) {
+			earliest = next
+			index = i
+		}
+	}
+	return earliest, index
+}
+
+// NextAll returns the next scheduled time after t for each of the
+// given schedules, in the same order. Each entry is the zero time if
+// the schedule is nil or has no upcoming scheduled time.
+func NextAll(t time.Time, schedules ...*Schedule) []time.Time {
+	next := make([]time.Time, len(schedules))
+	for i, s := range schedules {
+		if s != nil {
+			next[i] = s.Next(t)
+		}
+	}
+	return next
+}
+
+// Matches returns true if the schedule matches the given time. Times
+// outside the schedule's validity window never match.
 func (s *Schedule) Matches(t time.Time) bool {
+	if s.reboot || s.windowed(t).IsZero() {
+		return false
+	}
+	if !s.at.IsZero() {
+		return t.In(s.loc).Truncate(time.Minute).Equal(s.at.Truncate(time.Minute))
+	}
+	if s.every > 0 {
+		// an occurrence falls in the minute
+		minute := t.In(s.loc).Truncate(time.Minute)
+		return s.Ceil(minute).Before(minute.Add(time.Minute))
+	}
 	// return s.isMinute(t) && s.isHour(t) && s.isDay(t) && s.isMonth(t) && s.isWeekday(t)
-	return s.isWeekday(t) && s.isMonth(t) && s.isDay(t) && s.isHour(t) && s.isMinute(t)
+	return s.isMonth(t) && s.isScheduledDay(t) && s.isHour(t) && s.isMinute(t)
+}
+
+// MatchesSecond returns true if the schedule matches the given time
+// to the second. Matches only considers the minute, so 12:00:30
+// matches "0 12 * * *". MatchesSecond also requires the second to be
+// scheduled: for a schedule parsed without WithSeconds, that's only
+// the first second of each scheduled minute. Fractions of a second
+// are ignored. Times outside the schedule's validity window never match.
+func (s *Schedule) MatchesSecond(t time.Time) bool {
+	if s.reboot || s.windowed(t).IsZero() {
+		return false
+	}
+	if !s.at.IsZero() {
+		return t.In(s.loc).Truncate(time.Second).Equal(s.at.Truncate(time.Second))
+	}
+	if s.every > 0 {
+		second := t.In(s.loc).Truncate(time.Second)
+		return s.everyFloor(second).Equal(second)
+	}
+	return s.isSecond(t) && s.Matches(t)
+}
+
+// MatchesWithin returns true if the schedule matches the given time,
+// or if the time is within tolerance before a scheduled minute. This
+// tolerates clocks that land slightly short of a minute boundary
+// (ex: 11:59:59.8 matches a schedule of "0 12 * * *" with a
+// tolerance of one second).
+func (s *Schedule) MatchesWithin(t time.Time, tolerance time.Duration) bool {
+	matches := s.Matches
+	if s.options.seconds {
+		matches = s.MatchesSecond
+	}
+	if matches(t) {
+		return true
+	}
+	res := s.resolution()
+	limit := t.Add(tolerance)
+	for m := t.Truncate(res).Add(res); !m.After(limit); m = m.Add(res) {
+		if matches(m) {
+			return true
+		}
+	}
+	return false
 }
 
-// String returns the string representation of the schedule
+// String returns the string representation of the schedule. Parsing
+// it with the location and parse options the schedule was created
+// with always produces an equivalent schedule (see [CheckRoundTrip]),
+// so it can be stored in place of the original expression.
 func (s *Schedule) String() string {
+	return s.expr
+}
+
+// canonical builds the expression returned by String
+func (s *Schedule) canonical() string {
+	if !s.at.IsZero() {
+		return At + " " + s.at.Format(time.RFC3339Nano)
+	}
+	if s.every > 0 {
+		return Every + " " + s.every.String()
+	}
+	if s.reboot {
+		return Reboot
+	}
+	if s.options.seconds {
+		return s.second + " " + strings.Join(s.values[:], " ")
+	}
 	return strings.Join(s.values[:], " ")
 }
 
+// Macro returns the macro the schedule was created from (ex:
+// "@daily"), or an empty string if it wasn't created from one.
+// String returns the expression the macro expands to.
+func (s *Schedule) Macro() string {
+	return s.macro
+}
+
+// resolution returns the smallest interval the schedule
+// distinguishes between (a second if parsed with WithSeconds, or
+// for an @every interval that isn't a whole number of minutes,
+// otherwise a minute)
+func (s *Schedule) resolution() time.Duration {
+	if s.options.seconds || s.every%time.Minute != 0 {
+		return time.Second
+	}
+	return time.Minute
+}
+
+// At returns the time a one-shot (@at) schedule fires, or
+// the zero time for recurring schedules
+func (s *Schedule) At() time.Time {
+	return s.at
+}
+
+// Every returns the interval of a fixed-interval (@every)
+// schedule, or 0 for other schedules
+func (s *Schedule) Every() time.Duration {
+	return s.every
+}
+
+// WithSecond returns a new Schedule with the seconds field replaced
+// by the given value. The schedule must have been parsed with
+// WithSeconds.
+func (s *Schedule) WithSecond(value string) (*Schedule, error) {
+	if !s.options.seconds {
+		return nil, errors.New("schedule has no seconds field (see WithSeconds)")
+	}
+	return s.with(func(ns *Schedule) {
+		ns.second = value
+	})
+}
+
+// WithMinute returns a new Schedule with the minute field
+// replaced by the given value (ex: "*/15")
+func (s *Schedule) WithMinute(value string) (*Schedule, error) {
+	return s.withField(minuteInd, value)
+}
+
+// WithHour returns a new Schedule with the hour field
+// replaced by the given value (ex: "*/2")
+func (s *Schedule) WithHour(value string) (*Schedule, error) {
+	return s.withField(hourInd, value)
+}
+
+// WithDay returns a new Schedule with the day field
+// replaced by the given value (ex: "1,15")
+func (s *Schedule) WithDay(value string) (*Schedule, error) {
+	return s.withField(dayInd, value)
+}
+
+// WithMonth returns a new Schedule with the month field
+// replaced by the given value (ex: "JAN-JUN")
+func (s *Schedule) WithMonth(value string) (*Schedule, error) {
+	return s.withField(monthInd, value)
+}
+
+// WithWeekday returns a new Schedule with the weekday field
+// replaced by the given value (ex: "MON-FRI")
+func (s *Schedule) WithWeekday(value string) (*Schedule, error) {
+	return s.withField(weekdayInd, value)
+}
+
+// Location returns the location the schedule is evaluated in
+func (s *Schedule) Location() *time.Location {
+	return s.loc
+}
+
+// WithLocation returns a copy of the schedule evaluated in the given
+// location (UTC, if nil). A one-shot (@at) schedule keeps its wall
+// clock time, which is re-interpreted in the new location.
+func (s *Schedule) WithLocation(loc *time.Location) *Schedule {
+	if loc == nil {
+		loc = time.UTC
+	}
+	ns := *s
+	ns.loc = loc
+	if !s.at.IsZero() {
+		at := s.at
+		ns.at = time.Date(
+			at.Year(), at.Month(), at.Day(),
+			at.Hour(), at.Minute(), at.Second(), 0,
+			loc,
+		)
+		ns.expr = ns.canonical()
+	}
+	return &ns
+}
+
+// WithNotBefore returns a copy of the schedule with no scheduled
+// times before t, keeping its NotAfter time. Along with WithNotAfter,
+// this bounds a recurring schedule to a validity window (ex: the
+// term of a contract), so that Next returns the zero time outside of
+// it. The zero time removes the bound. Summaries computed from the
+// schedule's fields (ex: Histogram and Fields) ignore the window.
+func (s *Schedule) WithNotBefore(t time.Time) *Schedule {
+	ns := *s
+	ns.notBefore = t
+	return &ns
+}
+
+// WithNotAfter returns a copy of the schedule with no scheduled times
+// after t, keeping its NotBefore time (see [Schedule.WithNotBefore]).
+// Once t has passed, Next returns the zero time, and a Ticker for the
+// schedule is exhausted. The zero time removes the bound.
+func (s *Schedule) WithNotAfter(t time.Time) *Schedule {
+	ns := *s
+	ns.notAfter = t
+	return &ns
+}
+
+// NotBefore returns the start of the schedule's validity window, or
+// the zero time if it isn't bounded (see [Schedule.WithNotBefore])
+func (s *Schedule) NotBefore() time.Time {
+	return s.notBefore
+}
+
+// NotAfter returns the end of the schedule's validity window, or
+// the zero time if it isn't bounded (see [Schedule.WithNotAfter])
+func (s *Schedule) NotAfter() time.Time {
+	return s.notAfter
+}
+
+// clampNotBefore returns t, or just before the schedule's NotBefore
+// time if t is earlier, so the next scheduled time after the result
+// is the first one at or after NotBefore
+func (s *Schedule) clampNotBefore(t time.Time) time.Time {
+	if !s.notBefore.IsZero() && t.Before(s.notBefore) {
+		return s.notBefore.Add(-time.Nanosecond)
+	}
+	return t
+}
+
+// windowed returns t if it's within the schedule's validity
+// window, or the zero time if it isn't
+func (s *Schedule) windowed(t time.Time) time.Time {
+	switch {
+	case t.IsZero():
+		return t
+	case !s.notBefore.IsZero() && t.Before(s.notBefore):
+		return time.Time{}
+	case !s.notAfter.IsZero() && t.After(s.notAfter):
+		return time.Time{}
+	}
+	return t
+}
+
+// ReloadLocation loads the given location again by name, picking up
+// changes to the time zone database since it was loaded (see
+// [time.LoadLocation]). UTC and Local can't be reloaded, and are
+// returned as-is.
+func ReloadLocation(loc *time.Location) (*time.Location, error) {
+	if loc == nil || loc == time.UTC || loc == time.Local {
+		return loc, nil
+	}
+	return time.LoadLocation(loc.String())
+}
+
+// withField returns a copy of the schedule with the
+// field at the given index replaced by value
+func (s *Schedule) withField(ind int, value string) (*Schedule, error) {
+	return s.with(func(ns *Schedule) {
+		ns.values[ind] = value
+	})
+}
+
+// with returns a copy of the schedule, with the same location and
+// parse options, after applying f to its fields and validating the
+// result. One-shot (@at), fixed-interval (@every) and startup
+// (@reboot) schedules can't be derived from.
+func (s *Schedule) with(f func(ns *Schedule)) (*Schedule, error) {
+	if !s.at.IsZero() {
+		return nil, fmt.Errorf("cannot override fields of an %s schedule", At)
+	}
+	if s.every > 0 {
+		return nil, fmt.Errorf("cannot override fields of an %s schedule", Every)
+	}
+	if s.reboot {
+		return nil, fmt.Errorf("cannot override fields of a %s schedule", Reboot)
+	}
+	ns := &Schedule{
+		values:    s.values,
+		loc:       s.loc,
+		created:   time.Now().In(s.loc),
+		options:   s.options,
+		second:    s.second,
+		notBefore: s.notBefore,
+		notAfter:  s.notAfter,
+	}
+	f(ns)
+	if err := ns.validate(); err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// EarliestTimeOfDay returns the earliest time of day the schedule
+// runs, as the duration since midnight (ex: 9h for "0 9-17 * * *").
+// For a one-shot (@at) schedule, it's the time of day it fires.
+func (s *Schedule) EarliestTimeOfDay() time.Duration {
+	if !s.at.IsZero() {
+		return s.atTimeOfDay()
+	}
+	d := time.Duration(s.bounds[hourInd].min)*time.Hour +
+		time.Duration(s.bounds[minuteInd].min)*time.Minute
+	if s.options.seconds {
+		d += time.Duration(s.seconds[0]) * time.Second
+	}
+	return d
+}
+
+// LatestTimeOfDay returns the latest time of day the schedule runs,
+// as the duration since midnight (ex: 17h45m for "*/15 9-17 * * *").
+// For a one-shot (@at) schedule, it's the time of day it fires.
+func (s *Schedule) LatestTimeOfDay() time.Duration {
+	if !s.at.IsZero() {
+		return s.atTimeOfDay()
+	}
+	d := time.Duration(s.bounds[hourInd].max)*time.Hour +
+		time.Duration(s.bounds[minuteInd].max)*time.Minute
+	if s.options.seconds {
+		d += time.Duration(s.seconds[len(s.seconds)-1]) * time.Second
+	}
+	return d
+}
+
+// EarliestTimeOfDayOn returns the earliest time of day the schedule
+// runs on the given weekday. If the weekday field excludes the given
+// weekday, ok is false. The day and month fields aren't considered,
+// so the schedule may still not run on every such weekday.
+func (s *Schedule) EarliestTimeOfDayOn(weekday time.Weekday) (d time.Duration, ok bool) {
+	if !s.runsOnWeekday(weekday) {
+		return 0, false
+	}
+	return s.EarliestTimeOfDay(), true
+}
+
+// LatestTimeOfDayOn returns the latest time of day the schedule runs
+// on the given weekday. As with EarliestTimeOfDayOn, ok is false if
+// the weekday field excludes the given weekday.
+func (s *Schedule) LatestTimeOfDayOn(weekday time.Weekday) (d time.Duration, ok bool) {
+	if !s.runsOnWeekday(weekday) {
+		return 0, false
+	}
+	return s.LatestTimeOfDay(), true
+}
+
+// atTimeOfDay returns the time of day a one-shot (@at) schedule
+// fires, including its seconds, as the duration since midnight
+func (s *Schedule) atTimeOfDay() time.Duration {
+	h, m, sec := s.at.Clock()
+	return time.Duration(h)*time.Hour +
+		time.Duration(m)*time.Minute +
+		time.Duration(sec)*time.Second +
+		time.Duration(s.at.Nanosecond())
+}
+
+// runsOnWeekday returns true if the weekday field includes
+// the given weekday
+func (s *Schedule) runsOnWeekday(weekday time.Weekday) bool {
+	if !s.at.IsZero() {
+		return s.at.Weekday() == weekday
+	}
+	// with the fields unioned, the day field can
+	// include any weekday
+	return s.union || s.allowAnyWeekday || slices.Contains(s.weekdays, int(weekday))
+}
+
+// Second returns the seconds value of the schedule, which
+// is empty unless it was parsed with WithSeconds
+func (s *Schedule) Second() string {
+	return s.second
+}
+
 // Minute returns the minute value of the schedule
 func (s *Schedule) Minute() string {
 	return s.values[minuteInd]
@@ -618,19 +1538,23 @@ func (s *Schedule) LogValue() slog.Value {
 	return slog.StringValue(s.String())
 }
 
+// isSecond returns true if the given time is a second included
+// in the schedule. Without a seconds field, only the first second
+// of the minute is included.
+func (s *Schedule) isSecond(t time.Time) bool {
+	if !s.options.seconds {
+		return t.Second() == 0
+	}
+	return s.secondSet.has(t.Second())
+}
+
 // isMinute returns true if the given time is a minute
 // included in the schedule
 func (s *Schedule) isMinute(t time.Time) bool {
 	if s.allowAnyMinute {
 		return true
 	}
-	m := t.Minute()
-	for _, includedMinute := range s.minutes {
-		if m == includedMinute {
-			return true
-		}
-	}
-	return false
+	return s.minuteSet.has(t.Minute())
 }
 
 // isHour returns true if the given time is an hour
@@ -639,46 +1563,70 @@ func (s *Schedule) isHour(t time.Time) bool {
 	if s.allowAnyHour {
 		return true
 	}
-	h := t.Hour()
-	for _, includedHour := range s.hours {
-		if h == includedHour {
-			return true
-		}
-	}
-	return false
+	return s.hourSet.has(t.Hour())
 }
 
 // isDay returns true if the given time is a day
 // included in the schedule. If "L" is used as
-// the day, it will be interpreted as the last
-// day of the month
+// the day (or a day list entry), it will be
+// interpreted as the last day of the month.
+// Weeks of the year (ex: "WY10") include each
+// day of the ISO 8601 week, and "W" after a day
+// (ex: "15W") includes the weekday nearest it.
 func (s *Schedule) isDay(t time.Time) bool {
 	if s.allowAnyDay {
 		return true
 	}
-	d := t.Day()
-	for _, includedDay := range s.days {
-		if d == includedDay {
+	if s.daySet.has(t.Day()) {
+		return true
+	}
+	if s.weekOfYearSet != 0 {
+		if _, week := t.ISOWeek(); s.weekOfYearSet.has(week) {
 			return true
 		}
 	}
+	if s.nearestWeekdaySet != 0 && s.isNearestWeekday(t) {
+		return true
+	}
 
-	if s.Day() == string(Last) {
-		targetMonth := t.Month() + 1
-		nextMonth := time.Date(
-			t.Year(),
-			targetMonth,
-			1,
-			0,
-			0,
-			0,
-			0,
-			t.Location(),
-		)
-		lastOfThisMonth := nextMonth.Add(-24 * time.Hour)
-		return lastOfThisMonth.Day() == t.Day()
+	// the month's length is found without stepping back a day from
+	// the next month, which is off by one if the last day of the
+	// month is shortened by a DST transition
+	return s.lastDay && t.Day() == DaysInMonth(t.Year(), t.Month())
+}
+
+// isScheduledDay returns true if the given time is on a day the
+// schedule runs: one included by both the day and weekday fields,
+// or by either, if they're unioned (see WithDomDowUnion)
+func (s *Schedule) isScheduledDay(t time.Time) bool {
+	if s.union {
+		return s.isDay(t) || s.isWeekday(t)
 	}
-	return false
+	return s.isDay(t) && s.isWeekday(t)
+}
+
+// restricted returns true if a day or weekday field value
+// restricts the days a schedule runs, as Vixie cron decides
+// whether to union them: it doesn't start with '*' or '?'
+func restricted(value string) bool {
+	return !strings.HasPrefix(value, string(Any)) &&
+		!strings.HasPrefix(value, string(Blank))
+}
+
+// isNearestWeekday returns true if the given time is the weekday
+// nearest one of the schedule's "W" days. The nearest weekday is in
+// the same month, and within two days of the day, so only those
+// days are checked. Months without the day (ex: "31W" in April)
+// are skipped.
+func (s *Schedule) isNearestWeekday(t time.Time) bool {
+	year, month, day := t.Date()
+	last := DaysInMonth(year, month)
+	for d := max(day-2, 1); d <= min(day+2, last); d++ {
+		if s.nearestWeekdaySet.has(d) && nearestWeekday(year, month, d) == day {
+			return true
+		}
+	}
+	return s.nearestWeekdaySet.has(0) && nearestWeekday(year, month, last) == day
 }
 
 // isMonth returns true if the given time is a month
@@ -687,34 +1635,47 @@ func (s *Schedule) isMonth(t time.Time) bool {
 	if s.allowAnyMonth {
 		return true
 	}
-	m := int(t.Month())
-	for _, includedMonth := range s.months {
-		if m == includedMonth {
-			return true
-		}
-	}
-	return false
+	return s.monthSet.has(int(t.Month()))
 }
 
 // isWeekday returns true if the given time is a weekday
-// included in the schedule
+// included in the schedule. A weekday followed by "L"
+// (ex: "5L") only includes its last occurrence in the
+// month, and one followed by "#" and n (ex: "5#3")
+// only its nth occurrence.
 func (s *Schedule) isWeekday(t time.Time) bool {
 	if s.allowAnyWeekday {
 		return true
 	}
-	w := int(t.Weekday())
-	for _, includedWeekday := range s.weekdays {
-		if w == includedWeekday {
-			return true
-		}
+	if s.weekdaySet.has(int(t.Weekday())) {
+		return true
 	}
-	return false
+	if s.nthWeekdaySet.has(nthWeekdayBit(int(t.Weekday()), (t.Day()-1)/7+1)) {
+		return true
+	}
+	// the last of a weekday is in the last seven days of the month
+	return s.lastWeekdaySet.has(int(t.Weekday())) &&
+		t.Day() > DaysInMonth(t.Year(), t.Month())-7
 }
 
 // validate checks the schedule for errors, and
 // assigns the parsed values to the schedule
 func (s *Schedule) validate() error {
-	errs := make([]error, 0, 5)
+	// list entries are counted before any field is parsed
+	verr := &ValidationError{Expression: s.canonical()}
+	if !s.validateListEntries(verr) {
+		return verr
+	}
+	if !s.expandHashes(verr) {
+		return verr
+	}
+	s.normalize()
+	s.expr = s.canonical()
+	if err := s.options.checkLength(s.expr); err != nil {
+		// String must parse with the same options
+		return err
+	}
+	verr.Expression = s.expr
 	var minutes []int
 	var hours []int
 	var days []int
@@ -725,13 +1686,18 @@ func (s *Schedule) validate() error {
 	anyStr := string(Any)
 	blankStr := string(Blank)
 
+	if s.options.seconds {
+		s.seconds, err = secondOpts.parse(s.Second())
+		verr.add(secondOpts, s.Second(), err)
+	}
+
 	switch ms := s.Minute(); ms {
 	case anyStr:
 		s.allowAnyMinute = true
 	default:
 		minutes, err = minuteOpts.parse(ms)
 		s.minutes = minutes
-		errs = append(errs, err)
+		verr.add(minuteOpts, ms, err)
 
 		revSlice := make([]int, len(minutes))
 		for i, j := 0, len(minutes)-1; i < j; i, j = i+1, j-1 {
@@ -746,7 +1712,7 @@ func (s *Schedule) validate() error {
 		s.allowAnyHour = true
 	default:
 		hours, err = hourOpts.parse(hs)
-		errs = append(errs, err)
+		verr.add(hourOpts, hs, err)
 		s.hours = hours
 	}
 
@@ -755,8 +1721,23 @@ func (s *Schedule) validate() error {
 		s.allowAnyDay = true
 	default:
 		days, err = dayOpts.parse(ds)
-		errs = append(errs, err)
+		verr.add(dayOpts, ds, err)
 		s.days = days
+		var weeks []int
+		var nearestDays []int
+		for _, entry := range strings.Split(ds, string(ListSeparator)) {
+			if strings.EqualFold(entry, string(Last)) {
+				s.lastDay = true
+			}
+			if week, ok := weekOfYear(entry); ok {
+				weeks = append(weeks, week)
+			}
+			if day, ok := nearestWeekdayDay(entry); ok {
+				nearestDays = append(nearestDays, day)
+			}
+		}
+		s.weekOfYearSet = newValueSet(weeks)
+		s.nearestWeekdaySet = newValueSet(nearestDays)
 	}
 
 	switch ms := s.Month(); ms {
@@ -764,7 +1745,7 @@ func (s *Schedule) validate() error {
 		s.allowAnyMonth = true
 	default:
 		months, err = monthOpts.parse(ms)
-		errs = append(errs, err)
+		verr.add(monthOpts, ms, err)
 		s.months = months
 	}
 
@@ -772,12 +1753,92 @@ func (s *Schedule) validate() error {
 	case string(Any), string(Blank):
 		s.allowAnyWeekday = true
 	default:
-		weekdays, err = weekdayOpts.parse(ws)
-		errs = append(errs, err)
+		wf := s.weekdayField()
+		weekdays, err = wf.parse(ws)
+		verr.add(wf, ws, err)
+		weekdays = wf.canonical(weekdays)
+		if s.options.isoWeekdays && err == nil {
+			weekdays = fromISOWeekdays(weekdays)
+		}
 		s.weekdays = weekdays
+		var lastWeekdays []int
+		var nthWeekdays []int
+		for _, entry := range strings.Split(ws, string(ListSeparator)) {
+			if weekday, ok := wf.lastWeekday(entry); ok {
+				lastWeekdays = append(lastWeekdays, weekday%7)
+			}
+			if weekday, n, ok := wf.nthWeekday(entry); ok {
+				nthWeekdays = append(nthWeekdays, nthWeekdayBit(weekday%7, n))
+			}
+		}
+		s.lastWeekdaySet = newValueSet(lastWeekdays)
+		s.nthWeekdaySet = newValueSet(nthWeekdays)
+	}
+
+	s.union = s.options.domDowUnion && restricted(s.Day()) && restricted(s.Weekday())
+
+	if s.options.strictBlank {
+		s.validateStrictBlank(verr)
+	}
+	if s.options.mode == ParseStrict {
+		s.validateStrict(verr)
+	}
+	s.validateRanges(verr)
+	s.lint(verr)
+
+	s.secondSet = newValueSet(s.seconds)
+	s.minuteSet = newValueSet(s.minutes)
+	s.hourSet = newValueSet(s.hours)
+	s.daySet = newValueSet(s.days)
+	s.monthSet = newValueSet(s.months)
+	s.weekdaySet = newValueSet(s.weekdays)
+
+	s.bounds = [5]fieldBounds{
+		minuteInd:  newFieldBounds(s.minutes, minuteOpts),
+		hourInd:    newFieldBounds(s.hours, hourOpts),
+		dayInd:     newFieldBounds(s.days, dayOpts),
+		monthInd:   newFieldBounds(s.months, monthOpts),
+		weekdayInd: newFieldBounds(s.weekdays, weekdayOpts),
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return s.checkValues()
+}
+
+// valueSet is a set of field values, which are all from 0 to 63
+type valueSet uint64
+
+// newValueSet returns a set of the given values
+func newValueSet(values []int) valueSet {
+	var set valueSet
+	for _, v := range values {
+		if v >= 0 && v < 64 {
+			set |= 1 << v
+		}
 	}
+	return set
+}
 
-	return errors.Join(errs...)
+// has returns true if v is in the set
+func (vs valueSet) has(v int) bool {
+	return v >= 0 && v < 64 && vs&(1<<v) != 0
+}
+
+// fieldBounds holds the smallest and largest values of a field
+type fieldBounds struct {
+	min int
+	max int
+}
+
+// newFieldBounds returns the bounds of the given (sorted) values,
+// or the field's bounds if there are none (ex: a wildcard)
+func newFieldBounds(values []int, f field) fieldBounds {
+	if len(values) == 0 {
+		return fieldBounds{min: f.Min(), max: f.Max()}
+	}
+	return fieldBounds{min: values[0], max: values[len(values)-1]}
 }
 
 // field defines a cron field
@@ -795,6 +1856,10 @@ type field struct {
 	// Conversions is a map of string values to their
 	// allowed int values (ex: "JAN" -> 1, "FEB" -> 2, etc.)
 	Conversions map[string]int
+
+	// Aliases maps allowed values that stand for other values to
+	// the values they stand for (ex: 7 -> 0, for Sunday)
+	Aliases map[int]int
 }
 
 // Min returns the minimum allowed value for the field
@@ -807,6 +1872,38 @@ func (f field) Max() int {
 	return f.Allowed[len(f.Allowed)-1]
 }
 
+// canonical returns the given values with aliases replaced by the
+// values they stand for, sorted and without duplicates
+func (f field) canonical(values []int) []int {
+	if f.Aliases == nil || values == nil {
+		return values
+	}
+	canonical := make([]int, len(values))
+	for i, v := range values {
+		if alias, ok := f.Aliases[v]; ok {
+			v = alias
+		}
+		canonical[i] = v
+	}
+	slices.Sort(canonical)
+	return slices.Compact(canonical)
+}
+
+// rangeEnd returns the end of a range from start, using the alias
+// for end if it's a name that comes before start (ex: 7 for
+// "FRI-SUN", since SUN is 0), and end otherwise
+func (f field) rangeEnd(start int, end int, named bool) int {
+	if !named || end >= start {
+		return end
+	}
+	for alias, v := range f.Aliases {
+		if v == end && alias > start {
+			return alias
+		}
+	}
+	return end
+}
+
 // error returns an error with a field-specific prefixed message
 func (f field) error(msg string) error {
 	return fmt.Errorf("invalid %s entry: %s", f.Name, msg)
@@ -817,10 +1914,28 @@ func (f field) wrapErr(err error) error {
 	return fmt.Errorf("invalid %s entry: %w", f.Name, err)
 }
 
+// atoi returns the integer value of s, and false if it isn't an
+// integer. Unlike strconv.Atoi, it doesn't allocate an error for
+// values that aren't integers, which are common while parsing
+// (ex: "*/15").
+func atoi(s string) (int, bool) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 || digits == "" {
+		return 0, false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
 // parse parses a string value for the field, returning
 // the parsed values (ints to trigger on) or an error
-func (f field) parse(s string) ([]int, error) {
-	var values []int
+func (f field) parse(s string) (values []int, err error) {
+	// values is a named result, so the compacted slice is returned
 	defer func() {
 		if values != nil {
 			slices.Sort(values)
@@ -858,8 +1973,8 @@ func (f field) parse(s string) ([]int, error) {
 
 	// if we successfully parse the string as an int, we
 	// don't have to worry about parsing steps, etc
-	m, err := strconv.Atoi(s)
-	if err == nil {
+	m, ok := atoi(s)
+	if ok {
 		switch {
 		case m < f.Min():
 			return nil, f.error(fmt.Sprintf("'%s' is less than %d", s, f.Min()))
@@ -879,10 +1994,21 @@ func (f field) parse(s string) ([]int, error) {
 		// the string
 		switch {
 		case strings.ContainsRune(s, ListSeparator):
+		case f.Index == weekdayInd && strings.ContainsRune(s, Nth):
+			return f.parseNth(s)
 		case strings.ContainsRune(s, Range):
 		case strings.ContainsRune(s, Step):
+		case f.Index == dayInd && strings.HasSuffix(s, string(Week)):
+			return f.parseNearestWeekday(s)
 		case strings.ContainsRune(s, Last):
+		case f.Index == dayInd && strings.HasPrefix(s, string(Week)):
+			if !strings.ContainsAny(s, string([]rune{Range, Step})) {
+				return f.parseWeek(s)
+			}
+		case f.Conversions != nil && strings.IndexFunc(s, unicode.IsLetter) == 0:
+			return nil, f.error(fmt.Sprintf("unknown name '%s'", s))
 		default:
+			_, err := strconv.Atoi(s)
 			return nil, f.wrapErr(err)
 		}
 	}
@@ -901,8 +2027,23 @@ func (f field) parse(s string) ([]int, error) {
 	// A minute expression would look like:
 	// */10 (every 10th minute, so 4:00, 4:10, 4:20...)
 	// 1-40/10 (every 10th minute from 1-50, so 4:01, 4:11, 4:21, 4:31)
-	// 5/10 (non-standard, interpreted as every 10th minute from 5-19, so 4:05, 4:15...)
+	// 5/10 (non-standard, interpreted as every 10th minute from 5-59, so 4:05, 4:15...)
+	// A step only applies to the list entry it follows, so 1,5,9/2
+	// is 1, 5, and every other minute from 9-59, and 1-10,20-30/5 is
+	// 1-10, 20, 25 and 30. Entries with more than one step or range
+	// (ex: 1-10/2/3, 1-5-10) are ambiguous, and are rejected.
+	if f.Index == dayInd && strings.ContainsRune(s, Week) {
+		return nil, f.error(
+			fmt.Sprintf("'%c' can't be used in a range or with a step", Week),
+		)
+	}
+	if strings.Count(s, string(Step)) > 1 {
+		return nil, f.error(fmt.Sprintf("'%s' has more than one step", s))
+	}
 	beforeStep, afterStep, stepFound := strings.Cut(s, string(Step))
+	if strings.Count(beforeStep, string(Range)) > 1 {
+		return nil, f.error(fmt.Sprintf("'%s' has more than one range", s))
+	}
 	if stepFound {
 		values, err = f.parseStep(beforeStep, afterStep)
 		return values, err
@@ -914,12 +2055,145 @@ func (f field) parse(s string) ([]int, error) {
 		return values, err
 	}
 
-	// the above cases may fall through in case of
-	// the "L" (Last) special character
+	// the above cases fall through for the "L" (Last) special
+	// character, which has no values of its own, since they
+	// depend on the month
+	switch {
+	case f.Index == weekdayInd:
+		if _, ok := f.lastWeekday(s); !ok {
+			return nil, f.error(fmt.Sprintf("invalid last weekday '%s'", s))
+		}
+	case f.Index != dayInd:
+		return nil, f.error(
+			fmt.Sprintf("'%c' is only allowed in the day and weekday fields", Last),
+		)
+	case s != string(Last):
+		return nil, f.error(fmt.Sprintf("invalid entry '%s'", s))
+	}
+	return nil, nil
+}
+
+// lastWeekday returns the weekday of a weekday field entry for the
+// last of that weekday in the month (ex: 5 for "5L" or "FRIL", the
+// last Friday), numbered as the field is
+func (f field) lastWeekday(entry string) (int, bool) {
+	weekday, found := strings.CutSuffix(strings.ToUpper(entry), string(Last))
+	if !found || weekday == "" ||
+		strings.ContainsAny(weekday, string([]rune{ListSeparator, Range, Step, Any, Blank, Last})) {
+		return 0, false
+	}
+	values, err := f.parse(weekday)
+	if err != nil || len(values) != 1 {
+		return 0, false
+	}
+	return values[0], true
+}
+
+// parseNth parses a weekday field entry for the nth of a weekday in
+// the month (ex: "5#3", the third Friday). Like "L", it has no values
+// of its own, since its days depend on the month.
+func (f field) parseNth(s string) ([]int, error) {
+	if _, _, ok := f.nthWeekday(s); !ok {
+		return nil, f.error(
+			fmt.Sprintf("invalid nth weekday '%s' (ex: 5%c3 for the third Friday)", s, Nth),
+		)
+	}
+	return nil, nil
+}
 
+// nthWeekday returns the weekday and n of a weekday field entry for
+// the nth of that weekday in the month (ex: 5 and 3 for "5#3" or
+// "FRI#3"), with the weekday numbered as the field is. n is 1-5.
+func (f field) nthWeekday(entry string) (weekday int, n int, ok bool) {
+	before, after, found := strings.Cut(strings.ToUpper(entry), string(Nth))
+	if !found || before == "" ||
+		strings.ContainsAny(before, string([]rune{Range, Step, Any, Blank, Last})) {
+		return 0, 0, false
+	}
+	n, ok = atoi(after)
+	if !ok || n < 1 || n > 5 {
+		return 0, 0, false
+	}
+	values, err := f.parse(before)
+	if err != nil || len(values) != 1 {
+		return 0, 0, false
+	}
+	return values[0], n, true
+}
+
+// nthWeekdayBit returns the bit for the nth (1-5) of a weekday
+// (0-6) in nthWeekdaySet
+func nthWeekdayBit(weekday int, n int) int {
+	return weekday*5 + n - 1
+}
+
+// parseNearestWeekday parses a day field entry for the weekday
+// (Monday to Friday) nearest a day of the month (ex: "15W"), or "LW",
+// the last weekday of the month. Like "L", these have no values of
+// their own, since their days depend on the month.
+func (f field) parseNearestWeekday(s string) ([]int, error) {
+	if _, ok := nearestWeekdayDay(s); !ok {
+		return nil, f.error(fmt.Sprintf("invalid nearest weekday '%s'", s))
+	}
+	return nil, nil
+}
+
+// nearestWeekdayDay returns the day of a "W" day field entry for the
+// nearest weekday (ex: 15 for "15W"), or 0 for "LW"
+func nearestWeekdayDay(entry string) (int, bool) {
+	day, found := strings.CutSuffix(strings.ToUpper(entry), string(Week))
+	if !found {
+		return 0, false
+	}
+	if day == string(Last) {
+		return 0, true
+	}
+	d, ok := atoi(day)
+	if !ok || d < dayOpts.Min() || d > dayOpts.Max() {
+		return 0, false
+	}
+	return d, true
+}
+
+// parseWeek parses a week token in the day field. "W1" through "W5"
+// are weeks of the month, starting on the 1st, 8th, 15th, 22nd and
+// 29th, so "W2" is days 8-14. "WY1" through "WY53" are ISO 8601 weeks
+// of the year, which (like "L") have no values of their own, since
+// their days depend on the year.
+func (f field) parseWeek(s string) ([]int, error) {
+	if _, ok := weekOfYear(s); ok {
+		return nil, nil
+	}
+	week, ok := atoi(strings.TrimPrefix(s, string(Week)))
+	if !ok || week < 1 || week > 5 {
+		return nil, f.error(
+			fmt.Sprintf(
+				"invalid week '%s' (W1-W5 for a week of the month, WY1-WY53 for a week of the year)",
+				s,
+			),
+		)
+	}
+	values := make([]int, 0, 7)
+	for day := (week-1)*7 + 1; day <= min(week*7, f.Max()); day++ {
+		values = append(values, day)
+	}
 	return values, nil
 }
 
+// weekOfYear returns the ISO 8601 week of a "WY" day field entry
+// (ex: 10 for "WY10")
+func weekOfYear(entry string) (int, bool) {
+	n, found := strings.CutPrefix(strings.ToUpper(entry), string(Week)+"Y")
+	if !found {
+		return 0, false
+	}
+	week, ok := atoi(n)
+	if !ok || week < 1 || week > 53 {
+		return 0, false
+	}
+	return week, true
+}
+
 // parseStep returns the values specified for the pre-delimiter
 // and post-delimiter step entry
 func (f field) parseStep(stepRange string, step string) ([]int, error) {
@@ -944,6 +2218,9 @@ func (f field) parseStep(stepRange string, step string) ([]int, error) {
 	if err != nil {
 		return nil, f.wrapErr(err)
 	}
+	if len(stepRangeValues) == 0 {
+		return nil, f.error(fmt.Sprintf("'%c' can't be used with a step", Last))
+	}
 
 	// Though non-standard, this accounts for cron entries
 	// like "5/10 * * * *" which is interpreted here as
@@ -975,6 +2252,16 @@ func (f field) parseRange(beforeRange string, afterRange string) (
 	if afterRange == "" {
 		return nil, f.error("empty end range")
 	}
+	for _, v := range []string{beforeRange, afterRange} {
+		switch v {
+		case string(Any), string(Blank):
+			return nil, f.error(
+				fmt.Sprintf("wildcard '%s' can't be used in a range", v),
+			)
+		case string(Last):
+			return nil, f.error(fmt.Sprintf("'%c' can't be used in a range", Last))
+		}
+	}
 
 	startMin, err := f.parse(beforeRange)
 	if err != nil {
@@ -999,7 +2286,8 @@ func (f field) parseRange(beforeRange string, afterRange string) (
 	}
 
 	startNum := startMin[0]
-	endNum := endMin[0]
+	_, named := f.Conversions[strings.ToUpper(afterRange)]
+	endNum := f.rangeEnd(startNum, endMin[0], named)
 
 	if startNum > endNum || startNum == endNum {
 		return nil, f.error(
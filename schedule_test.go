@@ -1,9 +1,12 @@
 package crong
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"slices"
+	"sync"
 	"testing"
 	"time"
 )
@@ -143,6 +146,9 @@ func TestCronSchedule(t *testing.T) {
 			nextTime: time.Date(
 				2023, 12, 1, 0, 0, 0, 0, time.UTC,
 			),
+			prevTime: time.Date(
+				2023, 11, 1, 0, 0, 0, 0, time.UTC,
+			),
 		},
 		{
 			name:           "yearly",
@@ -158,6 +164,9 @@ func TestCronSchedule(t *testing.T) {
 			nextTime: time.Date(
 				2024, 1, 1, 0, 0, 0, 0, time.UTC,
 			),
+			prevTime: time.Date(
+				2023, 1, 1, 0, 0, 0, 0, time.UTC,
+			),
 		},
 		{
 			name:           "hourly",
@@ -934,6 +943,32 @@ func BenchmarkRandomSchedules(b *testing.B) {
 	}
 }
 
+// benchmarkExprs are typical expressions for parsing benchmarks
+var benchmarkExprs = []string{
+	"*/15 9-17 * * MON-FRI",
+	"0 0 1,15 * *",
+	"30 2 * JAN-JUN SUN",
+	Daily,
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := New(benchmarkExprs[i%len(benchmarkExprs)], nil); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Validate(benchmarkExprs[i%len(benchmarkExprs)]); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
 func BenchmarkScheduleNext(b *testing.B) {
 	// cronExpr, err := NewRandom(rand.New(rand.NewSource(int64(1))))
 	// if err != nil {
@@ -1145,3 +1180,1522 @@ func TestNewRandom(t *testing.T) {
 		t.Fatalf("didn't see macro schedule")
 	}
 }
+
+func TestMatchesWithin(t *testing.T) {
+	s, err := New("0 12 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	type withinCase struct {
+		Time      time.Time
+		Tolerance time.Duration
+		Expect    bool
+	}
+	cases := []withinCase{
+		{
+			Time:   time.Date(2024, 2, 21, 12, 0, 30, 0, time.UTC),
+			Expect: true,
+		},
+		{
+			Time:   time.Date(2024, 2, 21, 11, 59, 59, 0, time.UTC),
+			Expect: false,
+		},
+		{
+			Time:      time.Date(2024, 2, 21, 11, 59, 59, 0, time.UTC),
+			Tolerance: time.Second,
+			Expect:    true,
+		},
+		{
+			Time:      time.Date(2024, 2, 21, 11, 59, 58, 0, time.UTC),
+			Tolerance: time.Second,
+			Expect:    false,
+		},
+		{
+			Time:      time.Date(2024, 2, 21, 11, 56, 30, 0, time.UTC),
+			Tolerance: 4 * time.Minute,
+			Expect:    true,
+		},
+		{
+			Time:      time.Date(2024, 2, 21, 12, 1, 0, 0, time.UTC),
+			Tolerance: time.Minute,
+			Expect:    false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			fmt.Sprintf("%s +%s", tc.Time.Format(time.TimeOnly), tc.Tolerance),
+			func(t *testing.T) {
+				assertEqual(t, s.MatchesWithin(tc.Time, tc.Tolerance), tc.Expect)
+			},
+		)
+	}
+
+	// a smeared clock can land just short of a scheduled second
+	s, err = New("0 0 12 * * *", nil, WithSeconds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	smeared := time.Date(2024, 2, 21, 11, 59, 59, 999_000_000, time.UTC)
+	assertEqual(t, s.MatchesWithin(smeared, 0), false)
+	assertEqual(t, s.MatchesWithin(smeared, time.Millisecond), true)
+	assertEqual(t, s.MatchesWithin(smeared.Add(-time.Second), time.Millisecond), false)
+}
+
+func TestAt(t *testing.T) {
+	s, err := New("@at 2025-07-01T09:00:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	assertEqual(t, s.At(), at)
+	assertEqual(t, s.String(), "@at 2025-07-01T09:00:00Z")
+	assertEqual(t, s.Minute(), "0")
+	assertEqual(t, s.Hour(), "9")
+	assertEqual(t, s.Day(), "1")
+	assertEqual(t, s.Month(), "7")
+
+	assertEqual(t, s.Next(at.Add(-24*time.Hour)), at)
+	assertEqual(t, s.Next(at.Add(-time.Second)), at)
+	assertEqual(t, s.Next(at), time.Time{})
+	assertEqual(t, s.Next(at.Add(time.Hour)), time.Time{})
+
+	assertEqual(t, s.Prev(at.Add(time.Hour)), at)
+	assertEqual(t, s.Prev(at), time.Time{})
+	assertEqual(t, s.Prev(at.Add(-time.Hour)), time.Time{})
+
+	assertEqual(t, s.Matches(at), true)
+	assertEqual(t, s.Matches(at.Add(30*time.Second)), true)
+	assertEqual(t, s.Matches(at.AddDate(1, 0, 0)), false)
+	assertEqual(t, s.Matches(at.Add(time.Minute)), false)
+
+	// one-shots don't repeat, so they have no fields, and the
+	// time of day includes the timestamp's seconds
+	if fields := s.Fields(); fields != nil {
+		t.Errorf("expected no fields, got %v", fields)
+	}
+	s, err = New("@at 2025-07-01T09:00:30Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.EarliestTimeOfDay(), 9*time.Hour+30*time.Second)
+	assertEqual(t, s.LatestTimeOfDay(), 9*time.Hour+30*time.Second)
+	d, ok := s.EarliestTimeOfDayOn(time.Tuesday)
+	assertEqual(t, ok, true)
+	assertEqual(t, d, 9*time.Hour+30*time.Second)
+	_, ok = s.LatestTimeOfDayOn(time.Wednesday)
+	assertEqual(t, ok, false)
+
+	// without an offset, the timestamp is in the schedule's location
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	s, err = New("@at 2025-07-01T09:00:00", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.At().Equal(at.Add(5*time.Hour)), true)
+
+	// a leap second is the first second of the next minute
+	s, err = New("@at 2016-12-31T23:59:60Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.At(), time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	s, err = New("@at 2016-12-31T23:59:60", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.At(), time.Date(2017, 1, 1, 0, 0, 0, 0, loc))
+
+	for _, cron := range []string{
+		"@at",
+		"@at tomorrow",
+		"@at 2025-13-01T09:00:00Z",
+		"@at 2016-12-31T23:59:61Z",
+		"@at 2016-12-31T23:60:60Z",
+	} {
+		if _, err = New(cron, nil); err == nil {
+			t.Errorf("expected error for %q", cron)
+		}
+	}
+}
+
+func TestNextErr(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := New("0 0 30 2 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next, err := s.NextErr(start)
+	if !errors.Is(err, ErrUnreachableSchedule) {
+		t.Fatalf("expected ErrUnreachableSchedule, got %v", err)
+	}
+	assertEqual(t, next, time.Time{})
+	assertEqual(t, s.Next(start), time.Time{})
+
+	// leap days are rare, but reachable
+	s, err = New("0 0 29 2 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next, err = s.NextErr(start)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, next, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC))
+
+	// leap days falling on a Monday are even rarer
+	s, err = New("30 12 29 2 MON", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next, err = s.NextErr(start)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, next, time.Date(2044, 2, 29, 12, 30, 0, 0, time.UTC))
+
+	s, err = New(At+" 2024-03-01T12:00:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = s.NextErr(start.Add(24 * time.Hour))
+	if !errors.Is(err, ErrScheduleExhausted) {
+		t.Fatalf("expected ErrScheduleExhausted, got %v", err)
+	}
+}
+
+// TestNextMatchesBruteForce compares Next against checking
+// every minute after the given time
+func TestNextMatchesBruteForce(t *testing.T) {
+	start := time.Date(2024, 2, 21, 11, 35, 0, 0, time.UTC)
+	horizon := start.AddDate(2, 0, 0)
+	for i := range 40 {
+		cronExpr, err := NewRandom(rand.New(rand.NewSource(int64(i))))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s, err := New(cronExpr, nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", cronExpr, err)
+		}
+
+		expected := time.Time{}
+		for m := start.Add(time.Minute); m.Before(horizon); m = m.Add(time.Minute) {
+			if s.Matches(m) {
+				expected = m
+				break
+			}
+		}
+		if expected.IsZero() {
+			continue
+		}
+		assertEqual(t, s.Next(start), expected)
+	}
+}
+
+func TestNextPrevMacros(t *testing.T) {
+	testCases := []struct {
+		cron  string
+		given time.Time
+		next  time.Time
+		prev  time.Time
+	}{
+		{
+			cron:  Yearly,
+			given: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			prev:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			cron:  Yearly,
+			given: time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC),
+			next:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			prev:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			cron:  Monthly,
+			given: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+			next:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			prev:  time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			cron:  Monthly,
+			given: time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+			next:  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			prev:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			cron:  Weekly,
+			given: time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC),
+			next:  time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+			prev:  time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			cron:  Hourly,
+			given: time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC),
+			next:  time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+			prev:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			cron:  "30 23 L * *",
+			given: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			next:  time.Date(2024, 3, 31, 23, 30, 0, 0, time.UTC),
+			prev:  time.Date(2024, 2, 29, 23, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %s", tc.cron, tc.given.Format(time.RFC3339)), func(t *testing.T) {
+			s, err := New(tc.cron, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			assertEqual(t, s.Next(tc.given), tc.next)
+			assertEqual(t, s.Prev(tc.given), tc.prev)
+		})
+	}
+}
+
+func TestPrevUnreachable(t *testing.T) {
+	s, err := New("0 0 31 4 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.Prev(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)), time.Time{})
+}
+
+// TestPrevMatchesBruteForce compares Prev against checking
+// every minute before the given time
+func TestPrevMatchesBruteForce(t *testing.T) {
+	start := time.Date(2024, 2, 21, 11, 35, 0, 0, time.UTC)
+	horizon := start.AddDate(-2, 0, 0)
+	for i := range 40 {
+		cronExpr, err := NewRandom(rand.New(rand.NewSource(int64(i))))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s, err := New(cronExpr, nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", cronExpr, err)
+		}
+
+		expected := time.Time{}
+		for m := start.Add(-time.Minute); m.After(horizon); m = m.Add(-time.Minute) {
+			if s.Matches(m) {
+				expected = m
+				break
+			}
+		}
+		if expected.IsZero() {
+			continue
+		}
+		assertEqual(t, s.Prev(start), expected)
+	}
+}
+
+func TestParseDuplicateEntries(t *testing.T) {
+	// overlapping list entries are compacted, without leaving
+	// zeroes behind at the end of the values
+	s, err := New("18,20,18-20 * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !slicesEqual(t, s.minutes, []int{18, 19, 20}) {
+		t.Errorf("expected minutes 18-20, got %v", s.minutes)
+	}
+	assertEqual(t, s.Matches(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), false)
+}
+
+func TestStepListSemantics(t *testing.T) {
+	type semanticsCase struct {
+		Field       field
+		Value       string
+		Expect      []int
+		ExpectError bool
+	}
+	cases := []semanticsCase{
+		// a step only applies to the list entry it follows
+		{
+			Field:  minuteOpts,
+			Value:  "1-10,20-30/5",
+			Expect: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 20, 25, 30},
+		},
+		{
+			Field:  minuteOpts,
+			Value:  "20-30/5,1-10",
+			Expect: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 20, 25, 30},
+		},
+		// a step after a single value runs to the end of the field
+		{
+			Field:  hourOpts,
+			Value:  "1,5,9/4",
+			Expect: []int{1, 5, 9, 13, 17, 21},
+		},
+		{Field: hourOpts, Value: "9/4,1,5", Expect: []int{1, 5, 9, 13, 17, 21}},
+		{Field: weekdayOpts, Value: "MON-FRI/2,SUN", Expect: []int{0, 1, 3, 5}},
+		{Field: minuteOpts, Value: "1-10/2/3", ExpectError: true},
+		{Field: minuteOpts, Value: "1-5-10", ExpectError: true},
+		{Field: minuteOpts, Value: "1-5-10/2", ExpectError: true},
+		{Field: minuteOpts, Value: "*-5", ExpectError: true},
+		{Field: minuteOpts, Value: "5-*", ExpectError: true},
+		{Field: dayOpts, Value: "?-5", ExpectError: true},
+		{Field: dayOpts, Value: "L-5", ExpectError: true},
+		{Field: dayOpts, Value: "L/2", ExpectError: true},
+		{Field: dayOpts, Value: "1L", ExpectError: true},
+		{Field: minuteOpts, Value: "L", ExpectError: true},
+		{Field: monthOpts, Value: "JAN,L", ExpectError: true},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Field.Name+" "+tc.Value, func(t *testing.T) {
+				values, err := tc.Field.parse(tc.Value)
+				if tc.ExpectError {
+					requireErr(t, err)
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if !slices.Equal(values, tc.Expect) {
+					t.Errorf("expected %v, got %v", tc.Expect, values)
+				}
+			},
+		)
+	}
+}
+
+func TestLastDayListEntry(t *testing.T) {
+	s, err := New("0 0 1,15,L * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	start := time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)
+	assertEqual(t, s.Next(start), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+	assertEqual(
+		t,
+		s.Next(s.Next(start)),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	)
+
+	assertEqual(
+		t,
+		s.Prev(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+	)
+
+	// the last day is checked against allowed ranges
+	_, err = New("0 0 1,L * *", nil, WithFieldRange("day", 1, 28))
+	requireErr(t, err)
+
+	// March 31, 2024 is only 23 hours long in London, where
+	// DST starts that day
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err = New("0 12 1,15,L * *", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(
+		t,
+		s.Next(time.Date(2024, 3, 16, 0, 0, 0, 0, loc)),
+		time.Date(2024, 3, 31, 12, 0, 0, 0, loc),
+	)
+	assertEqual(t, s.Matches(time.Date(2024, 3, 30, 12, 0, 0, 0, loc)), false)
+}
+
+func TestWeekTokens(t *testing.T) {
+	// the second week of the month selects the second Tuesday
+	s, err := New("0 9 W2 * TUE", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for range 24 {
+		next = s.Next(next)
+		day, ok := NthWeekdayOfMonth(next.Year(), next.Month(), time.Tuesday, 2)
+		assertEqual(t, ok, true)
+		assertEqual(t, next, time.Date(next.Year(), next.Month(), day, 9, 0, 0, 0, time.UTC))
+		assertEqual(t, s.Prev(next.Add(time.Minute)), next)
+	}
+	if err = CheckRoundTrip(s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the fifth week only has the days after the 28th
+	s, err = New("0 0 W5 2 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(
+		t,
+		s.Next(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+	)
+
+	// ISO week 1 of 2025 starts on 2024-12-30
+	s, err = New("0 9 WY1 * MON", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	monday := time.Date(2024, 12, 30, 9, 0, 0, 0, time.UTC)
+	assertEqual(t, s.Next(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)), monday)
+	assertEqual(t, s.Prev(monday), time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	// weeks of the year can be listed with days
+	s, err = New("0 9 15,wy10 * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, day := range []int{4, 5, 6, 7, 8, 9, 10, 15} {
+		next = s.Next(next)
+		assertEqual(t, next, time.Date(2024, 3, day, 9, 0, 0, 0, time.UTC))
+	}
+	fields := s.Fields()
+	assertEqual(t, fields[dayInd].Entries[1].Kind, FieldWeekOfYear)
+	assertEqual(t, fields[dayInd].Entries[1].Start, 10)
+
+	a, err := New("0 9 WY1 * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := New("0 9 WY2 * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(a, b), false)
+	assertEqual(t, Diff(a, b).String(), "day: added WY2, removed WY1")
+
+	w, err := New("0 9 W3 * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, w.Fields()[dayInd].Kind, FieldWeek)
+	assertEqual(t, w.Fields()[dayInd].Start, 3)
+	if !slices.Equal(w.days, []int{15, 16, 17, 18, 19, 20, 21}) {
+		t.Errorf("expected days 15-21, got %v", w.days)
+	}
+
+	for _, cron := range []string{
+		"0 9 W0 * *",
+		"0 9 W6 * *",
+		"0 9 W * *",
+		"0 9 WY0 * *",
+		"0 9 WY54 * *",
+		"0 9 W1-W2 * *",
+		"0 9 W2/2 * *",
+		"W1 9 * * *",
+	} {
+		_, err = New(cron, nil)
+		requireErr(t, err, cron)
+	}
+}
+
+func TestSundaySeven(t *testing.T) {
+	// 2024-03-03 is a Sunday
+	sunday := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		Cron     string
+		Weekdays []int
+	}{
+		{Cron: "0 0 * * 7", Weekdays: []int{0}},
+		{Cron: "0 0 * * 0,7", Weekdays: []int{0}},
+		{Cron: "0 0 * * 5-7", Weekdays: []int{0, 5, 6}},
+		{Cron: "0 0 * * 1-7/2", Weekdays: []int{0, 1, 3, 5}},
+		{Cron: "0 0 * * 7L", Weekdays: nil},
+		{Cron: "0 0 * * 7#1", Weekdays: nil},
+	} {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, s.String(), tc.Cron)
+				if !slices.Equal(s.weekdays, tc.Weekdays) {
+					t.Errorf("expected weekdays %v, got %v", tc.Weekdays, s.weekdays)
+				}
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			},
+		)
+	}
+
+	seven, err := New("0 0 * * 7", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	zero, err := New("0 0 * * 0", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(seven, zero), true)
+	assertEqual(t, seven.Matches(sunday), true)
+	assertEqual(t, seven.Next(sunday), sunday.AddDate(0, 0, 7))
+
+	// the last Sunday of March, 2024 is the 31st
+	last, err := New("0 0 * * 7L", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, last.Next(sunday), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC))
+
+	// 0 and 7 overlap, and together include every weekday
+	_, err = New("0 0 * * 0,7", nil, WithDuplicateCheck(LintError))
+	requireErr(t, err)
+	_, err = New("0 0 * * 1-7", nil, WithFullRangeCheck(LintError))
+	requireErr(t, err)
+	_, err = New("0 0 * * 8", nil)
+	requireErr(t, err)
+
+	spec := seven.Fields()[4]
+	assertEqual(t, spec.Start, 7)
+	if !slices.Equal(spec.Values, []int{0}) {
+		t.Errorf("expected values [0], got %v", spec.Values)
+	}
+}
+
+func TestLastWeekday(t *testing.T) {
+	type lastCase struct {
+		Cron     string
+		Opts     []ParseOption
+		Weekdays []time.Weekday
+	}
+	cases := []lastCase{
+		{Cron: "0 22 * * 5L", Weekdays: []time.Weekday{time.Friday}},
+		{Cron: "0 22 * * fril", Weekdays: []time.Weekday{time.Friday}},
+		{Cron: "0 22 * * 1L,5L", Weekdays: []time.Weekday{time.Monday, time.Friday}},
+		{
+			Cron:     "0 22 * * 7L",
+			Opts:     []ParseOption{WithISOWeekdays()},
+			Weekdays: []time.Weekday{time.Sunday},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil, tc.Opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				var expected []time.Time
+				for month := time.January; month <= time.December; month++ {
+					var days []int
+					for _, weekday := range tc.Weekdays {
+						days = append(days, LastWeekdayOfMonth(2024, month, weekday))
+					}
+					slices.Sort(days)
+					for _, day := range days {
+						expected = append(expected, time.Date(2024, month, day, 22, 0, 0, 0, time.UTC))
+					}
+				}
+				next := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				for _, expect := range expected {
+					next = s.Next(next)
+					assertEqual(t, next, expect)
+					assertEqual(t, s.Prev(next.Add(time.Minute)), next)
+				}
+			},
+		)
+	}
+
+	// every Monday, and the last Friday
+	s, err := New("0 22 * * 1,5L", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	for _, day := range []int{25, 29} {
+		next = s.Next(next)
+		assertEqual(t, next, time.Date(2024, 3, day, 22, 0, 0, 0, time.UTC))
+	}
+	next = s.Next(next)
+	assertEqual(t, next, time.Date(2024, 4, 1, 22, 0, 0, 0, time.UTC))
+
+	weekday := s.Fields()[weekdayInd].Entries[1]
+	assertEqual(t, weekday.Kind, FieldLast)
+	assertEqual(t, weekday.Start, 5)
+
+	monday, err := New("0 22 * * 1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Diff(monday, s).String(), "weekday: added 5L")
+
+	for _, cron := range []string{
+		"0 22 * * 8L",
+		"0 22 * * L",
+		"0 22 * * 1-5L",
+		"0 22 * * 5LL",
+		"0 22 5L * *",
+		"5L 22 * * *",
+	} {
+		_, err = New(cron, nil)
+		requireErr(t, err, cron)
+	}
+	_, err = New("0 22 * * 6L", nil, WithFieldRange("weekday", 1, 5))
+	requireErr(t, err)
+}
+
+func TestNthWeekday(t *testing.T) {
+	type nthCase struct {
+		Cron    string
+		Opts    []ParseOption
+		Weekday time.Weekday
+		N       int
+	}
+	cases := []nthCase{
+		{Cron: "0 9 * * 5#3", Weekday: time.Friday, N: 3},
+		{Cron: "0 9 * * fri#3", Weekday: time.Friday, N: 3},
+		{Cron: "0 9 * * MON#1", Weekday: time.Monday, N: 1},
+		{Cron: "0 9 * * 2#5", Weekday: time.Tuesday, N: 5},
+		{
+			Cron:    "0 9 * * 7#2",
+			Opts:    []ParseOption{WithISOWeekdays()},
+			Weekday: time.Sunday,
+			N:       2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil, tc.Opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				var expected []time.Time
+				for month := time.January; month <= time.December; month++ {
+					if day, ok := NthWeekdayOfMonth(2024, month, tc.Weekday, tc.N); ok {
+						expected = append(expected, time.Date(2024, month, day, 9, 0, 0, 0, time.UTC))
+					}
+				}
+				next := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				for _, expect := range expected {
+					next = s.Next(next)
+					assertEqual(t, next, expect)
+					assertEqual(t, s.Prev(next.Add(time.Minute)), next)
+				}
+			},
+		)
+	}
+
+	// the first and third Mondays, and every Friday
+	s, err := New("0 9 * * 1#1,1#3,5", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, day := range []int{1, 4, 8, 15, 18, 22, 29} {
+		next = s.Next(next)
+		assertEqual(t, next, time.Date(2024, 3, day, 9, 0, 0, 0, time.UTC))
+	}
+
+	weekday := s.Fields()[weekdayInd].Entries[1]
+	assertEqual(t, weekday.Kind, FieldNthWeekday)
+	assertEqual(t, weekday.Start, 1)
+	assertEqual(t, weekday.Nth, 3)
+
+	friday, err := New("0 9 * * 1#1,5", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Diff(friday, s).String(), "weekday: added 1#3")
+
+	for _, cron := range []string{
+		"0 9 * * 5#0",
+		"0 9 * * 5#6",
+		"0 9 * * 5#",
+		"0 9 * * #3",
+		"0 9 * * 1-5#3",
+		"0 9 * * 5#3/2",
+		"0 9 * * 5L#3",
+		"0 9 5#3 * *",
+		"5#3 9 * * *",
+	} {
+		_, err = New(cron, nil)
+		requireErr(t, err, cron)
+	}
+	_, err = New("0 9 * * 6#1", nil, WithFieldRange("weekday", 1, 5))
+	requireErr(t, err)
+}
+
+func TestNearestWeekdayToken(t *testing.T) {
+	type nearestCase struct {
+		Cron   string
+		From   time.Time
+		Expect []time.Time
+	}
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+	cases := []nearestCase{
+		{
+			// Saturday the 15th moves to Friday, and Monday the
+			// 15th doesn't move
+			Cron:   "0 9 15W * *",
+			From:   date(2024, time.June, 1),
+			Expect: []time.Time{date(2024, time.June, 14), date(2024, time.July, 15)},
+		},
+		{
+			// Saturday the 1st moves to Monday the 3rd, not the
+			// Friday before
+			Cron:   "0 9 1W * *",
+			From:   date(2024, time.May, 20),
+			Expect: []time.Time{date(2024, time.June, 3), date(2024, time.July, 1)},
+		},
+		{
+			// Sunday the 30th moves to Friday the 28th, not the
+			// Monday after
+			Cron:   "0 9 30w * *",
+			From:   date(2024, time.June, 1),
+			Expect: []time.Time{date(2024, time.June, 28), date(2024, time.July, 30)},
+		},
+		{
+			// months without a 31st are skipped
+			Cron:   "0 9 31W * *",
+			From:   date(2024, time.April, 1),
+			Expect: []time.Time{date(2024, time.May, 31), date(2024, time.July, 31)},
+		},
+		{
+			Cron: "0 9 1,LW * *",
+			From: date(2024, time.June, 1),
+			Expect: []time.Time{
+				date(2024, time.June, 28),
+				date(2024, time.July, 1),
+				date(2024, time.July, 31),
+				date(2024, time.August, 1),
+				date(2024, time.August, 30),
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				next := tc.From
+				for _, expect := range tc.Expect {
+					next = s.Next(next)
+					assertEqual(t, next, expect)
+					assertEqual(t, s.Matches(next), true)
+					assertEqual(t, s.Prev(next.Add(time.Minute)), next)
+				}
+			},
+		)
+	}
+
+	// the last weekday is the last day of the month, or the
+	// Friday before it
+	s, err := New("0 9 LW * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next := date(2024, time.January, 1)
+	for range 24 {
+		next = s.Next(next)
+		last := time.Date(next.Year(), next.Month()+1, 0, 9, 0, 0, 0, time.UTC)
+		for last.Weekday() == time.Saturday || last.Weekday() == time.Sunday {
+			last = last.AddDate(0, 0, -1)
+		}
+		assertEqual(t, next, last)
+	}
+	assertEqual(t, s.Matches(date(2024, time.June, 30)), false)
+
+	days := s.Fields()[dayInd]
+	assertEqual(t, days.Kind, FieldNearestWeekday)
+	assertEqual(t, days.Start, 0)
+
+	fifteenth, err := New("0 9 15W * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, fifteenth.Fields()[dayInd].Start, 15)
+	assertEqual(t, Diff(fifteenth, s).String(), "day: added LW, removed 15W")
+
+	for _, cron := range []string{
+		"0 9 32W * *",
+		"0 9 0W * *",
+		"0 9 W * *",
+		"0 9 15W-20 * *",
+		"0 9 15W/2 * *",
+		"0 9 * * 5W",
+		"15W 9 * * *",
+	} {
+		_, err = New(cron, nil)
+		requireErr(t, err, cron)
+	}
+	_, err = New("0 9 LW * *", nil, WithFieldRange("day", 1, 27))
+	requireErr(t, err)
+}
+
+func TestFloorCeil(t *testing.T) {
+	type floorCase struct {
+		cron  string
+		opts  []ParseOption
+		given time.Time
+		floor time.Time
+		ceil  time.Time
+	}
+	date := func(hour, minute, sec, nsec int) time.Time {
+		return time.Date(2024, 2, 21, hour, minute, sec, nsec, time.UTC)
+	}
+	cases := []floorCase{
+		{
+			cron:  "*/15 * * * *",
+			given: date(12, 7, 30, 0),
+			floor: date(12, 0, 0, 0),
+			ceil:  date(12, 15, 0, 0),
+		},
+		{
+			// a scheduled time is its own floor and ceiling
+			cron:  "*/15 * * * *",
+			given: date(12, 15, 0, 0),
+			floor: date(12, 15, 0, 0),
+			ceil:  date(12, 15, 0, 0),
+		},
+		{
+			// but not once it's past the scheduled instant
+			cron:  "*/15 * * * *",
+			given: date(12, 15, 0, 1),
+			floor: date(12, 15, 0, 0),
+			ceil:  date(12, 30, 0, 0),
+		},
+		{
+			cron:  "0 9 * * *",
+			given: date(8, 59, 59, 0),
+			floor: date(9, 0, 0, 0).AddDate(0, 0, -1),
+			ceil:  date(9, 0, 0, 0),
+		},
+		{
+			cron:  "*/10 * * * * *",
+			opts:  []ParseOption{WithSeconds()},
+			given: date(12, 0, 25, 0),
+			floor: date(12, 0, 20, 0),
+			ceil:  date(12, 0, 30, 0),
+		},
+		{
+			cron:  "*/10 * * * * *",
+			opts:  []ParseOption{WithSeconds()},
+			given: date(12, 0, 30, 0),
+			floor: date(12, 0, 30, 0),
+			ceil:  date(12, 0, 30, 0),
+		},
+		{
+			cron:  "@at 2024-02-21T12:00:00Z",
+			given: date(12, 0, 0, 0),
+			floor: date(12, 0, 0, 0),
+			ceil:  date(12, 0, 0, 0),
+		},
+		{
+			cron:  "@at 2024-02-21T12:00:00Z",
+			given: date(11, 0, 0, 0),
+			ceil:  date(12, 0, 0, 0),
+		},
+		{
+			cron:  "@at 2024-02-21T12:00:00Z",
+			given: date(13, 0, 0, 0),
+			floor: date(12, 0, 0, 0),
+		},
+		{
+			cron:  "0 0 31 4 *",
+			given: date(12, 0, 0, 0),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			fmt.Sprintf("%s %s", tc.cron, tc.given.Format(time.RFC3339Nano)),
+			func(t *testing.T) {
+				s, err := New(tc.cron, nil, tc.opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, s.Floor(tc.given), tc.floor)
+				assertEqual(t, s.Ceil(tc.given), tc.ceil)
+			},
+		)
+	}
+}
+
+func TestWithField(t *testing.T) {
+	base, err := New("30 9 * * MON-FRI", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	s, err := base.WithHour("*/2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "30 */2 * * MON-FRI")
+	assertEqual(
+		t,
+		s.Next(time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)),
+		time.Date(2024, 3, 4, 10, 30, 0, 0, time.UTC),
+	)
+	// the base schedule is left unchanged
+	assertEqual(t, base.String(), "30 9 * * MON-FRI")
+
+	s, err = base.WithMinute("0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err = s.WithWeekday("*")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err = s.WithDay("1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err = s.WithMonth("JAN")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "0 9 1 JAN *")
+	assertEqual(
+		t,
+		s.Next(time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)),
+		time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+	)
+
+	if _, err = base.WithHour("24"); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, err = base.WithSecond("0"); err == nil {
+		t.Errorf("expected error")
+	}
+
+	s, err = New("0 0 12 * * *", time.UTC, WithSeconds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err = s.WithSecond("*/30")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), "*/30 0 12 * * *")
+
+	at, err := New(At+" 2024-03-04T09:30:00Z", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = at.WithHour("10"); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	s, err := New("*/15 9-17 * * MON-FRI", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.EarliestTimeOfDay(), 9*time.Hour)
+	assertEqual(t, s.LatestTimeOfDay(), 17*time.Hour+45*time.Minute)
+
+	d, ok := s.EarliestTimeOfDayOn(time.Monday)
+	assertEqual(t, ok, true)
+	assertEqual(t, d, 9*time.Hour)
+	d, ok = s.LatestTimeOfDayOn(time.Friday)
+	assertEqual(t, ok, true)
+	assertEqual(t, d, 17*time.Hour+45*time.Minute)
+	_, ok = s.EarliestTimeOfDayOn(time.Sunday)
+	assertEqual(t, ok, false)
+	_, ok = s.LatestTimeOfDayOn(time.Saturday)
+	assertEqual(t, ok, false)
+
+	s, err = New("* * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.EarliestTimeOfDay(), time.Duration(0))
+	assertEqual(t, s.LatestTimeOfDay(), 23*time.Hour+59*time.Minute)
+
+	s, err = New("10,50 0 6,18 * * *", nil, WithSeconds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.EarliestTimeOfDay(), 6*time.Hour+10*time.Second)
+	assertEqual(t, s.LatestTimeOfDay(), 18*time.Hour+50*time.Second)
+
+	// 2024-03-04 is a Monday
+	s, err = New(At+" 2024-03-04T13:20:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.EarliestTimeOfDay(), 13*time.Hour+20*time.Minute)
+	assertEqual(t, s.LatestTimeOfDay(), 13*time.Hour+20*time.Minute)
+	_, ok = s.EarliestTimeOfDayOn(time.Monday)
+	assertEqual(t, ok, true)
+	_, ok = s.EarliestTimeOfDayOn(time.Tuesday)
+	assertEqual(t, ok, false)
+}
+
+func TestNextAnyAll(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 10, 0, 0, time.UTC)
+	hourly, err := New(Hourly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	quarterly, err := New("*/15 * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	past, err := New(At+" 2024-01-01T00:00:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	all := NextAll(start, hourly, past, quarterly, nil)
+	assertEqual(t, len(all), 4)
+	assertEqual(t, all[0], time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC))
+	assertEqual(t, all[1], time.Time{})
+	assertEqual(t, all[2], time.Date(2024, 3, 4, 9, 15, 0, 0, time.UTC))
+	assertEqual(t, all[3], time.Time{})
+
+	next, i := NextAny(start, hourly, past, quarterly, nil)
+	assertEqual(t, next, time.Date(2024, 3, 4, 9, 15, 0, 0, time.UTC))
+	assertEqual(t, i, 2)
+
+	// ties go to the first schedule
+	next, i = NextAny(start.Add(40*time.Minute), past, hourly, quarterly)
+	assertEqual(t, next, time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC))
+	assertEqual(t, i, 1)
+
+	next, i = NextAny(start, past)
+	assertEqual(t, next, time.Time{})
+	assertEqual(t, i, -1)
+
+	next, i = NextAny(start)
+	assertEqual(t, next, time.Time{})
+	assertEqual(t, i, -1)
+}
+
+func TestMacro(t *testing.T) {
+	s, err := New(Annually, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.Macro(), Annually)
+	assertEqual(t, s.String(), cronShortcut[Annually])
+
+	// a derived schedule no longer matches the macro
+	s, err = s.WithHour("12")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.Macro(), "")
+	assertEqual(t, s.String(), "0 12 1 1 *")
+
+	s, err = New("0 0 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.Macro(), "")
+}
+
+func TestFieldBounds(t *testing.T) {
+	s, err := New("10-20 3,9 L * MON-FRI", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.bounds[minuteInd], fieldBounds{min: 10, max: 20})
+	assertEqual(t, s.bounds[hourInd], fieldBounds{min: 3, max: 9})
+	assertEqual(t, s.bounds[dayInd], fieldBounds{min: 1, max: 31})
+	assertEqual(t, s.bounds[monthInd], fieldBounds{min: 1, max: 12})
+	assertEqual(t, s.bounds[weekdayInd], fieldBounds{min: 1, max: 5})
+
+	// past the last scheduled hour and minute
+	given := time.Date(2024, 1, 30, 9, 30, 0, 0, time.UTC)
+	assertEqual(t, s.Next(given), time.Date(2024, 1, 31, 3, 10, 0, 0, time.UTC))
+	// before the first
+	given = time.Date(2024, 1, 31, 3, 5, 0, 0, time.UTC)
+	// (Dec. 31, 2023 is a Sunday)
+	assertEqual(t, s.Prev(given), time.Date(2023, 11, 30, 9, 20, 0, 0, time.UTC))
+}
+
+// TestScheduleConcurrentUse shares schedules across goroutines, and
+// is meant to be run with -race
+func TestScheduleConcurrentUse(t *testing.T) {
+	exprs := []string{
+		"*/5 9-17 * * MON-FRI",
+		"0 12 L * *",
+		Hourly,
+		At + " 2024-06-01T12:00:00Z",
+	}
+	schedules := make([]*Schedule, 0, len(exprs))
+	for _, expr := range exprs {
+		s, err := New(expr, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		schedules = append(schedules, s)
+	}
+	seconds, err := New("*/10 * * * * *", nil, WithSeconds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	schedules = append(schedules, seconds)
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for g := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				given := start.Add(time.Duration(g*50+i) * 7 * time.Minute)
+				for _, s := range schedules {
+					_ = s.Next(given)
+					_ = s.Prev(given)
+					_, _ = s.NextErr(given)
+					_ = s.Matches(given)
+					_ = s.MatchesSecond(given)
+					_ = s.MatchesWithin(given, time.Second)
+					_ = s.String()
+					_ = s.EarliestTimeOfDay()
+					_ = s.Histogram(given, given.AddDate(0, 0, 1))
+				}
+				_, _ = NextAny(given, schedules...)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNextDue(t *testing.T) {
+	s, err := New("0 12 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	noon := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	tomorrow := noon.AddDate(0, 0, 1)
+
+	// fired on time, or late within the same minute
+	assertEqual(t, s.NextDue(noon, 0), tomorrow)
+	assertEqual(t, s.NextDue(noon.Add(5*time.Second), 0), tomorrow)
+	assertEqual(t, s.NextDue(noon.In(time.FixedZone("EST", -5*3600)), 0), tomorrow)
+
+	// fired early, within the tolerance
+	early := noon.Add(-200 * time.Millisecond)
+	assertEqual(t, s.NextDue(early, time.Second), tomorrow)
+	// without a tolerance, noon is still due
+	assertEqual(t, s.NextDue(early, 0), noon)
+
+	// missed occurrences are still due
+	assertEqual(t, s.NextDue(noon.AddDate(0, 0, -3), 0), noon.AddDate(0, 0, -2))
+
+	at, err := New(At+" 2024-03-04T12:00:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, at.NextDue(early, time.Second), time.Time{})
+	assertEqual(t, at.NextDue(early, 0), noon)
+}
+
+func TestWithLocation(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+
+	s, err := New("0 9 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ns := s.WithLocation(newYork)
+	assertEqual(t, s.Location(), time.UTC)
+	assertEqual(t, ns.Location(), newYork)
+	assertEqual(t, ns.String(), s.String())
+
+	from := time.Date(2024, 2, 21, 0, 0, 0, 0, time.UTC)
+	assertEqual(
+		t,
+		ns.Next(from).Equal(time.Date(2024, 2, 21, 9, 0, 0, 0, newYork)),
+		true,
+	)
+
+	// one-shots keep their wall clock time
+	at, err := New("@at 2024-03-01T12:00:00", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	nat := at.WithLocation(newYork)
+	assertEqual(
+		t,
+		nat.At().Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, newYork)),
+		true,
+	)
+	assertEqual(t, nat.String(), "@at 2024-03-01T12:00:00-05:00")
+
+	reloaded, err := ReloadLocation(newYork)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, reloaded.String(), newYork.String())
+	reloaded, err = ReloadLocation(time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, reloaded, time.UTC)
+}
+
+func TestScheduleWindow(t *testing.T) {
+	s, err := New("0 9 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	notBefore := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC)
+	ws := s.WithNotBefore(notBefore).WithNotAfter(notAfter)
+	assertEqual(t, ws.NotBefore(), notBefore)
+	assertEqual(t, ws.NotAfter(), notAfter)
+	assertEqual(t, s.NotBefore().IsZero(), true)
+	assertEqual(t, ws.String(), s.String())
+
+	first := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	last := notAfter
+
+	// before the window, the first scheduled time in it
+	assertEqual(t, ws.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), first)
+	assertEqual(t, ws.Ceil(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), first)
+	assertEqual(t, ws.Prev(first).IsZero(), true)
+	assertEqual(t, ws.Floor(first.Add(-time.Minute)).IsZero(), true)
+
+	// the window's bounds are inclusive
+	assertEqual(t, ws.Next(first.AddDate(0, 0, 1)), last)
+	assertEqual(t, ws.Matches(last), true)
+	assertEqual(t, ws.MatchesSecond(last), true)
+
+	// after the window, nothing
+	assertEqual(t, ws.Next(last).IsZero(), true)
+	_, err = ws.NextErr(last)
+	assertEqual(t, errors.Is(err, ErrScheduleExhausted), true)
+	assertEqual(t, ws.Matches(last.AddDate(0, 0, 1)), false)
+	assertEqual(t, ws.Prev(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), last)
+	assertEqual(t, ws.Floor(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), last)
+	assertEqual(t, ws.Ceil(last.Add(time.Second)).IsZero(), true)
+
+	// derived schedules keep the window
+	ns, err := ws.WithHour("10")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, ns.Next(last).IsZero(), true)
+	assertEqual(
+		t,
+		ns.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	)
+
+	// the window is part of what makes schedules equivalent,
+	// and survives a round trip
+	assertEqual(t, Equivalent(s, ws), false)
+	assertEqual(t, Equivalent(ws, s.WithNotAfter(notAfter).WithNotBefore(notBefore)), true)
+	if err = CheckRoundTrip(ws); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the zero time removes a bound
+	assertEqual(t, ws.WithNotAfter(time.Time{}).Next(last), last.AddDate(0, 0, 1))
+
+	// a ticker for the schedule is exhausted after the window
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ticker := NewTicker(ctx, s.WithNotAfter(time.Now().Add(-time.Minute)), time.Second)
+	defer ticker.Stop()
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected ticker to be exhausted")
+	case <-ticker.Exhausted():
+	}
+}
+
+func TestReboot(t *testing.T) {
+	s, err := New(Reboot, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, s.String(), Reboot)
+	assertEqual(t, s.Macro(), Reboot)
+	if err = CheckRoundTrip(s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s.Fields() != nil {
+		t.Errorf("expected no fields, got %#v", s.Fields())
+	}
+
+	// no scheduled times
+	now := time.Now()
+	assertEqual(t, s.Next(now).IsZero(), true)
+	assertEqual(t, s.Prev(now).IsZero(), true)
+	assertEqual(t, s.Floor(now).IsZero(), true)
+	assertEqual(t, s.Ceil(now).IsZero(), true)
+	assertEqual(t, s.Matches(now), false)
+	_, err = s.NextErr(now)
+	assertEqual(t, errors.Is(err, ErrScheduleExhausted), true)
+	_, err = s.AtIndex(0, now)
+	assertEqual(t, errors.Is(err, ErrScheduleExhausted), true)
+	assertEqual(t, s.Histogram(now, now.AddDate(0, 0, 7)).Total, 0)
+
+	daily, err := New(Daily, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, Equivalent(s, daily), false)
+
+	_, err = s.WithHour("1")
+	requireErr(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	for _, cron := range benchmarkExprs {
+		if err := Validate(cron); err != nil {
+			t.Errorf("unexpected error for %q: %s", cron, err)
+		}
+	}
+
+	// errors match New's
+	for _, cron := range []string{"0 25 * * *", "* * *", "@at tomorrow"} {
+		_, newErr := New(cron, nil)
+		err := Validate(cron)
+		if err == nil || newErr == nil {
+			t.Fatalf("expected error for %q", cron)
+		}
+		assertEqual(t, err.Error(), newErr.Error())
+	}
+
+	err := Validate("0 1,2,3 * * *", WithMaxListEntries(2))
+	var lerr *LimitError
+	if !errors.As(err, &lerr) {
+		t.Errorf("expected a *LimitError, got %v", err)
+	}
+
+	// pooled schedules don't carry options or fields over
+	if err = Validate("0 0 0 * * *", WithSeconds()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err = Validate("0 0 0 * * *"); err == nil {
+		t.Errorf("expected error for a seconds field without WithSeconds")
+	}
+}
+
+func TestNameRanges(t *testing.T) {
+	for _, tc := range []struct {
+		Cron     string
+		Months   []int
+		Weekdays []int
+	}{
+		{Cron: "0 0 * JAN-MAR *", Months: []int{1, 2, 3}},
+		{Cron: "0 0 * jan-dec/3 *", Months: []int{1, 4, 7, 10}},
+		{Cron: "0 0 * JUN,AUG-SEP *", Months: []int{6, 8, 9}},
+		{Cron: "0 0 * * MON-FRI/2", Weekdays: []int{1, 3, 5}},
+		{Cron: "0 0 * * SAT,SUN", Weekdays: []int{0, 6}},
+		{Cron: "0 0 * * MON,3-FRI", Weekdays: []int{1, 3, 4, 5}},
+		{Cron: "0 0 * * FRI-SUN", Weekdays: []int{0, 5, 6}},
+		{Cron: "0 0 * * MON-SUN", Weekdays: []int{0, 1, 2, 3, 4, 5, 6}},
+	} {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if tc.Months != nil && !slices.Equal(s.months, tc.Months) {
+					t.Errorf("expected months %v, got %v", tc.Months, s.months)
+				}
+				if tc.Weekdays != nil && !slices.Equal(s.weekdays, tc.Weekdays) {
+					t.Errorf("expected weekdays %v, got %v", tc.Weekdays, s.weekdays)
+				}
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			},
+		)
+	}
+
+	// only SUN, not 0, ends a range after the other weekdays
+	_, err := New("0 0 * * FRI-0", nil)
+	requireErr(t, err)
+	_, err = New("0 0 * MAR-JAN *", nil)
+	requireErr(t, err)
+
+	s, err := New("0 0 * * FRI-SUN", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	spec := s.Fields()[4]
+	assertEqual(t, spec.End, 7)
+	assertEqual(t, slices.Equal(spec.Names, []string{"FRI", "SUN"}), true)
+}
+
+func TestCronTZ(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	from := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, cron := range []string{
+		"CRON_TZ=America/New_York 0 9 * * MON-FRI",
+		"TZ=America/New_York 0 9 * * MON-FRI",
+		"  CRON_TZ=America/New_York   0 9 * * MON-FRI",
+	} {
+		t.Run(
+			cron, func(t *testing.T) {
+				// the prefix overrides the given location
+				s, err := New(cron, time.UTC)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, s.Location().String(), newYork.String())
+				assertEqual(t, s.String(), "0 9 * * MON-FRI")
+				// 07:00 in New York, on a Friday
+				want := time.Date(2024, 3, 1, 9, 0, 0, 0, newYork)
+				if next := s.Next(from); !next.Equal(want) {
+					t.Errorf("expected %s, got %s", want, next)
+				}
+				if err = CheckRoundTrip(s); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			},
+		)
+	}
+
+	// macros and one-shot schedules take the prefix too
+	s, err := New("CRON_TZ=America/New_York @daily", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := time.Date(2024, 3, 2, 0, 0, 0, 0, newYork); !s.Next(from).Equal(want) {
+		t.Errorf("expected %s, got %s", want, s.Next(from))
+	}
+	s, err = New("TZ=America/New_York @at 2024-07-01T09:00:00", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := time.Date(2024, 7, 1, 9, 0, 0, 0, newYork); !s.Next(from).Equal(want) {
+		t.Errorf("expected %s, got %s", want, s.Next(from))
+	}
+
+	for _, cron := range []string{
+		"CRON_TZ=Nowhere/Special 0 9 * * *",
+		"CRON_TZ= 0 9 * * *",
+		"TZ=UTC",
+	} {
+		_, err = New(cron, nil)
+		requireErr(t, err)
+	}
+}
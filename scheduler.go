@@ -0,0 +1,387 @@
+package crong
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SchedulerOptions configures a [Scheduler]
+type SchedulerOptions struct {
+	// MaxConcurrent, if set, is the maximum number of runs in progress
+	// at once across the scheduler's jobs, including the jobs of its
+	// child schedulers. Runs over the limit wait for one to finish.
+	MaxConcurrent int
+
+	// Blackouts are windows during which the scheduler's jobs (and
+	// its child schedulers' jobs) don't run. Ticks that fall in a
+	// blackout are skipped, and counted in [ScheduledJob.BlackedOut].
+	Blackouts []*Window
+}
+
+// Scheduler manages a set of named [ScheduledJob]s, and optionally
+// named child schedulers, which apply their own limits and blackouts
+// on top of their parent's (ex: a child per tenant, so one tenant
+// can be given a quota, or suspended wholesale).
+//
+// A Scheduler is safe for concurrent use, including while its jobs
+// are running. Dispatching a tick to a job doesn't take the
+// scheduler's lock, and Add, Remove and the rest only hold it to
+// update the scheduler's maps, so registering or removing jobs
+// doesn't hold up unrelated jobs, and a job's ticks don't hold up
+// changes to the scheduler.
+type Scheduler struct {
+	name     string
+	parent   *Scheduler
+	options  SchedulerOptions
+	slots    chan struct{}
+	jobs     map[string]*ScheduledJob
+	children map[string]*Scheduler
+	// adding holds the names of jobs being started by Add, which
+	// are reserved until the job is added to jobs. A name is set to
+	// true if it's removed while the job is starting.
+	adding map[string]bool
+	// stops counts calls to Stop, so Add can tell if the scheduler
+	// was stopped while a job was starting
+	stops uint64
+	// removed is set once the scheduler is removed from its parent
+	// (see RemoveChild), after which jobs can't be added to it
+	removed   atomic.Bool
+	suspended atomic.Bool
+	mu        sync.RWMutex
+
+	// started, if set, is called by Add once a job has started,
+	// before it's added to jobs (ex: so tests can stop the
+	// scheduler in between)
+	started func(job *ScheduledJob)
+}
+
+// NewScheduler returns a new, empty Scheduler
+func NewScheduler(opts SchedulerOptions) (*Scheduler, error) {
+	return newScheduler("", nil, opts)
+}
+
+func newScheduler(
+	name string,
+	parent *Scheduler,
+	opts SchedulerOptions,
+) (*Scheduler, error) {
+	if opts.MaxConcurrent < 0 {
+		return nil, errors.New("max concurrent must be 0 or greater")
+	}
+	if slices.Contains(opts.Blackouts, nil) {
+		return nil, errors.New("blackout window cannot be nil")
+	}
+	s := &Scheduler{
+		name:     name,
+		parent:   parent,
+		options:  opts,
+		jobs:     make(map[string]*ScheduledJob),
+		children: make(map[string]*Scheduler),
+		adding:   make(map[string]bool),
+	}
+	if opts.MaxConcurrent > 0 {
+		s.slots = make(chan struct{}, opts.MaxConcurrent)
+	}
+	return s, nil
+}
+
+// Name returns the scheduler's path from the root scheduler, with
+// names separated by '/' (ex: "tenants/acme"). The root scheduler's
+// name is empty.
+func (s *Scheduler) Name() string {
+	return s.path("")
+}
+
+// path returns the given name prefixed with the scheduler's path
+func (s *Scheduler) path(name string) string {
+	for p := s; p != nil && p.parent != nil; p = p.parent {
+		if name == "" {
+			name = p.name
+		} else {
+			name = p.name + "/" + name
+		}
+	}
+	return name
+}
+
+func (s *Scheduler) LogValue() slog.Value {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slog.GroupValue(
+		slog.String("name", s.Name()),
+		slog.Bool("suspended", s.Suspended()),
+		slog.Int("max_concurrent", s.options.MaxConcurrent),
+		slog.Int("jobs", len(s.jobs)),
+		slog.Int("children", len(s.children)),
+	)
+}
+
+// Add creates and starts a job named name, as with [ScheduleFuncContext].
+// If opts.Name is empty, it's set to the job's path from the root
+// scheduler (ex: "tenants/acme/report"). It returns an error if the
+// scheduler already has a job with the same name, or the scheduler
+// (or one of its parents) has been removed. If the scheduler is
+// stopped, or the job removed, while the job is starting, the job is
+// stopped and an error is returned, so no job is left running that
+// Stop didn't see.
+func (s *Scheduler) Add(
+	ctx context.Context,
+	name string,
+	schedule *Schedule,
+	opts ScheduledJobOptions,
+	f func(ctx context.Context, t time.Time) error,
+) (*ScheduledJob, error) {
+	if name == "" {
+		return nil, errors.New("job name cannot be empty")
+	}
+	if schedule == nil {
+		return nil, errors.New("schedule cannot be nil")
+	}
+	if opts.Name == "" {
+		opts.Name = s.path(name)
+	}
+
+	// the name is reserved while the job starts, so starting it
+	// doesn't block readers or other jobs being added or removed
+	s.mu.Lock()
+	if s.detached() {
+		s.mu.Unlock()
+		return nil, errRemoved
+	}
+	_, exists := s.jobs[name]
+	if _, adding := s.adding[name]; exists || adding {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("job '%s' already exists", name)
+	}
+	s.adding[name] = false
+	stops := s.stops
+	s.mu.Unlock()
+
+	job := scheduleFunc(ctx, schedule, opts, f, s)
+	if s.started != nil {
+		s.started(job)
+	}
+
+	s.mu.Lock()
+	removed := s.adding[name]
+	delete(s.adding, name)
+	var err error
+	switch {
+	case s.detached():
+		err = errRemoved
+	case s.stops != stops:
+		err = errors.New("scheduler stopped while the job was starting")
+	case removed:
+		err = fmt.Errorf("job '%s' removed while it was starting", name)
+	default:
+		s.jobs[name] = job
+	}
+	s.mu.Unlock()
+	if err != nil {
+		job.Stop(ctx)
+		return nil, err
+	}
+	return job, nil
+}
+
+// errRemoved is returned when adding to a removed scheduler
+var errRemoved = errors.New("scheduler has been removed")
+
+// detached returns true if the scheduler, or any of its
+// parents, has been removed (see RemoveChild)
+func (s *Scheduler) detached() bool {
+	for p := s; p != nil; p = p.parent {
+		if p.removed.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove stops the job with the given name and removes it from the
+// scheduler. A job that's still being started by Add is stopped once
+// it starts. It returns false if there's no such job.
+func (s *Scheduler) Remove(ctx context.Context, name string) bool {
+	s.mu.Lock()
+	if _, adding := s.adding[name]; adding {
+		s.adding[name] = true
+		s.mu.Unlock()
+		return true
+	}
+	job, ok := s.jobs[name]
+	delete(s.jobs, name)
+	s.mu.Unlock()
+	if ok {
+		job.Stop(ctx)
+	}
+	return ok
+}
+
+// Job returns the job with the given name, or nil if there isn't one
+func (s *Scheduler) Job(name string) *ScheduledJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.jobs[name]
+}
+
+// Jobs returns the scheduler's own jobs (not those of its child
+// schedulers), sorted by name
+func (s *Scheduler) Jobs() []*ScheduledJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	jobs := make([]*ScheduledJob, 0, len(names))
+	for _, name := range names {
+		jobs = append(jobs, s.jobs[name])
+	}
+	return jobs
+}
+
+// NewChild creates a child scheduler with the given name. Its jobs
+// are subject to its own options, as well as those of s (and any of
+// its parents). It returns an error if s already has a child with
+// the same name.
+func (s *Scheduler) NewChild(name string, opts SchedulerOptions) (*Scheduler, error) {
+	if name == "" {
+		return nil, errors.New("child scheduler name cannot be empty")
+	}
+	child, err := newScheduler(name, s, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.children[name]; ok {
+		return nil, fmt.Errorf("child scheduler '%s' already exists", name)
+	}
+	s.children[name] = child
+	return child, nil
+}
+
+// Child returns the child scheduler with the given name,
+// or nil if there isn't one
+func (s *Scheduler) Child(name string) *Scheduler {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.children[name]
+}
+
+// RemoveChild stops all jobs of the child scheduler with the given
+// name (see [Scheduler.Stop]) and removes it. Jobs can't be added to
+// a removed scheduler, or its children. It returns false if there's
+// no such child.
+func (s *Scheduler) RemoveChild(ctx context.Context, name string) bool {
+	s.mu.Lock()
+	child, ok := s.children[name]
+	delete(s.children, name)
+	s.mu.Unlock()
+	if ok {
+		child.removed.Store(true)
+		child.Stop(ctx)
+	}
+	return ok
+}
+
+// Suspend pauses all of the scheduler's jobs, including the jobs of
+// its child schedulers, until Resume is called. Ticks received while
+// suspended are skipped. Jobs keep their own state, so a job that
+// was suspended on its own stays suspended after Resume. It returns
+// false if the scheduler was already suspended.
+func (s *Scheduler) Suspend() bool {
+	return s.suspended.CompareAndSwap(false, true)
+}
+
+// Resume resumes the scheduler's jobs after a call to Suspend.
+// It returns false if the scheduler wasn't suspended.
+func (s *Scheduler) Resume() bool {
+	return s.suspended.CompareAndSwap(true, false)
+}
+
+// Suspended returns true if the scheduler, or any of its
+// parents, is suspended
+func (s *Scheduler) Suspended() bool {
+	for p := s; p != nil; p = p.parent {
+		if p.suspended.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// Stop stops all of the scheduler's jobs, including the jobs of its
+// child schedulers. Stopped jobs stay registered (see [Scheduler.Remove]).
+// Jobs still being started by Add are stopped once they start.
+func (s *Scheduler) Stop(ctx context.Context) {
+	s.mu.Lock()
+	s.stops++
+	jobs := make([]*ScheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	children := make([]*Scheduler, 0, len(s.children))
+	for _, child := range s.children {
+		children = append(children, child)
+	}
+	s.mu.Unlock()
+
+	for _, job := range jobs {
+		job.Stop(ctx)
+	}
+	for _, child := range children {
+		child.Stop(ctx)
+	}
+}
+
+// blackedOut returns true if t falls in a blackout window of the
+// scheduler, or any of its parents
+func (s *Scheduler) blackedOut(t time.Time) bool {
+	for p := s; p != nil; p = p.parent {
+		for _, w := range p.options.Blackouts {
+			if w.ActiveAt(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// acquire waits for a run slot from the scheduler and each of its
+// parents that limit concurrent runs, and returns a function that
+// releases them. Slots are taken from the root down, so runs waiting
+// on different children can't deadlock. It returns false if ctx is
+// done first.
+func (s *Scheduler) acquire(ctx context.Context) (func(), bool) {
+	var chain []*Scheduler
+	for p := s; p != nil; p = p.parent {
+		if p.slots != nil {
+			chain = append(chain, p)
+		}
+	}
+	slices.Reverse(chain)
+
+	release := func(held []*Scheduler) {
+		for _, p := range held {
+			<-p.slots
+		}
+	}
+	for i, p := range chain {
+		select {
+		case <-ctx.Done():
+			release(chain[:i])
+			return nil, false
+		case p.slots <- struct{}{}:
+		}
+	}
+	return func() { release(chain) }, true
+}
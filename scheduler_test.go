@@ -0,0 +1,578 @@
+package crong
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stress, if set, runs TestSchedulerConcurrentMutation for that long
+// (ex: go test -race -run ConcurrentMutation -crong.stress=1m)
+var stress = flag.Duration(
+	"crong.stress",
+	0,
+	"how long to run scheduler mutation stress tests",
+)
+
+func TestScheduler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(ctx context.Context, dt time.Time) error { return nil }
+
+	root, err := NewScheduler(SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer root.Stop(context.Background())
+	tenant, err := root.NewChild("acme", SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = root.NewChild("acme", SchedulerOptions{}); err == nil {
+		t.Errorf("expected error for duplicate child")
+	}
+	assertEqual(t, root.Child("acme"), tenant)
+	assertEqual(t, tenant.Name(), "acme")
+
+	report, err := tenant.Add(ctx, "report", s, ScheduledJobOptions{}, f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, report.options.Name, "acme/report")
+	if _, err = tenant.Add(ctx, "report", s, ScheduledJobOptions{}, f); err == nil {
+		t.Errorf("expected error for duplicate job")
+	}
+	if _, err = tenant.Add(ctx, "cleanup", s, ScheduledJobOptions{}, f); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	jobs := tenant.Jobs()
+	assertEqual(t, len(jobs), 2)
+	assertEqual(t, jobs[1], report)
+
+	assertEqual(t, tenant.Remove(context.Background(), "report"), true)
+	assertEqual(t, tenant.Remove(context.Background(), "report"), false)
+	assertEqual(t, report.State(), ScheduleStopped)
+	assertEqual(t, tenant.Job("report") == nil, true)
+
+	assertEqual(t, root.RemoveChild(context.Background(), "acme"), true)
+	assertEqual(t, root.Child("acme") == nil, true)
+	assertEqual(t, jobs[0].State(), ScheduleStopped)
+}
+
+func TestSchedulerSuspend(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	root, _ := NewScheduler(SchedulerOptions{})
+	defer root.Stop(context.Background())
+	tenant, _ := root.NewChild("acme", SchedulerOptions{})
+
+	ran := make(chan struct{}, 1)
+	job, err := tenant.Add(
+		ctx, "report", s, ScheduledJobOptions{},
+		func(ctx context.Context, dt time.Time) error {
+			ran <- struct{}{}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// suspending the parent suspends the child's jobs
+	assertEqual(t, root.Suspend(), true)
+	assertEqual(t, tenant.Suspended(), true)
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return !job.triggerPending.Load()
+		},
+	)
+	assertEqual(t, job.Runs.Load(), 0)
+
+	assertEqual(t, root.Resume(), true)
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-ran
+}
+
+func TestSchedulerBlackout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	always, err := New("* * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	blackout, err := NewWindow(always, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	root, _ := NewScheduler(SchedulerOptions{Blackouts: []*Window{blackout}})
+	defer root.Stop(context.Background())
+	tenant, _ := root.NewChild("acme", SchedulerOptions{})
+
+	job, err := tenant.Add(
+		ctx, "report", s, ScheduledJobOptions{},
+		func(ctx context.Context, dt time.Time) error { return nil },
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return job.BlackedOut.Load() == 1
+		},
+	)
+	assertEqual(t, job.Runs.Load(), 0)
+
+	if _, err = NewScheduler(SchedulerOptions{Blackouts: []*Window{nil}}); err == nil {
+		t.Errorf("expected error for nil blackout")
+	}
+}
+
+func TestSchedulerMaxConcurrent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	root, _ := NewScheduler(SchedulerOptions{MaxConcurrent: 1})
+	defer root.Stop(context.Background())
+
+	startedCh := make(chan string, 10)
+	releaseCh := make(chan struct{})
+	var jobs []*ScheduledJob
+	for _, name := range []string{"acme", "globex"} {
+		tenant, _ := root.NewChild(name, SchedulerOptions{MaxConcurrent: 2})
+		job, err := tenant.Add(
+			ctx, "report", s, ScheduledJobOptions{MaxConcurrent: 2},
+			func(ctx context.Context, dt time.Time) error {
+				startedCh <- name
+				<-releaseCh
+				return nil
+			},
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	for _, job := range jobs {
+		if err = job.Trigger(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	first := <-startedCh
+
+	// the root's limit holds the other tenant's run
+	select {
+	case name := <-startedCh:
+		t.Fatalf("%s started over the root's limit", name)
+	case <-time.After(200 * time.Millisecond):
+	}
+	releaseCh <- struct{}{}
+	second := <-startedCh
+	if first == second {
+		t.Errorf("expected both tenants to run, got %s twice", first)
+	}
+	releaseCh <- struct{}{}
+}
+
+func TestSchedulerSnapshot(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	root, _ := NewScheduler(SchedulerOptions{MaxConcurrent: 2})
+	defer root.Stop(context.Background())
+	tenant, _ := root.NewChild("acme", SchedulerOptions{})
+
+	releaseCh := make(chan struct{})
+	job, err := tenant.Add(
+		ctx, "report", s, ScheduledJobOptions{},
+		func(ctx context.Context, dt time.Time) error {
+			AddRunAttrs(ctx, slog.String("rows", "10"))
+			<-releaseCh
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(ctx context.Context, dt time.Time) error { return nil }
+	if _, err = root.Add(ctx, "cleanup", s, ScheduledJobOptions{}, f); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for range 3 {
+		if err = job.Trigger(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		releaseCh <- struct{}{}
+	}
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(job.Runtimes()) == 3 && len(job.ActiveRuns()) == 1
+		},
+	)
+	assertEqual(t, tenant.Suspend(), true)
+
+	snap := root.Snapshot(2)
+	assertEqual(t, snap.Name, "")
+	assertEqual(t, snap.MaxConcurrent, 2)
+	assertEqual(t, snap.Suspended, false)
+	assertEqual(t, len(snap.Jobs), 1)
+	assertEqual(t, snap.Jobs[0].Name, "cleanup")
+	assertEqual(t, len(snap.Children), 1)
+
+	child := snap.Children[0]
+	assertEqual(t, child.Name, "acme")
+	assertEqual(t, child.Suspended, true)
+	assertEqual(t, len(child.Jobs), 1)
+	report := child.Jobs[0]
+	assertEqual(t, report.Name, "report")
+	assertEqual(t, report.Schedule, "0 0 1 1 *")
+	assertEqual(t, report.Location, "UTC")
+	assertEqual(t, report.State, ScheduleStarted)
+	assertEqual(t, report.Next, s.Next(time.Now()))
+	assertEqual(t, report.Runs, 4)
+	assertEqual(t, report.Running, 1)
+	assertEqual(t, len(report.ActiveRuns), 1)
+	assertEqual(t, len(report.Runtimes), 2)
+	runtimes := job.Runtimes()
+	assertEqual(t, report.Runtimes[1].RunID, runtimes[2].RunID)
+	assertEqual(t, report.Runtimes[0].RunID, runtimes[1].RunID)
+
+	// the snapshot doesn't change with the job, or share its runtimes
+	report.Runtimes[1].Attrs[0] = slog.String("rows", "0")
+	assertEqual(t, runtimes[2].Attrs[0].Value.String(), "10")
+	releaseCh <- struct{}{}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(job.Runtimes()) == 4
+		},
+	)
+	assertEqual(t, report.Running, 1)
+	assertEqual(t, len(root.Snapshot(0).Children[0].Jobs[0].Runtimes), 0)
+	assertEqual(t, len(root.Snapshot(10).Children[0].Jobs[0].Runtimes), 4)
+}
+
+// TestSchedulerConcurrentMutation adds, suspends and removes jobs and
+// child schedulers while another job is being dispatched ticks, and
+// is meant to be run with -race (and -crong.stress, to run it for
+// longer)
+func TestSchedulerConcurrentMutation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second+*stress)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	root, _ := NewScheduler(SchedulerOptions{MaxConcurrent: 8})
+	defer root.Stop(context.Background())
+
+	var steadyRuns atomic.Int64
+	steady, err := root.Add(
+		ctx, "steady", s, ScheduledJobOptions{MaxConcurrent: 1},
+		func(ctx context.Context, dt time.Time) error {
+			steadyRuns.Add(1)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a job stuck in a run doesn't hold up removing it, or other jobs
+	stuck := make(chan struct{})
+	defer close(stuck)
+	blocked, err := root.Add(
+		ctx, "blocked", s, ScheduledJobOptions{},
+		func(ctx context.Context, dt time.Time) error {
+			<-stuck
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err = blocked.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(blocked.ActiveRuns()) == 1
+		},
+	)
+
+	done := make(chan struct{})
+	var dispatched sync.WaitGroup
+	dispatched.Add(1)
+	go func() {
+		defer dispatched.Done()
+		at := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			steady.ticker.inject(ctx, at.Add(time.Duration(i)*time.Minute))
+		}
+	}()
+
+	iterations := 50
+	deadline := time.Now().Add(*stress)
+	f := func(ctx context.Context, dt time.Time) error { return nil }
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations || time.Now().Before(deadline); i++ {
+				name := fmt.Sprintf("job-%d-%d", g, i)
+				job, err := root.Add(ctx, name, s, ScheduledJobOptions{}, f)
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				_ = job.Suspend()
+				_ = job.Trigger()
+				_ = job.Resume()
+
+				child, err := root.NewChild(name, SchedulerOptions{})
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				if _, err = child.Add(ctx, "report", s, ScheduledJobOptions{}, f); err != nil {
+					t.Errorf("unexpected error: %s", err)
+					return
+				}
+				_ = child.Suspend()
+				_ = root.Jobs()
+				_ = root.Snapshot(1)
+				_ = root.LogValue()
+				_ = child.Resume()
+
+				if !root.Remove(ctx, name) {
+					t.Errorf("expected job %s to be removed", name)
+				}
+				if !root.RemoveChild(ctx, name) {
+					t.Errorf("expected child %s to be removed", name)
+				}
+				assertEqual(t, job.State(), ScheduleStopped)
+			}
+		}()
+	}
+
+	// the blocked job is removed while its run is in progress
+	removed := make(chan bool, 1)
+	go func() { removed <- root.Remove(ctx, "blocked") }()
+	select {
+	case ok := <-removed:
+		assertEqual(t, ok, true)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("removing a job blocked on its running run")
+	}
+
+	wg.Wait()
+	before := steadyRuns.Load()
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return steadyRuns.Load() > before
+		},
+	)
+	close(done)
+	dispatched.Wait()
+
+	if steadyRuns.Load() == 0 {
+		t.Errorf("expected the steady job to run during mutation")
+	}
+	jobs := root.Jobs()
+	assertEqual(t, len(jobs), 1)
+	assertEqual(t, jobs[0], steady)
+	assertEqual(t, len(root.Snapshot(0).Children), 0)
+}
+
+// TestSchedulerConcurrentAdd adds jobs with the same name at once,
+// only one of which should be added
+func TestSchedulerConcurrentAdd(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	root, _ := NewScheduler(SchedulerOptions{})
+	defer root.Stop(context.Background())
+
+	f := func(ctx context.Context, dt time.Time) error { return nil }
+	var added atomic.Int64
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := root.Add(ctx, "report", s, ScheduledJobOptions{}, f); err == nil {
+				added.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assertEqual(t, added.Load(), 1)
+	assertEqual(t, len(root.Jobs()), 1)
+}
+
+// TestSchedulerAddStop adds jobs while the scheduler is stopped and
+// its child removed, and checks no job is left running
+func TestSchedulerAddStop(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New(Reboot, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// each job runs once when it starts, until it's stopped
+	var running atomic.Int64
+	f := func(ctx context.Context, dt time.Time) error {
+		running.Add(1)
+		defer running.Add(-1)
+		<-ctx.Done()
+		return nil
+	}
+
+	for range 20 {
+		root, _ := NewScheduler(SchedulerOptions{})
+		tenant, _ := root.NewChild("acme", SchedulerOptions{})
+		var wg sync.WaitGroup
+		for g := range 8 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_, _ = root.Add(ctx, fmt.Sprintf("job-%d", g), s, ScheduledJobOptions{}, f)
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = tenant.Add(ctx, fmt.Sprintf("job-%d", g), s, ScheduledJobOptions{}, f)
+			}()
+		}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			root.Stop(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			root.RemoveChild(ctx, "acme")
+		}()
+		wg.Wait()
+
+		if _, err = tenant.Add(ctx, "late", s, ScheduledJobOptions{}, f); err == nil {
+			t.Errorf("expected error adding to a removed scheduler")
+		}
+		// jobs added after the first Stop are still registered
+		root.Stop(ctx)
+	}
+
+	// gives any job that was missed time to start its run
+	time.Sleep(100 * time.Millisecond)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return running.Load() == 0
+		},
+	)
+}
+
+// TestSchedulerStopAdding stops the scheduler, or removes the job,
+// while Add is starting it
+func TestSchedulerStopAdding(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(ctx context.Context, dt time.Time) error { return nil }
+	root, _ := NewScheduler(SchedulerOptions{})
+	defer root.Stop(context.Background())
+	tenant, _ := root.NewChild("acme", SchedulerOptions{})
+
+	cases := []struct {
+		Name      string
+		Scheduler *Scheduler
+		Stop      func()
+	}{
+		{Name: "stop", Scheduler: root, Stop: func() { root.Stop(ctx) }},
+		{Name: "remove", Scheduler: root, Stop: func() { root.Remove(ctx, "report") }},
+		{Name: "stop parent", Scheduler: tenant, Stop: func() { root.Stop(ctx) }},
+		{
+			Name:      "remove child",
+			Scheduler: tenant,
+			Stop:      func() { root.RemoveChild(ctx, "acme") },
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Name, func(t *testing.T) {
+				var started *ScheduledJob
+				tc.Scheduler.started = func(job *ScheduledJob) {
+					started = job
+					tc.Stop()
+				}
+				job, err := tc.Scheduler.Add(ctx, "report", s, ScheduledJobOptions{}, f)
+				requireErr(t, err)
+				assertEqual(t, job == nil, true)
+				assertEqual(t, tc.Scheduler.Job("report") == nil, true)
+				assertEqual(t, started.State(), ScheduleStopped)
+			},
+		)
+	}
+
+	tenant.started = nil
+	if _, err = tenant.Add(ctx, "report", s, ScheduledJobOptions{}, f); err == nil {
+		t.Errorf("expected error adding to a removed scheduler")
+	}
+	root.started = nil
+	if _, err = root.Add(ctx, "report", s, ScheduledJobOptions{}, f); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,188 @@
+package crong
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// JobShutdown reports what a job was doing when it was shut down
+// (see [ScheduledJob.Shutdown])
+type JobShutdown struct {
+	// Name is the job's path from the root scheduler, or its
+	// [ScheduledJobOptions.Name] if it wasn't added to one
+	Name string
+
+	// Drained is the number of runs that finished after the job
+	// was stopped, without returning the context's error
+	Drained int64
+
+	// Cancelled is the number of runs that returned the context's
+	// error after the job was stopped
+	Cancelled int64
+
+	// Dropped is the number of ticks that were queued (or waiting
+	// for a run slot) when the job stopped, and never ran
+	Dropped int64
+
+	// Complete is false if the shutdown context was done before the
+	// job's runs finished, so some may still be in progress
+	Complete bool
+
+	// Final is the job's state once it stopped
+	Final JobSnapshot
+}
+
+// LogValue implements [slog.LogValuer]
+func (j JobShutdown) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", j.Name),
+		slog.String("state", j.Final.State.String()),
+		slog.String("stop_reason", j.Final.StopReason.String()),
+		slog.Int64("drained", j.Drained),
+		slog.Int64("cancelled", j.Cancelled),
+		slog.Int64("dropped", j.Dropped),
+		slog.Int64("running", j.Final.Running),
+		slog.Bool("complete", j.Complete),
+	)
+}
+
+// ShutdownReport reports what a scheduler's jobs were doing when it
+// was shut down (see [Scheduler.Shutdown])
+type ShutdownReport struct {
+	// Jobs holds the scheduler's jobs, sorted by name, followed by the
+	// jobs of its child schedulers
+	Jobs []JobShutdown
+}
+
+// Drained returns the total number of runs that finished after
+// their job was stopped (see [JobShutdown.Drained])
+func (r ShutdownReport) Drained() int64 {
+	var n int64
+	for _, j := range r.Jobs {
+		n += j.Drained
+	}
+	return n
+}
+
+// Cancelled returns the total number of runs that returned the
+// context's error after their job was stopped
+// (see [JobShutdown.Cancelled])
+func (r ShutdownReport) Cancelled() int64 {
+	var n int64
+	for _, j := range r.Jobs {
+		n += j.Cancelled
+	}
+	return n
+}
+
+// Dropped returns the total number of ticks that never ran
+// (see [JobShutdown.Dropped])
+func (r ShutdownReport) Dropped() int64 {
+	var n int64
+	for _, j := range r.Jobs {
+		n += j.Dropped
+	}
+	return n
+}
+
+// Complete returns true if every job's runs finished before
+// the shutdown context was done
+func (r ShutdownReport) Complete() bool {
+	for _, j := range r.Jobs {
+		if !j.Complete {
+			return false
+		}
+	}
+	return true
+}
+
+// LogValue implements [slog.LogValuer]
+func (r ShutdownReport) LogValue() slog.Value {
+	jobs := make([]slog.Attr, 0, len(r.Jobs))
+	for _, j := range r.Jobs {
+		jobs = append(jobs, slog.Any(j.Name, j))
+	}
+	return slog.GroupValue(
+		slog.Int64("drained", r.Drained()),
+		slog.Int64("cancelled", r.Cancelled()),
+		slog.Int64("dropped", r.Dropped()),
+		slog.Bool("complete", r.Complete()),
+		slog.Attr{Key: "jobs", Value: slog.GroupValue(jobs...)},
+	)
+}
+
+// Shutdown stops the job, as with [ScheduledJob.Stop], then waits for
+// its runs in progress to finish, or for ctx to be done, and reports
+// which runs were cut short
+func (s *ScheduledJob) Shutdown(ctx context.Context) JobShutdown {
+	s.Stop(ctx)
+	return s.shutdownReport(ctx, s.options.Name)
+}
+
+// shutdownReport waits for the stopped job's runs to finish,
+// or ctx to be done, and reports on them
+func (s *ScheduledJob) shutdownReport(ctx context.Context, name string) JobShutdown {
+	complete := true
+	if s.previouslyStarted.Load() {
+		select {
+		case <-ctx.Done():
+			complete = false
+		case <-s.done:
+		}
+	}
+	report := JobShutdown{
+		Name:      name,
+		Drained:   s.drained.Load(),
+		Cancelled: s.cancelled.Load(),
+		Dropped:   s.dropped.Load(),
+		Complete:  complete,
+		Final:     s.Snapshot(0),
+	}
+	report.Final.Name = name
+	jobLogger().Info("job shut down", "shutdown", report)
+	return report
+}
+
+// Shutdown stops all of the scheduler's jobs, including the jobs of
+// its child schedulers, as with [Scheduler.Stop], then waits for their
+// runs in progress to finish, or for ctx to be done, and reports
+// which runs were cut short
+func (s *Scheduler) Shutdown(ctx context.Context) ShutdownReport {
+	s.Stop(ctx)
+	var report ShutdownReport
+	s.shutdownReport(ctx, &report)
+	return report
+}
+
+// shutdownReport adds the scheduler's stopped jobs, then those
+// of its child schedulers, to the report
+func (s *Scheduler) shutdownReport(ctx context.Context, report *ShutdownReport) {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	jobs := make([]*ScheduledJob, 0, len(names))
+	for _, name := range names {
+		jobs = append(jobs, s.jobs[name])
+	}
+	childNames := make([]string, 0, len(s.children))
+	for name := range s.children {
+		childNames = append(childNames, name)
+	}
+	slices.Sort(childNames)
+	children := make([]*Scheduler, 0, len(childNames))
+	for _, name := range childNames {
+		children = append(children, s.children[name])
+	}
+	s.mu.RUnlock()
+
+	for i, job := range jobs {
+		report.Jobs = append(report.Jobs, job.shutdownReport(ctx, s.path(names[i])))
+	}
+	for _, child := range children {
+		child.shutdownReport(ctx, report)
+	}
+}
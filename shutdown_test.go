@@ -0,0 +1,121 @@
+package crong
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSchedulerShutdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	scheduler, err := NewScheduler(SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	child, err := scheduler.NewChild("tenant", SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err := New("0 0 1 1 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	now := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	opts := ScheduledJobOptions{
+		MaxConcurrent:        1,
+		MaxQueueDepth:        5,
+		TickerReceiveTimeout: 5 * time.Second,
+	}
+
+	// runs until it's cancelled, with two more ticks queued behind it
+	cancelled, err := scheduler.Add(
+		ctx, "cancelled", s, opts,
+		func(ctx context.Context, t time.Time) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// wraps up without an error once it's cancelled
+	drained, err := child.Add(
+		ctx, "drained", s, opts,
+		func(ctx context.Context, t time.Time) error {
+			<-ctx.Done()
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		cancelled.ticker.inject(ctx, now.Add(time.Duration(i)*time.Minute))
+	}
+	drained.ticker.inject(ctx, now)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return cancelled.Running.Load() == 1 && drained.Running.Load() == 1
+		},
+	)
+
+	report := scheduler.Shutdown(ctx)
+
+	assertEqual(t, len(report.Jobs), 2)
+	assertEqual(t, report.Jobs[0].Name, "cancelled")
+	assertEqual(t, report.Jobs[0].Cancelled, int64(1))
+	assertEqual(t, report.Jobs[0].Dropped, int64(2))
+	assertEqual(t, report.Jobs[0].Drained, int64(0))
+	assertEqual(t, report.Jobs[0].Final.State, ScheduleStopped)
+	assertEqual(t, report.Jobs[0].Final.StopReason, StopRequested)
+	assertEqual(t, report.Jobs[1].Name, "tenant/drained")
+	assertEqual(t, report.Jobs[1].Drained, int64(1))
+	assertEqual(t, report.Jobs[1].Final.Runs, int64(1))
+	assertEqual(t, report.Complete(), true)
+	assertEqual(t, report.Drained(), int64(1))
+	assertEqual(t, report.Cancelled(), int64(1))
+	assertEqual(t, report.Dropped(), int64(2))
+}
+
+func TestJobShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	release := make(chan struct{})
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{TickerReceiveTimeout: 5 * time.Second},
+		func(dt time.Time) error {
+			<-release
+			return nil
+		},
+	)
+	sj.ticker.inject(ctx, time.Time{})
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.Running.Load() == 1
+		},
+	)
+
+	// the run outlasts the shutdown context
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer shutdownCancel()
+	report := sj.Shutdown(shutdownCtx)
+	assertEqual(t, report.Complete, false)
+	assertEqual(t, report.Final.Running, int64(1))
+	close(release)
+
+	// once it finishes, it's counted as drained
+	report = sj.Shutdown(ctx)
+	assertEqual(t, report.Complete, true)
+	assertEqual(t, report.Drained, int64(1))
+	assertEqual(t, report.Final.Running, int64(0))
+}
@@ -0,0 +1,67 @@
+package crong
+
+import (
+	"sync"
+	"time"
+)
+
+// slaCheck tracks whether a run finishes within the job's SLA
+// (see [ScheduledJobOptions.SLA]). Each run is recorded as exactly
+// one hit or miss.
+type slaCheck struct {
+	deadline time.Time
+	timer    *time.Timer
+	once     sync.Once
+}
+
+// watchSLA starts tracking the SLA for the given run, which is for
+// the given scheduled occurrence. The miss is recorded as soon as
+// the deadline passes, even if the run is still going.
+func (s *ScheduledJob) watchSLA(runID string, scheduled time.Time) *slaCheck {
+	c := &slaCheck{deadline: scheduled.Add(s.options.SLA)}
+	remaining := c.deadline.Sub(clockNow(s.options.Clock))
+	c.timer = time.AfterFunc(
+		max(remaining, 0), func() {
+			c.once.Do(
+				func() {
+					s.missedSLA(runID, scheduled)
+				},
+			)
+		},
+	)
+	return c
+}
+
+// finish records the run as a hit if it ended by the deadline,
+// and it hasn't already been recorded as a miss. It returns true
+// if the run missed the SLA.
+func (c *slaCheck) finish(s *ScheduledJob, runID string, end time.Time) bool {
+	c.timer.Stop()
+	missed := true
+	c.once.Do(
+		func() {
+			if end.After(c.deadline) {
+				s.missedSLA(runID, c.deadline.Add(-s.options.SLA))
+				return
+			}
+			missed = false
+			s.SLAHits.Add(1)
+		},
+	)
+	return missed
+}
+
+// missedSLA records a run that didn't finish within the SLA
+func (s *ScheduledJob) missedSLA(runID string, scheduled time.Time) {
+	s.SLAMisses.Add(1)
+	jobLogger().Warn(
+		"run missed SLA",
+		"run_id", runID,
+		"scheduled", scheduled,
+		"sla", s.options.SLA,
+		"scheduled_job", s,
+	)
+	if f := s.options.OnSLAMiss; f != nil {
+		f(runID, scheduled)
+	}
+}
@@ -0,0 +1,67 @@
+package crong
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestJobSLA(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	now := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	late := now.Add(-time.Minute)
+	missedCh := make(chan time.Time, 1)
+	releaseCh := make(chan struct{})
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			MaxConcurrent:        2,
+			TickerReceiveTimeout: 5 * time.Second,
+			SLA:                  30 * time.Second,
+			OnSLAMiss: func(runID string, scheduled time.Time) {
+				missedCh <- scheduled
+			},
+			Clock: ClockFunc(
+				func() time.Time {
+					return now
+				},
+			),
+		},
+		func(dt time.Time) error {
+			if dt.Equal(late) {
+				<-releaseCh
+			}
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	// scheduled at the clock's time, so it finishes within the SLA
+	sj.ticker.tick(ctx)
+
+	// the miss is reported while the run is still going
+	sj.ticker.inject(ctx, late)
+	if scheduled := <-missedCh; !scheduled.Equal(late) {
+		t.Errorf("expected miss for %s, got %s", late, scheduled)
+	}
+	close(releaseCh)
+
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+	for _, rt := range sj.Runtimes() {
+		assertEqual(t, rt.SLAMissed, rt.Scheduled.Equal(late))
+	}
+	stats := sj.Stats()
+	assertEqual(t, stats.SLAHits, 1)
+	assertEqual(t, stats.SLAMisses, 1)
+}
@@ -0,0 +1,184 @@
+package crong
+
+import (
+	"bytes"
+	"slices"
+	"time"
+)
+
+// SchedulerSnapshot is a point-in-time copy of a [Scheduler], its
+// jobs and its child schedulers (see [Scheduler.Snapshot]). It shares
+// no state with the scheduler, so it can be rendered (ex: by an admin
+// page's HTTP handler) without holding any of the scheduler's locks,
+// or racing with its jobs.
+type SchedulerSnapshot struct {
+	// Name is the scheduler's path from the root scheduler
+	// (see [Scheduler.Name])
+	Name string
+
+	// Taken is when the snapshot was taken
+	Taken time.Time
+
+	// Suspended is true if the scheduler, or any of its
+	// parents, was suspended
+	Suspended bool
+
+	// MaxConcurrent is the scheduler's [SchedulerOptions.MaxConcurrent]
+	MaxConcurrent int
+
+	// Jobs holds the scheduler's own jobs, sorted by name
+	Jobs []JobSnapshot
+
+	// Children holds the scheduler's child schedulers, sorted by name
+	Children []SchedulerSnapshot
+}
+
+// JobSnapshot is a point-in-time copy of a [ScheduledJob]'s state
+// (see [ScheduledJob.Snapshot])
+type JobSnapshot struct {
+	// Name is the job's name in its scheduler, or its
+	// [ScheduledJobOptions.Name] if it wasn't added to one
+	Name string
+
+	// Schedule is the job's schedule expression
+	Schedule string
+
+	// Location is the name of the schedule's location
+	Location string
+
+	// State is the job's state
+	State ScheduleState
+
+	// StopReason is why the job stopped, if it has
+	StopReason StopReason
+
+	// Degraded is true if the job's failure rate is over its
+	// [ScheduledJobOptions.DegradedFailureRate]
+	Degraded bool
+
+	// Next is the job's next scheduled time, or the zero time if
+	// it isn't started (or suspended), or has no more occurrences
+	Next time.Time
+
+	// MaxConcurrent is the job's current maximum number of
+	// concurrent runs (see [ScheduledJob.SetMaxConcurrent])
+	MaxConcurrent int
+
+	// Counters copied from the job's fields of the same names
+
+	Runs                int64
+	Running             int64
+	Failures            int64
+	ConsecutiveFailures int64
+	Duplicates          int64
+	BlackedOut          int64
+	OverBudget          int64
+	StuckRuns           int64
+	Shed                int64
+
+	// Stats summarizes the job's finished runs
+	Stats JobStats
+
+	// ActiveRuns holds the runs in progress, ordered by start
+	ActiveRuns []ActiveRun
+
+	// Runtimes holds the job's most recent runtimes, oldest first
+	Runtimes []JobRuntime
+}
+
+// Snapshot returns a copy of the state of the scheduler, its jobs and
+// its child schedulers. Up to history of each job's most recent
+// runtimes are included. The scheduler's lock is only held while its
+// jobs and children are listed, and each job is copied separately,
+// so the snapshot isn't atomic across jobs.
+func (s *Scheduler) Snapshot(history int) SchedulerSnapshot {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	jobs := make([]*ScheduledJob, 0, len(names))
+	for _, name := range names {
+		jobs = append(jobs, s.jobs[name])
+	}
+	childNames := make([]string, 0, len(s.children))
+	for name := range s.children {
+		childNames = append(childNames, name)
+	}
+	slices.Sort(childNames)
+	children := make([]*Scheduler, 0, len(childNames))
+	for _, name := range childNames {
+		children = append(children, s.children[name])
+	}
+	s.mu.RUnlock()
+
+	snap := SchedulerSnapshot{
+		Name:          s.Name(),
+		Taken:         time.Now(),
+		Suspended:     s.Suspended(),
+		MaxConcurrent: s.options.MaxConcurrent,
+		Jobs:          make([]JobSnapshot, 0, len(jobs)),
+		Children:      make([]SchedulerSnapshot, 0, len(children)),
+	}
+	for i, job := range jobs {
+		js := job.Snapshot(history)
+		js.Name = names[i]
+		snap.Jobs = append(snap.Jobs, js)
+	}
+	for _, child := range children {
+		snap.Children = append(snap.Children, child.Snapshot(history))
+	}
+	return snap
+}
+
+// Snapshot returns a copy of the job's state, including up to
+// history of its most recent runtimes
+func (s *ScheduledJob) Snapshot(history int) JobSnapshot {
+	schedule := s.Schedule()
+	state := s.State()
+	snap := JobSnapshot{
+		Name:                s.options.Name,
+		Schedule:            schedule.String(),
+		Location:            schedule.Location().String(),
+		State:               state,
+		StopReason:          s.StopReason(),
+		Degraded:            s.Degraded(),
+		MaxConcurrent:       s.MaxConcurrent(),
+		Runs:                s.Runs.Load(),
+		Running:             s.Running.Load(),
+		Failures:            s.Failures.Load(),
+		ConsecutiveFailures: s.ConsecutiveFailures.Load(),
+		Duplicates:          s.Duplicates.Load(),
+		BlackedOut:          s.BlackedOut.Load(),
+		OverBudget:          s.OverBudget.Load(),
+		StuckRuns:           s.StuckRuns.Load(),
+		Shed:                s.Shed.Load(),
+		Stats:               s.Stats(),
+	}
+	if state == ScheduleStarted || state == ScheduleSuspended {
+		snap.Next = schedule.Next(clockNow(s.options.Clock))
+	}
+
+	snap.ActiveRuns = s.ActiveRuns()
+	for i := range snap.ActiveRuns {
+		snap.ActiveRuns[i].Checkpoint = bytes.Clone(snap.ActiveRuns[i].Checkpoint)
+	}
+	slices.SortFunc(
+		snap.ActiveRuns, func(a, b ActiveRun) int {
+			return a.Started.Compare(b.Started)
+		},
+	)
+
+	s.mu.RLock()
+	recent := s.runtimes[len(s.runtimes)-min(max(history, 0), len(s.runtimes)):]
+	snap.Runtimes = make([]JobRuntime, 0, len(recent))
+	for _, rt := range recent {
+		c := *rt
+		c.Attrs = slices.Clone(rt.Attrs)
+		c.Checkpoint = bytes.Clone(rt.Checkpoint)
+		snap.Runtimes = append(snap.Runtimes, c)
+	}
+	s.mu.RUnlock()
+	return snap
+}
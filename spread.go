@@ -0,0 +1,72 @@
+package crong
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// AddSpread runs one logical schedule in each of the given locations,
+// for jobs that should run at the same local time in every region
+// (ex: "0 2 * * *" at 02:00 in each location). It creates a child
+// scheduler named name, with a job per location, running schedule
+// evaluated in that location (see [Schedule.WithLocation]). Jobs are
+// named after their location (ex: "America/New_York"), so
+// "nightly/America/New_York" is the path of one of the jobs of
+// AddSpread(ctx, "nightly", ...), and f receives times in the job's
+// location. Stopping, suspending or removing the child scheduler
+// applies to the jobs in every location.
+//
+// opts is used for each job. If opts.WatermarkKey is set, each job's
+// watermark is stored under the key followed by "/" and its location,
+// so the locations' occurrences are tracked separately. It returns an
+// error, without starting any jobs, if s already has a child with the
+// same name, or the locations are empty or repeated.
+func (s *Scheduler) AddSpread(
+	ctx context.Context,
+	name string,
+	schedule *Schedule,
+	locations []*time.Location,
+	opts ScheduledJobOptions,
+	f func(ctx context.Context, t time.Time) error,
+) (*Scheduler, error) {
+	if schedule == nil {
+		return nil, errors.New("schedule cannot be nil")
+	}
+	if len(locations) == 0 {
+		return nil, errors.New("at least one location is required")
+	}
+	names := make([]string, 0, len(locations))
+	for _, loc := range locations {
+		if loc == nil {
+			return nil, errors.New("location cannot be nil")
+		}
+		if slices.Contains(names, loc.String()) {
+			return nil, fmt.Errorf("duplicate location '%s'", loc)
+		}
+		names = append(names, loc.String())
+	}
+
+	child, err := s.NewChild(name, SchedulerOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i, loc := range locations {
+		jobOpts := opts
+		jobOpts.Name = ""
+		if opts.Name != "" {
+			jobOpts.Name = opts.Name + "/" + names[i]
+		}
+		if opts.WatermarkKey != "" {
+			jobOpts.WatermarkKey = opts.WatermarkKey + "/" + names[i]
+		}
+		_, err = child.Add(ctx, names[i], schedule.WithLocation(loc), jobOpts, f)
+		if err != nil {
+			s.RemoveChild(ctx, name)
+			return nil, err
+		}
+	}
+	return child, nil
+}
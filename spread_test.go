@@ -0,0 +1,88 @@
+package crong
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSchedulerAddSpread(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 2 * * *", nil) // 02:00 daily
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	root, err := NewScheduler(SchedulerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer root.Stop(context.Background())
+
+	doneCh := make(chan time.Time, 10)
+	f := func(ctx context.Context, dt time.Time) error {
+		doneCh <- dt
+		return nil
+	}
+	opts := ScheduledJobOptions{
+		TickerReceiveTimeout: 5 * time.Second,
+		WatermarkKey:         "nightly",
+	}
+
+	_, err = root.AddSpread(ctx, "nightly", s, nil, opts, f)
+	requireErr(t, err)
+	_, err = root.AddSpread(ctx, "nightly", s, []*time.Location{tokyo, tokyo}, opts, f)
+	requireErr(t, err)
+	assertEqual(t, root.Child("nightly") == nil, true)
+
+	nightly, err := root.AddSpread(
+		ctx, "nightly", s, []*time.Location{newYork, tokyo}, opts, f,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = root.AddSpread(ctx, "nightly", s, []*time.Location{tokyo}, opts, f)
+	requireErr(t, err)
+
+	jobs := nightly.Jobs()
+	assertEqual(t, len(jobs), 2)
+	assertEqual(t, jobs[0].options.Name, "nightly/America/New_York")
+	assertEqual(t, jobs[0].watermarkKey(), "nightly/America/New_York")
+	assertEqual(t, jobs[1].options.Name, "nightly/Asia/Tokyo")
+
+	// each job runs at 02:00 local time
+	start := time.Date(2024, 2, 21, 0, 0, 0, 0, time.UTC)
+	assertEqual(
+		t,
+		nightly.Job("America/New_York").Schedule().Next(start).Equal(
+			time.Date(2024, 2, 21, 2, 0, 0, 0, newYork),
+		),
+		true,
+	)
+	assertEqual(
+		t,
+		nightly.Job("Asia/Tokyo").Schedule().Next(start).Equal(
+			time.Date(2024, 2, 22, 2, 0, 0, 0, tokyo),
+		),
+		true,
+	)
+
+	nightly.Job("Asia/Tokyo").ticker.inject(ctx, time.Date(2024, 2, 22, 2, 0, 0, 0, tokyo))
+	dt := <-doneCh
+	assertEqual(t, dt.Location(), tokyo)
+
+	assertEqual(t, root.RemoveChild(context.Background(), "nightly"), true)
+	for _, job := range jobs {
+		assertEqual(t, job.State(), ScheduleStopped)
+	}
+}
@@ -0,0 +1,98 @@
+package crong
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// statsWindow is the number of recent run durations
+// percentiles are computed from
+const statsWindow = 1024
+
+// JobStats summarizes a [ScheduledJob]'s finished runs.
+// Count and Mean cover every run, while the percentiles are
+// computed from the most recent runs (up to 1024), so they follow
+// changes in the job's behavior.
+type JobStats struct {
+	// Count is the number of finished runs
+	Count int64
+
+	// Mean is the mean run duration
+	Mean time.Duration
+
+	// P50 is the median run duration
+	P50 time.Duration
+
+	// P95 is the 95th percentile run duration
+	P95 time.Duration
+
+	// P99 is the 99th percentile run duration
+	P99 time.Duration
+
+	// SLAHits is the number of runs that finished within
+	// [ScheduledJobOptions.SLA]
+	SLAHits int64
+
+	// SLAMisses is the number of runs that didn't
+	SLAMisses int64
+}
+
+// durationStats tracks run durations for JobStats
+type durationStats struct {
+	mu     sync.Mutex
+	count  int64
+	total  time.Duration
+	recent []time.Duration
+	// next is the index in recent to overwrite once it's full
+	next int
+}
+
+// add records a run duration
+func (d *durationStats) add(duration time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.count++
+	d.total += duration
+	if len(d.recent) < statsWindow {
+		d.recent = append(d.recent, duration)
+		return
+	}
+	d.recent[d.next] = duration
+	d.next = (d.next + 1) % statsWindow
+}
+
+// stats returns a summary of the recorded durations
+func (d *durationStats) stats() JobStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.count == 0 {
+		return JobStats{}
+	}
+	sorted := slices.Clone(d.recent)
+	slices.Sort(sorted)
+	return JobStats{
+		Count: d.count,
+		Mean:  d.total / time.Duration(d.count),
+		P50:   percentile(sorted, 0.50),
+		P95:   percentile(sorted, 0.95),
+		P99:   percentile(sorted, 0.99),
+	}
+}
+
+// percentile returns the nearest-rank percentile p (0-1)
+// of the given sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// Stats returns a summary of the job's finished runs: their
+// durations, and how many met the job's SLA
+func (s *ScheduledJob) Stats() JobStats {
+	stats := s.durations.stats()
+	stats.SLAHits = s.SLAHits.Load()
+	stats.SLAMisses = s.SLAMisses.Load()
+	return stats
+}
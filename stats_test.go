@@ -0,0 +1,63 @@
+package crong
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDurationStats(t *testing.T) {
+	var d durationStats
+	assertEqual(t, d.stats(), JobStats{})
+
+	for i := 1; i <= 100; i++ {
+		d.add(time.Duration(i) * time.Millisecond)
+	}
+	stats := d.stats()
+	assertEqual(t, stats.Count, int64(100))
+	assertEqual(t, stats.Mean, 50500*time.Microsecond)
+	assertEqual(t, stats.P50, 50*time.Millisecond)
+	assertEqual(t, stats.P95, 95*time.Millisecond)
+	assertEqual(t, stats.P99, 99*time.Millisecond)
+
+	// percentiles follow the most recent runs
+	for i := 0; i < statsWindow; i++ {
+		d.add(time.Second)
+	}
+	stats = d.stats()
+	assertEqual(t, stats.Count, int64(100+statsWindow))
+	assertEqual(t, stats.P50, time.Second)
+	assertEqual(t, stats.P99, time.Second)
+}
+
+func TestJobStats(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{TickerReceiveTimeout: 5 * time.Second},
+		func(dt time.Time) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 1
+		},
+	)
+	stats := sj.Stats()
+	assertEqual(t, stats.Count, int64(1))
+	if stats.P50 < 20*time.Millisecond || stats.P50 != stats.P99 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
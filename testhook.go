@@ -0,0 +1,43 @@
+package crong
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arcward/crong/internal/testhook"
+)
+
+func init() {
+	testhook.Tick = func(ctx context.Context, target any, at time.Time) bool {
+		switch v := target.(type) {
+		case *Ticker:
+			return v.inject(ctx, at)
+		case *ScheduledJob:
+			return v.ticker.inject(ctx, at)
+		default:
+			panic(fmt.Sprintf("crong: can't send a tick to %T", target))
+		}
+	}
+}
+
+// inject sends a tick for the given time (or the current time, if
+// it's zero) as if the schedule had fired, unless the ticker has
+// stopped or ctx is done first
+func (t *Ticker) inject(ctx context.Context, at time.Time) bool {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	at = at.In(t.Schedule().loc)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-t.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return t.sendTick(ctx, Tick{Time: at, Occurrences: 1, First: at, Last: at})
+}
@@ -0,0 +1,133 @@
+package crong
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestEvaluateThroughput(t *testing.T) {
+	var schedules []*Schedule
+	for _, cron := range benchmarkExprs {
+		s, err := New(cron, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		schedules = append(schedules, s)
+	}
+	// one-shots in the past never occur, and are skipped
+	past, err := New("@at 2020-01-01T00:00:00Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	schedules = append(schedules, past)
+
+	result := EvaluateThroughput(schedules, 50*time.Millisecond)
+	assertEqual(t, result.Schedules, len(schedules))
+	if result.Evaluations < throughputCheckEvery {
+		t.Errorf("expected at least %d evaluations, got %d", throughputCheckEvery, result.Evaluations)
+	}
+	if result.Elapsed < 50*time.Millisecond {
+		t.Errorf("expected to run for at least 50ms, got %s", result.Elapsed)
+	}
+	if result.PerSecond <= 0 || result.PerEvaluation <= 0 {
+		t.Errorf("expected rates to be set, got %+v", result)
+	}
+
+	assertEqual(t, EvaluateThroughput(nil, time.Second), Throughput{})
+}
+
+// matchesListScan is Matches, as it was implemented before field
+// values were held in bitsets: each field's values are scanned
+func matchesListScan(s *Schedule, t time.Time) bool {
+	contains := func(anyValue bool, values []int, v int) bool {
+		if anyValue {
+			return true
+		}
+		for _, included := range values {
+			if included == v {
+				return true
+			}
+		}
+		return false
+	}
+	return contains(s.allowAnyWeekday, s.weekdays, int(t.Weekday())) &&
+		contains(s.allowAnyMonth, s.months, int(t.Month())) &&
+		contains(s.allowAnyDay, s.days, t.Day()) &&
+		contains(s.allowAnyHour, s.hours, t.Hour()) &&
+		contains(s.allowAnyMinute, s.minutes, t.Minute())
+}
+
+// benchmarkMatches calls match for each of a set of random
+// schedules and times
+func benchmarkMatches(b *testing.B, match func(s *Schedule, t time.Time) bool) {
+	r := rand.New(rand.NewSource(1))
+	var schedules []*Schedule
+	for len(schedules) < 100 {
+		cron, err := NewRandom(r)
+		if err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+		s, err := New(cron, nil)
+		if err != nil || s.Day() == string(Last) {
+			continue
+		}
+		schedules = append(schedules, s)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := schedules[i%len(schedules)]
+		_ = match(s, start.Add(time.Duration(i)*time.Minute))
+	}
+}
+
+func BenchmarkMatchesListScan(b *testing.B) {
+	benchmarkMatches(b, matchesListScan)
+}
+
+func BenchmarkMatchesBitset(b *testing.B) {
+	benchmarkMatches(
+		b, func(s *Schedule, t time.Time) bool {
+			return s.Matches(t)
+		},
+	)
+}
+
+func TestMatchesListScan(t *testing.T) {
+	// the reference implementation agrees with Matches
+	r := rand.New(rand.NewSource(1))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 200; i++ {
+		cron, err := NewRandom(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s, err := New(cron, nil)
+		if err != nil || s.Day() == string(Last) || s.Macro() != "" {
+			continue
+		}
+		for j := 0; j < 100; j++ {
+			at := start.Add(time.Duration(r.Intn(525600)) * time.Minute)
+			if s.Matches(at) != matchesListScan(s, at) {
+				t.Fatalf("%s: implementations disagree at %s", s, at)
+			}
+		}
+	}
+}
+
+func BenchmarkEvaluateThroughput(b *testing.B) {
+	var schedules []*Schedule
+	for _, cron := range benchmarkExprs {
+		s, err := New(cron, nil)
+		if err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+		schedules = append(schedules, s)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result := EvaluateThroughput(schedules, 10*time.Millisecond)
+		b.ReportMetric(float64(result.PerEvaluation.Nanoseconds()), "ns/eval")
+	}
+}
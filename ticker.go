@@ -2,6 +2,8 @@ package crong
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"sync"
@@ -12,16 +14,248 @@ import (
 // Logger used by [Ticker] and [ScheduledJob]. By default, it discards all logs.
 var Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 
+// TickerLogger, if set, is used instead of Logger for [Ticker] logs,
+// which include a record for each tick and wakeup, so they can be
+// sent to a separate handler or level from job runs. Like Logger,
+// it should be set before any tickers are created.
+var TickerLogger *slog.Logger
+
+// JobLogger, if set, is used instead of Logger for [ScheduledJob]
+// logs (ex: runs starting and finishing). Like Logger, it should be
+// set before any jobs are created.
+var JobLogger *slog.Logger
+
+// tickerLogger returns the logger for Ticker logs
+func tickerLogger() *slog.Logger {
+	if TickerLogger != nil {
+		return TickerLogger
+	}
+	return Logger
+}
+
+// jobLogger returns the logger for ScheduledJob logs
+func jobLogger() *slog.Logger {
+	if JobLogger != nil {
+		return JobLogger
+	}
+	return Logger
+}
+
+// DropImmediately can be used as a [Ticker] send timeout (or
+// [ScheduledJobOptions.TickerReceiveTimeout]) to drop a tick right
+// away if no receiver is ready for it. Any negative duration has
+// the same effect.
+const DropImmediately time.Duration = -1
+
+// Tick is sent on the Ticker.Ticks channel when the schedule is triggered
+type Tick struct {
+	// Time is the time the tick was sent
+	Time time.Time
+
+	// Occurrences is the number of scheduled occurrences the tick
+	// represents. This is 1, unless several occurrences became due
+	// at once and were coalesced (see [TickerOptions.Coalesce])
+	Occurrences int
+
+	// First is the earliest scheduled occurrence the tick represents
+	First time.Time
+
+	// Last is the latest scheduled occurrence the tick represents
+	Last time.Time
+
+	// Triggered is true if the tick was sent by
+	// [ScheduledJob.Trigger] rather than the schedule, in which
+	// case it represents no occurrences
+	Triggered bool
+
+	// Reason is why the tick was sent
+	Reason RunReason
+
+	// delivery is the ID of the delivery the tick was received
+	// in, if it came from an AckTickQueue (see ScheduledJob.settle)
+	delivery string
+}
+
+// RunReason is why a [Tick] was sent, and so why a [ScheduledJob]
+// ran (see [JobRuntime.Reason]), which separates runs for the
+// schedule from operator actions
+type RunReason int
+
+const (
+	// RunScheduled means the tick is for occurrences that
+	// just became due
+	RunScheduled RunReason = iota
+
+	// RunCatchUp means the tick includes occurrences that were
+	// missed (ex: while the host was asleep, or the receiver was
+	// busy), and became due before the minute (or second) the
+	// ticker sent it in
+	RunCatchUp
+
+	// RunTriggered means the tick was sent by [ScheduledJob.Trigger]
+	RunTriggered
+
+	// RunStartup means the tick was sent when a job with a startup
+	// (@reboot) schedule was started
+	RunStartup
+)
+
+func (r RunReason) String() string {
+	switch r {
+	case RunScheduled:
+		return "scheduled"
+	case RunCatchUp:
+		return "catch_up"
+	case RunTriggered:
+		return "triggered"
+	case RunStartup:
+		return "startup"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalJSON encodes the reason as its name (see [RunReason.String])
+func (r RunReason) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON decodes a reason from its name
+// (see [RunReason.String])
+func (r *RunReason) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for _, reason := range []RunReason{
+		RunScheduled,
+		RunCatchUp,
+		RunTriggered,
+		RunStartup,
+	} {
+		if reason.String() == name {
+			*r = reason
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown run reason '%s'", name)
+}
+
+// TickerOptions configures a [Ticker]
+type TickerOptions struct {
+	// Name identifies the ticker in log records
+	Name string
+
+	// SendTimeout is the maximum time to wait for a receiver
+	// to receive a tick (see [NewTicker])
+	SendTimeout time.Duration
+
+	// Coalesce delivers a single tick when several scheduled
+	// occurrences become due at once (ex: catching up after the
+	// host was suspended, or the clock jumped forward), rather
+	// than a burst of ticks. The tick's Occurrences, First and
+	// Last fields describe the occurrences it represents.
+	Coalesce bool
+
+	// MissedTicks determines what happens to occurrences that were
+	// missed because the host was asleep (ex: a suspended laptop
+	// or paused VM). Defaults to [MissedTicksFireAll].
+	MissedTicks MissedTickPolicy
+
+	// Tolerance treats the current time as having reached a scheduled
+	// occurrence if it's within Tolerance before it, so a tick isn't
+	// delayed when the host's clock lands slightly short of the minute
+	// boundary (ex: NTP slewing the clock, or smearing a leap second).
+	// See [Schedule.MatchesWithin].
+	Tolerance time.Duration
+
+	// Clock, if set, provides the times ticks are sent with
+	// (Tick.Time). The ticker still uses the system clock to
+	// decide when occurrences are due.
+	Clock Clock
+}
+
+func (o TickerOptions) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", o.Name),
+		slog.Duration("send_timeout", o.SendTimeout),
+		slog.Bool("coalesce", o.Coalesce),
+		slog.String("missed_ticks", o.MissedTicks.String()),
+		slog.Duration("tolerance", o.Tolerance),
+	)
+}
+
+// MissedTickPolicy determines how a [Ticker] handles scheduled
+// occurrences that were missed while the host was asleep. The
+// host is considered to have been asleep when the wall clock
+// advanced significantly further than the monotonic clock
+// between two checks of the time. Stepping the wall clock forward
+// (ex: an NTP step or a manual change) looks the same, so it's
+// also treated as the host having been asleep.
+type MissedTickPolicy int
+
+const (
+	// MissedTicksFireAll sends a tick for each missed occurrence
+	// (or a single tick, if [TickerOptions.Coalesce] is set)
+	MissedTicksFireAll MissedTickPolicy = iota
+
+	// MissedTicksFireOnce sends a single tick representing all
+	// missed occurrences, regardless of [TickerOptions.Coalesce]
+	MissedTicksFireOnce
+
+	// MissedTicksSkip doesn't send ticks for missed occurrences.
+	// An occurrence due in the minute (or second) the host
+	// woke up in isn't considered missed.
+	MissedTicksSkip
+)
+
+func (p MissedTickPolicy) String() string {
+	switch p {
+	case MissedTicksFireAll:
+		return "fire_all"
+	case MissedTicksFireOnce:
+		return "fire_once"
+	case MissedTicksSkip:
+		return "skip"
+	default:
+		return "unknown"
+	}
+}
+
+// sleepThreshold is how much further the wall clock must advance
+// than the monotonic clock between two checks of the time for the
+// host to be considered to have been asleep
+const sleepThreshold = 30 * time.Second
+
+// hostSleptFor returns how much longer the wall clock advanced than
+// the monotonic clock between last and now. The monotonic clock
+// doesn't advance while the host is suspended, while the wall clock
+// does. A forward step of the wall clock is indistinguishable from
+// a suspend, so it's counted too. If either time has no monotonic
+// clock reading, it returns 0.
+func hostSleptFor(last time.Time, now time.Time) time.Duration {
+	return now.Round(0).Sub(last.Round(0)) - now.Sub(last)
+}
+
 // Ticker is a cron ticker that sends the current time
-// on the Ticker.C channel when the schedule is triggered
+// on the Ticker.C channel when the schedule is triggered.
+// Each tick is delivered once, on either Ticker.C or
+// Ticker.Ticks, whichever is received from first.
 type Ticker struct {
-	schedule *Schedule
-	C        chan time.Time
-	tickCh   chan time.Time
-	stop     chan struct{}
-	// sendTimeout is the maximum time to wait for a receiver
-	// to send a tick on the Ticker.C channel
-	sendTimeout time.Duration
+	// schedule is replaced by SetLocation, guarded by scheduleMu
+	schedule   *Schedule
+	scheduleMu sync.RWMutex
+	C          chan time.Time
+	Ticks      chan Tick
+	tickCh     chan Tick
+	stop       chan struct{}
+	// done is closed once the ticker has stopped
+	done chan struct{}
+	// exhausted is closed once the schedule has no more occurrences
+	exhausted chan struct{}
+	// rescheduled signals tickOnSchedule that the schedule changed
+	rescheduled chan struct{}
+	options     TickerOptions
 
 	firstTick time.Time
 	lastTick  time.Time
@@ -29,29 +263,65 @@ type Ticker struct {
 	ticksSeen    atomic.Int64
 	ticksSent    atomic.Int64
 	ticksDropped atomic.Int64
+	ticksMissed  atomic.Int64
 	mu           sync.Mutex
+
+	// sleptFor reports how long the host was asleep between two
+	// checks of the time. Defaults to hostSleptFor, and may be
+	// replaced to simulate the host sleeping.
+	sleptFor func(last time.Time, now time.Time) time.Duration
+
+	// onDrop is called when a tick is dropped because no receiver
+	// was ready before the send timeout elapsed
+	onDrop func(t time.Time)
 }
 
 // NewTicker creates a new Ticker from a cron expression,
 // sending the current time on Ticker.C when the schedule
 // is triggered.
 // It works similarly to [time.Ticker](https://golang.org/pkg/time/#Ticker),
-// but is granular only to the minute. sendTimeout is the maximum time to wait
+// but is granular only to the minute (or the second, for schedules parsed
+// with [WithSeconds]). sendTimeout is the maximum time to wait
 // for a receiver to send a tick on the Ticker.C channel (this differs from
-// [time.Ticker], allowing some wiggle room for slow receivers).
+// [time.Ticker], allowing some wiggle room for slow receivers):
+//
+//   - 0: wait until the tick is received (or the ticker is stopped)
+//   - >0: wait up to sendTimeout, then drop the tick
+//   - [DropImmediately] (or any negative value): drop the tick if no
+//     receiver is ready
+//
 // If the provided context is canceled, the ticker will stop automatically.
 func NewTicker(
 	ctx context.Context,
 	schedule *Schedule,
 	sendTimeout time.Duration,
+) *Ticker {
+	return NewTickerWithOptions(
+		ctx,
+		schedule,
+		TickerOptions{SendTimeout: sendTimeout},
+	)
+}
+
+// NewTickerWithOptions creates a new Ticker, as with [NewTicker],
+// configured by the given [TickerOptions]
+func NewTickerWithOptions(
+	ctx context.Context,
+	schedule *Schedule,
+	opts TickerOptions,
 ) *Ticker {
 	t := &Ticker{
 		schedule:    schedule,
 		C:           make(chan time.Time),
+		Ticks:       make(chan Tick),
 		stop:        make(chan struct{}, 1),
-		tickCh:      make(chan time.Time),
+		done:        make(chan struct{}),
+		exhausted:   make(chan struct{}),
+		rescheduled: make(chan struct{}, 1),
+		tickCh:      make(chan Tick),
 		mu:          sync.Mutex{},
-		sendTimeout: sendTimeout,
+		options:     opts,
+		sleptFor:    hostSleptFor,
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -62,8 +332,9 @@ func NewTicker(
 		for {
 			select {
 			case <-t.stop:
-				Logger.Debug("ticker stopped, canceling", "ticker", t)
+				tickerLogger().Debug("ticker stopped, canceling", "ticker", t)
 				cancel()
+				close(t.done)
 				return
 			case <-ctx.Done():
 				t.Stop()
@@ -77,9 +348,9 @@ func NewTicker(
 		t.tickOnSchedule(ctx)
 	}()
 
-	Logger.Debug("waiting for initial tick", "ticker", t)
+	tickerLogger().Debug("waiting for initial tick", "ticker", t)
 	init := <-t.tickCh
-	Logger.Debug("initial tick", "time", init, "ticker", t)
+	tickerLogger().Debug("initial tick", "time", init, "ticker", t)
 	wg.Add(1)
 	go func() {
 		wg.Done()
@@ -90,6 +361,55 @@ func NewTicker(
 	return t
 }
 
+// OnDrop sets a function to be called whenever a tick is dropped
+// because no receiver was ready on Ticker.C before the send timeout
+// elapsed. The dropped tick's time is passed to f. f is called
+// synchronously from the ticker's goroutine, so it should return
+// quickly (hand off any slow work to another goroutine).
+// Passing nil removes a previously set function.
+func (t *Ticker) OnDrop(f func(t time.Time)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onDrop = f
+}
+
+// Done returns a channel that's closed once the ticker has stopped
+func (t *Ticker) Done() <-chan struct{} {
+	return t.done
+}
+
+// Schedule returns the ticker's schedule
+func (t *Ticker) Schedule() *Schedule {
+	t.scheduleMu.RLock()
+	defer t.scheduleMu.RUnlock()
+	return t.schedule
+}
+
+// SetLocation switches the ticker's schedule to the given location
+// (see [Schedule.WithLocation]), and recomputes its next scheduled
+// time. Occurrences that are due in the new location, but weren't
+// in the old one, fire right away. Long-running processes can use
+// it with [ReloadLocation] to apply updated time zone rules (ex: a
+// change in DST dates) without restarting.
+func (t *Ticker) SetLocation(loc *time.Location) {
+	t.scheduleMu.Lock()
+	t.schedule = t.schedule.WithLocation(loc)
+	t.scheduleMu.Unlock()
+	select {
+	case t.rescheduled <- struct{}{}:
+	default:
+		// a reschedule is already pending
+	}
+}
+
+// Exhausted returns a channel that's closed once the schedule has no
+// more occurrences, after the tick for the final occurrence (if any)
+// has been handed off for sending
+func (t *Ticker) Exhausted() <-chan struct{} {
+	return t.exhausted
+}
+
+// Stop stops the ticker. No more ticks will be sent after Stop is called.
 func (t *Ticker) Stop() {
 	select {
 	case t.stop <- struct{}{}:
@@ -99,56 +419,102 @@ func (t *Ticker) Stop() {
 	}
 }
 
-// tickOnSchedule sends a tick when the current time matches
-// the next scheduled time. The time is checked every minute.
-// This is used instead of a [time.Ticker] to avoid drift.
+// tickOnSchedule sends a tick when the current time reaches the next
+// scheduled time, re-checking the time at least every maxTickerSleep
 func (t *Ticker) tickOnSchedule(ctx context.Context) {
-	loc := t.schedule.loc
-	t.tickCh <- time.Now().In(t.schedule.loc)
-	nextTime := t.schedule.nextNoTruncate(time.Now().In(loc).Truncate(time.Minute))
-	sleepDone := make(chan struct{}, 1)
-	Logger.Debug(
+	loc := t.Schedule().loc
+	initial := time.Now().In(loc)
+	t.tickCh <- Tick{Time: initial, Occurrences: 1, First: initial, Last: initial}
+	nextTime := t.Schedule().Next(time.Now().In(loc))
+	tickerLogger().Debug(
 		"starting tick on schedule",
 		"next_time", nextTime,
 		"ticker", t,
 	)
-	for ctx.Err() == nil {
-		now := time.Now().In(t.schedule.loc)
-		if timesEqualToMinute(now, nextTime) {
-			Logger.Debug(
-				"saw tick",
+	if nextTime.IsZero() {
+		tickerLogger().Info("schedule has no upcoming occurrences", "ticker", t)
+		close(t.exhausted)
+		return
+	}
+
+	lastWake := time.Now()
+	timer := time.NewTimer(sleepDuration(lastWake, nextTime))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.rescheduled:
+			// occurrences up to lastWake have been handled, so the
+			// next one is recomputed from there, and sent right away
+			// if it's already due
+			schedule := t.Schedule()
+			loc = schedule.loc
+			nextTime = schedule.Next(lastWake)
+			tickerLogger().Info(
+				"schedule changed, rescheduling",
 				"next_time", nextTime,
-				"now", now,
 				"ticker", t,
 			)
-			t.tick(ctx)
-			nextTime = t.schedule.nextNoTruncate(
-				time.Now().In(loc).Truncate(time.Minute),
-			)
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			if nextTime.IsZero() {
+				tickerLogger().Info("schedule has no more occurrences", "ticker", t)
+				close(t.exhausted)
+				return
+			}
+			timer.Reset(sleepDuration(time.Now(), nextTime))
+			continue
+		case <-timer.C:
+			//
 		}
 
-		nextMinute := time.Now().Add(time.Minute).Truncate(time.Minute)
-		untilNextMinute := nextMinute.Sub(time.Now())
-		sleepDuration := untilNextMinute + (1 * time.Second)
+		now := time.Now().In(loc)
+		if !nextTime.After(now.Add(t.tolerance())) {
+			nextTime = t.wake(ctx, nextTime, lastWake, now)
+			// sending may block, so re-read the time before
+			// calculating the next wakeup
+			now = time.Now().In(loc)
+		}
+		lastWake = now
+		if nextTime.IsZero() {
+			tickerLogger().Info("schedule has no more occurrences", "ticker", t)
+			close(t.exhausted)
+			return
+		}
 
-		Logger.Info(
+		d := sleepDuration(now, nextTime)
+		tickerLogger().Debug(
 			"sleeping",
-			"duration", sleepDuration,
+			"duration", d,
 			"next_time", nextTime,
 			"now", now,
-			"until_next_minute", untilNextMinute,
 			"ticker", t,
 		)
-		go func() {
-			time.Sleep(sleepDuration)
-			sleepDone <- struct{}{}
-		}()
-		select {
-		case <-ctx.Done():
-			return
-		case <-sleepDone:
-			//
-		}
+		timer.Reset(d)
+	}
+}
+
+// maxTickerSleep is the longest a [Ticker] waits before
+// re-checking the time
+const maxTickerSleep = time.Minute
+
+// sleepDuration returns how long to wait from now until next,
+// capped at maxTickerSleep. If next isn't after now, it returns 0.
+func sleepDuration(now time.Time, next time.Time) time.Duration {
+	d := next.Sub(now)
+	switch {
+	case d <= 0:
+		return 0
+	case d > maxTickerSleep:
+		return maxTickerSleep
+	default:
+		return d
 	}
 }
 
@@ -159,60 +525,249 @@ func (t *Ticker) run(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			Logger.Debug("ticker stopped, breaking", "ticker", t)
+			tickerLogger().Debug("ticker stopped, breaking", "ticker", t)
 			return
 		case currentTick := <-t.tickCh:
-			Logger.Debug(
+			tickerLogger().Debug(
 				"schedule triggered",
-				"current_tick", currentTick,
+				"current_tick", currentTick.Time,
+				"occurrences", currentTick.Occurrences,
 				"ticker", t,
 			)
-			tctx, tcancel := context.WithTimeout(ctx, t.sendTimeout)
-			select {
-			case t.C <- currentTick:
+			sent, err := t.send(ctx, currentTick)
+			switch {
+			case sent:
 				t.ticksSent.Add(1)
-				Logger.Debug("sent tick", "ticker", t)
-			case <-tctx.Done():
-				Logger.Debug("dropped tick", "ticker", t)
+				tickerLogger().Debug("sent tick", "ticker", t)
+			case err != nil:
+				tickerLogger().Debug("ticker stopped before tick was sent", "ticker", t)
+			default:
+				tickerLogger().Debug("dropped tick", "ticker", t)
 				t.ticksDropped.Add(1)
+				t.dropped(currentTick.Time)
 			}
-			tcancel()
 		}
 	}
 }
 
-// tick sends a tick on the tick channel
+// wake sends the ticks due for the scheduled occurrences from next
+// through now, and returns the next scheduled time after now.
+// lastWake is the previous time the ticker checked the time, used
+// to determine whether the host was asleep in the meantime.
+func (t *Ticker) wake(
+	ctx context.Context,
+	next time.Time,
+	lastWake time.Time,
+	now time.Time,
+) time.Time {
+	slept := t.sleptFor(lastWake, now)
+	asleep := slept >= sleepThreshold
+	tickerLogger().Debug(
+		"saw tick",
+		"next_time", next,
+		"now", now,
+		"ticker", t,
+	)
+	if asleep {
+		tickerLogger().Warn(
+			"host appears to have been asleep",
+			"slept", slept,
+			"missed_ticks", t.options.MissedTicks.String(),
+			"ticker", t,
+		)
+	}
+
+	ticks, next := t.due(next, now, asleep)
+	for _, tk := range ticks {
+		if t.options.Clock != nil {
+			tk.Time = t.options.Clock.Now().In(t.Schedule().loc)
+		}
+		t.sendTick(ctx, tk)
+	}
+	return next
+}
+
+// tolerance returns the ticker's non-negative Tolerance
+func (t *Ticker) tolerance() time.Duration {
+	return max(t.options.Tolerance, 0)
+}
+
+// due returns the ticks to send for the scheduled occurrences
+// from next through now (plus the ticker's tolerance), along with
+// the next scheduled time after that. If the ticker coalesces ticks,
+// a single tick is returned for all due occurrences. If asleep is
+// true, the occurrences were missed while the host was asleep, and
+// are handled according to the MissedTicks policy.
+func (t *Ticker) due(
+	next time.Time,
+	now time.Time,
+	asleep bool,
+) ([]Tick, time.Time) {
+	schedule := t.Schedule()
+	limit := now.Add(t.tolerance())
+	coalesce := t.options.Coalesce
+	// occurrences before the minute (or second) the ticker
+	// woke up in were missed
+	woke := now.Truncate(schedule.resolution())
+	if asleep {
+		switch t.options.MissedTicks {
+		case MissedTicksSkip:
+			for !next.IsZero() && next.Before(woke) {
+				t.ticksMissed.Add(1)
+				next = schedule.Next(next)
+			}
+		case MissedTicksFireOnce:
+			coalesce = true
+		}
+	}
+
+	var ticks []Tick
+	coalesced := Tick{Time: now}
+	for !next.IsZero() && !next.After(limit) {
+		if coalesce {
+			if coalesced.First.IsZero() {
+				coalesced.First = next
+			}
+			coalesced.Last = next
+			coalesced.Occurrences++
+		} else {
+			tk := Tick{Time: now, Occurrences: 1, First: next, Last: next}
+			if next.Before(woke) {
+				tk.Reason = RunCatchUp
+			}
+			ticks = append(ticks, tk)
+		}
+		next = schedule.Next(next)
+	}
+	if coalesced.Occurrences > 0 {
+		if coalesced.First.Before(woke) {
+			coalesced.Reason = RunCatchUp
+		}
+		ticks = append(ticks, coalesced)
+	}
+	return ticks, next
+}
+
+// tick sends a tick for the current time on the tick channel
 func (t *Ticker) tick(ctx context.Context) bool {
-	nt := time.Now().In(t.schedule.loc)
+	nt := clockNow(t.options.Clock).In(t.Schedule().loc)
+	return t.sendTick(ctx, Tick{Time: nt, Occurrences: 1, First: nt, Last: nt})
+}
+
+// sendTick sends the given tick on the tick channel
+func (t *Ticker) sendTick(ctx context.Context, tk Tick) bool {
 	select {
 	case <-ctx.Done():
 		return false
-	case t.tickCh <- nt:
-		Logger.Info("sent tick", "tick", nt, "ticker", t)
+	case t.tickCh <- tk:
+		tickerLogger().Info("sent tick", "tick", tk.Time, "ticker", t)
 		t.ticksSeen.Add(1)
 
 		t.mu.Lock()
 		defer t.mu.Unlock()
-		t.lastTick = nt
+		t.lastTick = tk.Time
 		if t.firstTick.IsZero() {
-			t.firstTick = nt
+			t.firstTick = tk.Time
 		}
 		return true
 	}
 }
 
-func (t Ticker) LogValue() slog.Value {
-	return slog.GroupValue(
-		slog.String("schedule", t.schedule.String()),
+// send sends the given tick on Ticker.C or Ticker.Ticks, waiting
+// according to the send timeout. It returns true if the tick was received.
+// If the context is done before the tick is received, the context's
+// error is returned.
+func (t *Ticker) send(ctx context.Context, tick Tick) (bool, error) {
+	switch {
+	case t.options.SendTimeout < 0:
+		select {
+		case t.C <- tick.Time:
+			return true, nil
+		case t.Ticks <- tick:
+			return true, nil
+		default:
+			return false, nil
+		}
+	case t.options.SendTimeout == 0:
+		select {
+		case t.C <- tick.Time:
+			return true, nil
+		case t.Ticks <- tick:
+			return true, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	default:
+		timer := time.NewTimer(t.options.SendTimeout)
+		defer timer.Stop()
+		select {
+		case t.C <- tick.Time:
+			return true, nil
+		case t.Ticks <- tick:
+			return true, nil
+		case <-timer.C:
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// dropped calls the OnDrop function, if set, with the dropped tick
+func (t *Ticker) dropped(tick time.Time) {
+	t.mu.Lock()
+	f := t.onDrop
+	t.mu.Unlock()
+	if f != nil {
+		f(tick)
+	}
+}
+
+func (t *Ticker) LogValue() slog.Value {
+	t.mu.Lock()
+	lastTick := t.lastTick
+	t.mu.Unlock()
+
+	attrs := make([]slog.Attr, 0, 6)
+	if t.options.Name != "" {
+		attrs = append(attrs, slog.String("name", t.options.Name))
+	}
+	attrs = append(
+		attrs,
+		slog.String("schedule", t.Schedule().String()),
+		slog.String("state", t.state()),
+	)
+	if next := t.Schedule().Next(time.Now()); !next.IsZero() {
+		attrs = append(attrs, slog.Time("next", next))
+	}
+	if !lastTick.IsZero() {
+		attrs = append(attrs, slog.Time("last_tick", lastTick))
+	}
+	attrs = append(
+		attrs,
 		slog.Group(
 			"ticks",
 			"seen", t.ticksSeen.Load(),
 			"sent", t.ticksSent.Load(),
 			"dropped", t.ticksDropped.Load(),
+			"missed", t.ticksMissed.Load(),
 		),
 	)
+	return slog.GroupValue(attrs...)
 }
 
-func timesEqualToMinute(t1, t2 time.Time) bool {
-	return t1.Truncate(time.Minute).Equal(t2.Truncate(time.Minute))
+// state describes whether the ticker is running, stopped, or
+// exhausted (its schedule has no more occurrences)
+func (t *Ticker) state() string {
+	select {
+	case <-t.done:
+		return "stopped"
+	default:
+	}
+	select {
+	case <-t.exhausted:
+		return "exhausted"
+	default:
+		return "running"
+	}
 }
@@ -2,6 +2,7 @@ package crong
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -138,3 +139,584 @@ func TestTickerSendTimeout(t *testing.T) {
 	time.Sleep(5 * time.Second)
 	assertEqual(t, ticker.ticksDropped.Load(), int64(1))
 }
+
+func TestTickerOnDrop(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := NewTicker(ctx, s, 1*time.Second)
+	if ticker == nil {
+		t.Fatalf("expected ticker")
+	}
+	defer ticker.Stop()
+
+	droppedCh := make(chan time.Time, 1)
+	ticker.OnDrop(
+		func(dt time.Time) {
+			droppedCh <- dt
+		},
+	)
+	ticker.tick(ctx)
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected dropped tick")
+	case <-droppedCh:
+		assertEqual(t, ticker.ticksDropped.Load(), int64(1))
+	}
+}
+
+func TestTickerBlockUntilReceived(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := NewTicker(ctx, s, 0)
+	if ticker == nil {
+		t.Fatalf("expected ticker")
+	}
+	defer ticker.Stop()
+
+	go ticker.tick(ctx)
+	time.Sleep(2 * time.Second)
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected tick")
+	case <-ticker.C:
+		assertEqual(t, ticker.ticksDropped.Load(), int64(0))
+	}
+}
+
+func TestTickerDropImmediately(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := NewTicker(ctx, s, DropImmediately)
+	if ticker == nil {
+		t.Fatalf("expected ticker")
+	}
+	defer ticker.Stop()
+
+	droppedCh := make(chan time.Time, 1)
+	ticker.OnDrop(
+		func(dt time.Time) {
+			droppedCh <- dt
+		},
+	)
+	ticker.tick(ctx)
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected dropped tick")
+	case <-droppedCh:
+		assertEqual(t, ticker.ticksDropped.Load(), int64(1))
+		assertEqual(t, ticker.ticksSent.Load(), int64(0))
+	}
+}
+
+func TestTickerDue(t *testing.T) {
+	s, err := New("*/15 * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next := time.Date(2024, 2, 21, 11, 15, 0, 0, time.UTC)
+	now := time.Date(2024, 2, 21, 11, 50, 30, 0, time.UTC)
+	expectNext := time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC)
+
+	t.Run(
+		"burst", func(t *testing.T) {
+			ticker := &Ticker{schedule: s}
+			ticks, nextTime := ticker.due(next, now, false)
+			assertEqual(t, nextTime, expectNext)
+			if len(ticks) != 3 {
+				t.Fatalf("expected 3 ticks, got %d", len(ticks))
+			}
+			for i, tk := range ticks {
+				expectTime := next.Add(time.Duration(i) * 15 * time.Minute)
+				assertEqual(t, tk.Occurrences, 1)
+				assertEqual(t, tk.First, expectTime)
+				assertEqual(t, tk.Last, expectTime)
+				assertEqual(t, tk.Time, now)
+				assertEqual(t, tk.Reason, RunCatchUp)
+			}
+		},
+	)
+
+	t.Run(
+		"coalesced", func(t *testing.T) {
+			ticker := &Ticker{
+				schedule: s,
+				options:  TickerOptions{Coalesce: true},
+			}
+			ticks, nextTime := ticker.due(next, now, false)
+			assertEqual(t, nextTime, expectNext)
+			if len(ticks) != 1 {
+				t.Fatalf("expected 1 tick, got %d", len(ticks))
+			}
+			assertEqual(t, ticks[0].Occurrences, 3)
+			assertEqual(t, ticks[0].First, next)
+			assertEqual(
+				t,
+				ticks[0].Last,
+				time.Date(2024, 2, 21, 11, 45, 0, 0, time.UTC),
+			)
+			assertEqual(t, ticks[0].Reason, RunCatchUp)
+		},
+	)
+
+	t.Run(
+		"on time", func(t *testing.T) {
+			ticker := &Ticker{schedule: s}
+			ticks, _ := ticker.due(expectNext, expectNext.Add(2*time.Second), false)
+			if len(ticks) != 1 {
+				t.Fatalf("expected 1 tick, got %d", len(ticks))
+			}
+			assertEqual(t, ticks[0].Reason, RunScheduled)
+		},
+	)
+
+	t.Run(
+		"not due", func(t *testing.T) {
+			ticker := &Ticker{schedule: s}
+			ticks, nextTime := ticker.due(expectNext, now, false)
+			assertEqual(t, len(ticks), 0)
+			assertEqual(t, nextTime, expectNext)
+		},
+	)
+}
+
+func TestTickerDueSeconds(t *testing.T) {
+	s, err := New("15,45 30 12 * * *", nil, WithSeconds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := &Ticker{schedule: s}
+	next := time.Date(2024, 1, 1, 12, 30, 15, 0, time.UTC)
+
+	ticks, nextTime := ticker.due(next, next, false)
+	assertEqual(t, len(ticks), 1)
+	assertEqual(t, ticks[0].First, next)
+	assertEqual(t, nextTime, time.Date(2024, 1, 1, 12, 30, 45, 0, time.UTC))
+
+	// both occurrences in the minute are due
+	now := time.Date(2024, 1, 1, 12, 31, 0, 0, time.UTC)
+	ticks, nextTime = ticker.due(next, now, false)
+	assertEqual(t, len(ticks), 2)
+	assertEqual(t, ticks[1].First, time.Date(2024, 1, 1, 12, 30, 45, 0, time.UTC))
+	assertEqual(t, nextTime, time.Date(2024, 1, 2, 12, 30, 15, 0, time.UTC))
+}
+
+func TestTickerTicksChannel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := NewTickerWithOptions(
+		ctx,
+		s,
+		TickerOptions{SendTimeout: 5 * time.Second, Coalesce: true},
+	)
+	defer ticker.Stop()
+
+	go ticker.tick(ctx)
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected tick")
+	case tk := <-ticker.Ticks:
+		assertEqual(t, tk.Occurrences, 1)
+		assertEqual(t, tk.First, tk.Last)
+	}
+}
+
+func TestSleepDuration(t *testing.T) {
+	next := time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC)
+	type sleepCase struct {
+		Name   string
+		Now    time.Time
+		Expect time.Duration
+	}
+	cases := []sleepCase{
+		{
+			Name:   "just before boundary",
+			Now:    next.Add(-time.Millisecond),
+			Expect: time.Millisecond,
+		},
+		{
+			Name:   "on boundary",
+			Now:    next,
+			Expect: 0,
+		},
+		{
+			Name:   "past boundary",
+			Now:    next.Add(time.Second),
+			Expect: 0,
+		},
+		{
+			Name:   "within a minute",
+			Now:    next.Add(-45 * time.Second),
+			Expect: 45 * time.Second,
+		},
+		{
+			Name:   "capped",
+			Now:    next.Add(-90 * time.Minute),
+			Expect: maxTickerSleep,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Name, func(t *testing.T) {
+				assertEqual(t, sleepDuration(tc.Now, next), tc.Expect)
+			},
+		)
+	}
+}
+
+// TestTickerDueBoundary verifies an occurrence is only due once,
+// regardless of where around the minute boundary the ticker wakes up
+func TestTickerDueBoundary(t *testing.T) {
+	s, err := New("* * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := &Ticker{schedule: s}
+	next := time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC)
+
+	// woke up slightly early
+	ticks, nextTime := ticker.due(next, next.Add(-time.Millisecond), false)
+	assertEqual(t, len(ticks), 0)
+	assertEqual(t, nextTime, next)
+
+	// woke up exactly on the boundary
+	ticks, nextTime = ticker.due(next, next, false)
+	assertEqual(t, len(ticks), 1)
+	assertEqual(t, nextTime, next.Add(time.Minute))
+
+	// woke up again late in the same minute, which shouldn't
+	// fire the same occurrence again
+	ticks, nextTime = ticker.due(nextTime, next.Add(59*time.Second), false)
+	assertEqual(t, len(ticks), 0)
+	assertEqual(t, nextTime, next.Add(time.Minute))
+
+	// woke up slightly late for the following minute
+	ticks, nextTime = ticker.due(nextTime, next.Add(61*time.Second), false)
+	assertEqual(t, len(ticks), 1)
+	assertEqual(t, ticks[0].First, next.Add(time.Minute))
+	assertEqual(t, nextTime, next.Add(2*time.Minute))
+}
+
+func TestTickerMissedTicks(t *testing.T) {
+	s, err := New("*/15 * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next := time.Date(2024, 2, 21, 11, 15, 0, 0, time.UTC)
+	now := time.Date(2024, 2, 21, 11, 50, 30, 0, time.UTC)
+	expectNext := time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC)
+
+	type missedCase struct {
+		Policy            MissedTickPolicy
+		Coalesce          bool
+		ExpectTicks       int
+		ExpectOccurrences int
+		ExpectMissed      int64
+	}
+	cases := []missedCase{
+		{Policy: MissedTicksFireAll, ExpectTicks: 3, ExpectOccurrences: 1},
+		{
+			Policy:            MissedTicksFireAll,
+			Coalesce:          true,
+			ExpectTicks:       1,
+			ExpectOccurrences: 3,
+		},
+		{Policy: MissedTicksFireOnce, ExpectTicks: 1, ExpectOccurrences: 3},
+		{Policy: MissedTicksSkip, ExpectTicks: 0, ExpectMissed: 3},
+	}
+	for _, tc := range cases {
+		t.Run(
+			fmt.Sprintf("%s coalesce=%v", tc.Policy, tc.Coalesce),
+			func(t *testing.T) {
+				ticker := &Ticker{
+					schedule: s,
+					options: TickerOptions{
+						Coalesce:    tc.Coalesce,
+						MissedTicks: tc.Policy,
+					},
+				}
+				ticks, nextTime := ticker.due(next, now, true)
+				assertEqual(t, nextTime, expectNext)
+				assertEqual(t, len(ticks), tc.ExpectTicks)
+				for _, tk := range ticks {
+					assertEqual(t, tk.Occurrences, tc.ExpectOccurrences)
+				}
+				assertEqual(t, ticker.ticksMissed.Load(), tc.ExpectMissed)
+			},
+		)
+	}
+}
+
+func TestTickerMissedTicksSkipWakeMinute(t *testing.T) {
+	s, err := New("*/15 * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := &Ticker{
+		schedule: s,
+		options:  TickerOptions{MissedTicks: MissedTicksSkip},
+	}
+	next := time.Date(2024, 2, 21, 11, 15, 0, 0, time.UTC)
+	now := time.Date(2024, 2, 21, 11, 45, 20, 0, time.UTC)
+
+	// 11:45 is due in the minute the host woke up in, so it still fires
+	ticks, nextTime := ticker.due(next, now, true)
+	assertEqual(t, nextTime, time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC))
+	assertEqual(t, len(ticks), 1)
+	assertEqual(t, ticks[0].First, time.Date(2024, 2, 21, 11, 45, 0, 0, time.UTC))
+	assertEqual(t, ticks[0].Occurrences, 1)
+	assertEqual(t, ticker.ticksMissed.Load(), int64(2))
+}
+
+// TestTickerWakeAfterSleep simulates the host sleeping through
+// several occurrences between wakeups
+func TestTickerWakeAfterSleep(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s, err := New("*/15 * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next := time.Date(2024, 2, 21, 11, 15, 0, 0, time.UTC)
+	now := time.Date(2024, 2, 21, 11, 50, 30, 0, time.UTC)
+
+	for _, policy := range []MissedTickPolicy{
+		MissedTicksFireOnce,
+		MissedTicksSkip,
+	} {
+		for _, slept := range []time.Duration{0, time.Hour} {
+			t.Run(
+				fmt.Sprintf("%s slept=%s", policy, slept), func(t *testing.T) {
+					ticker := &Ticker{
+						schedule: s,
+						tickCh:   make(chan Tick, 10),
+						options:  TickerOptions{MissedTicks: policy},
+						sleptFor: func(_ time.Time, _ time.Time) time.Duration {
+							return slept
+						},
+					}
+					nextTime := ticker.wake(ctx, next, next, now)
+					assertEqual(
+						t,
+						nextTime,
+						time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC),
+					)
+
+					switch {
+					case slept == 0:
+						// not asleep, so every occurrence fires
+						assertEqual(t, len(ticker.tickCh), 3)
+					case policy == MissedTicksFireOnce:
+						assertEqual(t, len(ticker.tickCh), 1)
+						tk := <-ticker.tickCh
+						assertEqual(t, tk.Occurrences, 3)
+					default:
+						assertEqual(t, len(ticker.tickCh), 0)
+						assertEqual(t, ticker.ticksMissed.Load(), int64(3))
+					}
+				},
+			)
+		}
+	}
+}
+
+func TestHostSleptFor(t *testing.T) {
+	last := time.Now()
+	now := last.Add(time.Minute)
+	assertEqual(t, hostSleptFor(last, now), 0)
+
+	// without monotonic readings, there's nothing to compare
+	assertEqual(t, hostSleptFor(last.Round(0), now.Round(0)), 0)
+}
+
+func TestTickerDueTolerance(t *testing.T) {
+	s, err := New("* * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	next := time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC)
+	now := next.Add(-500 * time.Millisecond)
+
+	ticker := &Ticker{schedule: s}
+	ticks, nextTime := ticker.due(next, now, false)
+	assertEqual(t, len(ticks), 0)
+	assertEqual(t, nextTime, next)
+
+	ticker.options.Tolerance = time.Second
+	ticks, nextTime = ticker.due(next, now, false)
+	assertEqual(t, len(ticks), 1)
+	assertEqual(t, ticks[0].First, next)
+	assertEqual(t, nextTime, next.Add(time.Minute))
+}
+
+func TestTickerDueSkew(t *testing.T) {
+	s, err := New("* * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	start := time.Date(2024, 2, 21, 12, 0, 0, 0, time.UTC)
+
+	// a smeared clock wanders either side of each minute boundary,
+	// and the ticker may check the time more than once per minute.
+	// Each occurrence still fires exactly once.
+	skews := []time.Duration{
+		-999 * time.Millisecond,
+		-400 * time.Millisecond,
+		200 * time.Millisecond,
+		-1 * time.Millisecond,
+		999 * time.Millisecond,
+	}
+	ticker := &Ticker{schedule: s, options: TickerOptions{Tolerance: time.Second}}
+	next := start
+	var fired []time.Time
+	for i := 0; i < 10; i++ {
+		boundary := start.Add(time.Duration(i) * time.Minute)
+		for _, skew := range skews {
+			var ticks []Tick
+			ticks, next = ticker.due(next, boundary.Add(skew), false)
+			for _, tk := range ticks {
+				fired = append(fired, tk.Last)
+			}
+		}
+	}
+	if len(fired) != 10 {
+		t.Fatalf("expected 10 ticks, got %d: %v", len(fired), fired)
+	}
+	for i, occurrence := range fired {
+		assertEqual(t, occurrence, start.Add(time.Duration(i)*time.Minute))
+	}
+}
+
+func TestTickerAt(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	at := time.Now().Add(2 * time.Second)
+	s, err := New(At+" "+at.Format(time.RFC3339Nano), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := NewTicker(ctx, s, 5*time.Second)
+	defer ticker.Stop()
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected tick")
+	case tk := <-ticker.Ticks:
+		assertEqual(t, tk.First.Equal(at), true)
+	}
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected ticker to be exhausted")
+	case <-ticker.Exhausted():
+	}
+
+	// no further ticks
+	tctx, tcancel := context.WithTimeout(ctx, 3*time.Second)
+	defer tcancel()
+	select {
+	case <-tctx.Done():
+		//
+	case tk := <-ticker.Ticks:
+		t.Fatalf("unexpected tick: %#v", tk)
+	}
+}
+
+func TestTickerClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s, err := New("*/15 * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	stamp := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	ticker := &Ticker{
+		schedule: s,
+		tickCh:   make(chan Tick, 10),
+		options: TickerOptions{
+			Clock: ClockFunc(
+				func() time.Time {
+					return stamp
+				},
+			),
+		},
+		sleptFor: hostSleptFor,
+	}
+	next := time.Date(2024, 2, 21, 11, 15, 0, 0, time.UTC)
+	ticker.wake(ctx, next, next, next)
+
+	tk := <-ticker.tickCh
+	assertEqual(t, tk.Time, stamp)
+	assertEqual(t, tk.First, next)
+}
+
+func TestTickerSetLocation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// fires in a couple of seconds in plus2, but not for
+	// hours in UTC, the schedule's initial location
+	plus2 := time.FixedZone("plus2", 2*60*60)
+	at := time.Now().In(plus2).Add(2 * time.Second).Truncate(time.Second)
+	s, err := New(
+		fmt.Sprintf("%d %d %d * * *", at.Second(), at.Minute(), at.Hour()),
+		nil,
+		WithSeconds(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ticker := NewTickerWithOptions(ctx, s, TickerOptions{SendTimeout: 5 * time.Second})
+	defer ticker.Stop()
+
+	ticker.SetLocation(plus2)
+	assertEqual(t, ticker.Schedule().Location(), plus2)
+	for {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("expected tick in the new location")
+		case tk := <-ticker.Ticks:
+			if tk.Last.Equal(at) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package crong
+
+import (
+	"context"
+	"time"
+)
+
+// TickerFunc is a [Ticker] that, when the schedule is triggered,
+// evaluates a function and sends its result on TickerFunc.C. This
+// is useful for consumers that need data derived from the tick
+// (ex: the name of a time-based partition).
+type TickerFunc[T any] struct {
+	// C receives the result of the function for each tick
+	C chan T
+
+	ticker *Ticker
+	f      func(t time.Time) T
+}
+
+// NewTickerFunc creates a new [TickerFunc], configured with the given
+// [TickerOptions]. f is called with the tick's time when the schedule
+// is triggered, and its result is sent on TickerFunc.C.
+//
+// The underlying [Ticker]'s send timeout applies while a previous
+// result is still waiting to be received, so ticks are dropped
+// (rather than queued) when the receiver falls behind.
+// If the provided context is canceled, the ticker will stop automatically.
+func NewTickerFunc[T any](
+	ctx context.Context,
+	schedule *Schedule,
+	opts TickerOptions,
+	f func(t time.Time) T,
+) *TickerFunc[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	tf := &TickerFunc[T]{
+		C:      make(chan T),
+		ticker: NewTickerWithOptions(ctx, schedule, opts),
+		f:      f,
+	}
+	go func() {
+		defer cancel()
+		tf.run(ctx)
+	}()
+	return tf
+}
+
+// Ticker returns the underlying [Ticker]
+func (tf *TickerFunc[T]) Ticker() *Ticker {
+	return tf.ticker
+}
+
+// Stop stops the ticker. No more values will be sent after Stop is called.
+func (tf *TickerFunc[T]) Stop() {
+	tf.ticker.Stop()
+}
+
+// run evaluates the function for each tick from the underlying
+// ticker, and sends the result on TickerFunc.C
+func (tf *TickerFunc[T]) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tf.ticker.done:
+			return
+		case tk := <-tf.ticker.Ticks:
+			v := tf.f(tk.Time)
+			select {
+			case <-ctx.Done():
+				return
+			case <-tf.ticker.done:
+				return
+			case tf.C <- v:
+				//
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package crong
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTickerFunc(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tf := NewTickerFunc(
+		ctx,
+		s,
+		TickerOptions{SendTimeout: 5 * time.Second},
+		func(dt time.Time) string {
+			return "events_" + dt.Format("200601021504")
+		},
+	)
+	defer tf.Stop()
+
+	before := time.Now()
+	go tf.Ticker().tick(ctx)
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected value")
+	case v := <-tf.C:
+		if len(v) != len("events_200601021504") || v[:7] != "events_" {
+			t.Fatalf("unexpected value %q", v)
+		}
+		if v != "events_"+before.UTC().Format("200601021504") &&
+			v != "events_"+time.Now().UTC().Format("200601021504") {
+			t.Fatalf("unexpected value %q", v)
+		}
+	}
+
+	tf.Stop()
+	select {
+	case <-ctx.Done():
+		t.Fatalf("expected ticker to stop")
+	case <-tf.Ticker().Done():
+		//
+	}
+}
@@ -0,0 +1,88 @@
+package crong
+
+import (
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrJobNotRunning is returned by [ScheduledJob.Trigger] when the
+	// job isn't started (it's stopped, suspended or exhausted), or
+	// stops before the triggered run is queued
+	ErrJobNotRunning = errors.New("job is not running")
+
+	// ErrTriggerPending is returned by [ScheduledJob.Trigger] when an
+	// earlier triggered run hasn't started yet
+	ErrTriggerPending = errors.New("a trigger is already pending")
+)
+
+// Trigger runs the job now, on demand, in addition to its schedule.
+// The run goes through the same path as scheduled ticks: it waits
+// for a worker under MaxConcurrent (or in Pool's queue), counts
+// toward the job's failures and stats, and is recorded in Runtimes
+// with [JobRuntime.Triggered] set. A triggered run is for no
+// scheduled occurrence, so it neither checks nor moves the job's
+// watermark (see [ScheduledJobOptions.Watermarks]), and it isn't
+// shed when the queue is full.
+//
+// At most one trigger can be pending: ErrTriggerPending is returned
+// until the previously triggered run has started, so repeated calls
+// (ex, retried webhooks) don't pile up runs. With
+// [ScheduledJobOptions.TriggerDedupeWindow], a scheduled occurrence
+// shortly after the trigger is skipped, rather than running again.
+func (s *ScheduledJob) Trigger() error {
+	if ScheduleState(s.state.Load()) != ScheduleStarted {
+		return ErrJobNotRunning
+	}
+	if !s.triggerPending.CompareAndSwap(false, true) {
+		return ErrTriggerPending
+	}
+	s.mu.RLock()
+	stopping := s.stopping
+	s.mu.RUnlock()
+
+	now := clockNow(s.options.Clock)
+	s.lastTrigger.Store(now.UnixNano())
+	select {
+	case s.triggers <- Tick{
+		Time:      now,
+		Triggered: true,
+		Reason:    RunTriggered,
+	}:
+	case <-stopping:
+		// the dispatcher may already be gone
+		s.triggerPending.Store(false)
+		return ErrJobNotRunning
+	}
+	jobLogger().Info("job triggered", "scheduled_job", s)
+	return nil
+}
+
+// TriggerHandler returns an [http.Handler] that calls
+// [ScheduledJob.Trigger] on POST requests. It responds with
+// 202 Accepted when the run is triggered, 409 Conflict if a
+// trigger is already pending, 503 Service Unavailable if the
+// job isn't running, and 405 Method Not Allowed for other methods.
+func TriggerHandler(job *ScheduledJob) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", http.MethodPost)
+				http.Error(
+					w,
+					http.StatusText(http.StatusMethodNotAllowed),
+					http.StatusMethodNotAllowed,
+				)
+				return
+			}
+			switch err := job.Trigger(); {
+			case err == nil:
+				w.WriteHeader(http.StatusAccepted)
+			case errors.Is(err, ErrTriggerPending):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			}
+		},
+	)
+}
@@ -0,0 +1,222 @@
+package crong
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestJobTrigger(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	startedCh := make(chan struct{}, 10)
+	releaseCh := make(chan struct{})
+	job := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{Watermarks: NewMemoryWatermarkStore()},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			<-releaseCh
+			return nil
+		},
+	)
+	defer job.Stop(context.Background())
+
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-startedCh
+
+	// the first run has started, so another can be triggered,
+	// but only one can wait
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err = job.Trigger(); !errors.Is(err, ErrTriggerPending) {
+		t.Errorf("expected ErrTriggerPending, got %v", err)
+	}
+
+	releaseCh <- struct{}{}
+	<-startedCh
+	releaseCh <- struct{}{}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(job.Runtimes()) == 2
+		},
+	)
+	for _, rt := range job.Runtimes() {
+		assertEqual(t, rt.Triggered, true)
+		assertEqual(t, rt.Reason, RunTriggered)
+		assertEqual(t, rt.Scheduled.IsZero(), true)
+	}
+	assertEqual(t, job.Duplicates.Load(), 0)
+
+	job.Suspend()
+	if err = job.Trigger(); !errors.Is(err, ErrJobNotRunning) {
+		t.Errorf("expected ErrJobNotRunning, got %v", err)
+	}
+}
+
+func TestJobTriggerStopping(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New(Reboot, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	startedCh := make(chan struct{}, 10)
+	releaseCh := make(chan struct{})
+	jobCtx, stopJob := context.WithCancel(ctx)
+	job := ScheduleFunc(
+		jobCtx,
+		s,
+		ScheduledJobOptions{},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			<-releaseCh
+			return nil
+		},
+	)
+	defer job.Stop(context.Background())
+
+	// triggered while the startup run is pending or running
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-startedCh
+	releaseCh <- struct{}{}
+	<-startedCh
+	releaseCh <- struct{}{}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(job.Runtimes()) == 2
+		},
+	)
+	reasons := []RunReason{job.Runtimes()[0].Reason, job.Runtimes()[1].Reason}
+	if !slices.Contains(reasons, RunStartup) || !slices.Contains(reasons, RunTriggered) {
+		t.Errorf("expected a startup and a triggered run, got %v", reasons)
+	}
+
+	// the job's state is checked before it stops, and the
+	// trigger would wait on a full queue the dispatcher has left
+	stopJob()
+	<-job.done
+	job.state.Store(int64(ScheduleStarted))
+	job.triggers <- Tick{}
+	triggered := make(chan error, 1)
+	go func() { triggered <- job.Trigger() }()
+	select {
+	case err = <-triggered:
+		if !errors.Is(err, ErrJobNotRunning) {
+			t.Errorf("expected ErrJobNotRunning, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("trigger blocked after the job stopped")
+	}
+	assertEqual(t, job.triggerPending.Load(), false)
+
+	job.state.Store(int64(ScheduleStopped))
+	if err = job.Trigger(); !errors.Is(err, ErrJobNotRunning) {
+		t.Errorf("expected ErrJobNotRunning, got %v", err)
+	}
+}
+
+func TestJobTriggerDedupe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f := func(dt time.Time) error { return nil }
+	job := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{TriggerDedupeWindow: time.Minute},
+		f,
+	)
+	defer job.Stop(context.Background())
+
+	if err = job.Trigger(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(job.Runtimes()) == 1
+		},
+	)
+
+	// an occurrence seconds after the trigger is skipped
+	job.ticker.inject(ctx, time.Now().Add(30*time.Second))
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return job.Duplicates.Load() == 1
+		},
+	)
+
+	// but not one outside the window
+	job.ticker.inject(ctx, time.Now().Add(2*time.Minute))
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(job.Runtimes()) == 2
+		},
+	)
+	assertEqual(t, job.Runtimes()[1].Reason, RunScheduled)
+	assertEqual(t, job.Duplicates.Load(), 1)
+}
+
+func TestTriggerHandler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("0 0 1 1 *", nil) // yearly
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ran := make(chan struct{}, 1)
+	job := ScheduleFunc(
+		ctx, s, ScheduledJobOptions{}, func(dt time.Time) error {
+			ran <- struct{}{}
+			return nil
+		},
+	)
+	defer job.Stop(context.Background())
+
+	server := httptest.NewServer(TriggerHandler(job))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_ = resp.Body.Close()
+	assertEqual(t, resp.StatusCode, http.StatusMethodNotAllowed)
+
+	resp, err = http.Post(server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_ = resp.Body.Close()
+	assertEqual(t, resp.StatusCode, http.StatusAccepted)
+	<-ran
+
+	job.Suspend()
+	resp, err = http.Post(server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_ = resp.Body.Close()
+	assertEqual(t, resp.StatusCode, http.StatusServiceUnavailable)
+}
@@ -0,0 +1,104 @@
+package crong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError is a validation error for a single
+// field of a cron expression
+type FieldError struct {
+	// Field is the name of the field (ex: "minute", "weekday")
+	Field string
+	// Value is the field's value in the expression
+	Value string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned by [New] when one or more fields of a
+// cron expression are invalid, and holds an error for each of them.
+// Use errors.As to retrieve it:
+//
+//	var verr *crong.ValidationError
+//	if errors.As(err, &verr) {
+//		for _, fe := range verr.Fields {
+//			fmt.Println(fe.Field, fe.Err)
+//		}
+//	}
+type ValidationError struct {
+	// Expression is the expression that failed validation
+	Expression string
+	// Fields holds the errors for each invalid field,
+	// in the order the fields appear in the expression
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, fe := range e.Fields {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors for each field
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, fe := range e.Fields {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// Field returns the error for the field with the given
+// name, or nil if that field is valid
+func (e *ValidationError) Field(name string) *FieldError {
+	for _, fe := range e.Fields {
+		if fe.Field == name {
+			return fe
+		}
+	}
+	return nil
+}
+
+// LimitError is returned by [New] when an expression exceeds a limit
+// set by [WithMaxLength], [WithMaxListEntries] or [WithMaxValues].
+// Limits on a single field are reported in a [ValidationError], so
+// use errors.As to retrieve it:
+//
+//	var lerr *crong.LimitError
+//	if errors.As(err, &lerr) {
+//		fmt.Println(lerr.Limit, lerr.Max, lerr.Actual)
+//	}
+type LimitError struct {
+	// Limit is the limit exceeded: "length", "list entries"
+	// or "values"
+	Limit string
+	// Max is the limit's maximum
+	Max int
+	// Actual is the expression's (or field's) value for the limit,
+	// which may be a lower bound, as checking stops once the limit
+	// is exceeded
+	Actual int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s exceeds the limit of %d (got %d)", e.Limit, e.Max, e.Actual)
+}
+
+// add records err for the given field, if it isn't nil
+func (e *ValidationError) add(f field, value string, err error) {
+	if err == nil {
+		return
+	}
+	e.Fields = append(e.Fields, &FieldError{Field: f.Name, Value: value, Err: err})
+}
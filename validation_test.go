@@ -0,0 +1,74 @@
+package crong
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	_, err := New("75 12 * * FRY", nil)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %T", err)
+	}
+	assertEqual(t, verr.Expression, "75 12 * * FRY")
+	assertEqual(t, len(verr.Fields), 2)
+
+	minute := verr.Field("minute")
+	if minute == nil {
+		t.Fatalf("expected a minute error")
+	}
+	assertEqual(t, minute.Value, "75")
+	assertEqual(t, minute.Error(), "invalid minute entry: '75' is greater than 59")
+
+	weekday := verr.Field("weekday")
+	if weekday == nil {
+		t.Fatalf("expected a weekday error")
+	}
+	assertEqual(t, weekday.Value, "FRY")
+	assertEqual(t, weekday.Error(), "invalid weekday entry: unknown name 'FRY'")
+
+	if verr.Field("hour") != nil {
+		t.Errorf("expected no hour error")
+	}
+	assertEqual(t, err.Error(), minute.Error()+"; "+weekday.Error())
+
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("expected a *FieldError")
+	}
+	assertEqual(t, fe.Field, "minute")
+
+	_, err = New("0 12 ? ? ?", nil, WithStrictBlank())
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %T", err)
+	}
+	if verr.Field("month") == nil {
+		t.Errorf("expected a month error")
+	}
+	if verr.Field("weekday") == nil {
+		t.Errorf("expected a weekday error")
+	}
+
+	_, err = New("0 12 * * ?", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = New("x 0 12 * * *", nil, WithSeconds())
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %T", err)
+	}
+	second := verr.Field("second")
+	if second == nil {
+		t.Fatalf("expected a second error")
+	}
+	if !strings.HasPrefix(second.Error(), "invalid second entry") {
+		t.Errorf("unexpected error: %s", second)
+	}
+}
@@ -0,0 +1,222 @@
+package crong
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FromValues returns a cron expression including exactly the given
+// values in each field (ex: a grid of values picked in a UI), written
+// as compactly as possible with ranges and steps (ex: minutes 0, 15,
+// 30 and 45 are "*/15", and hours 9 through 17 are "9-17"). Values
+// may be unsorted or repeated. A field with no values, or every value,
+// is '*'. Weekdays are 0-6, with 0 being Sunday. An error is returned
+// if any value is outside its field's range.
+func FromValues(
+	minutes []int,
+	hours []int,
+	days []int,
+	months []int,
+	weekdays []int,
+) (string, error) {
+	fields := []struct {
+		field  field
+		values []int
+	}{
+		{minuteOpts, minutes},
+		{hourOpts, hours},
+		{dayOpts, days},
+		{monthOpts, months},
+		{weekdayOpts, weekdays},
+	}
+	exprs := make([]string, 0, len(fields))
+	for _, fv := range fields {
+		expr, err := fv.field.compact(fv.values)
+		if err != nil {
+			return "", err
+		}
+		exprs = append(exprs, expr)
+	}
+	return strings.Join(exprs, " "), nil
+}
+
+// compact returns the shortest field value including exactly the
+// given values, made of a list of values, ranges and steps
+func (f field) compact(values []int) (string, error) {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	values = slices.Compact(values)
+	for _, v := range values {
+		if v < f.Min() || v > f.Max() {
+			return "", f.error(
+				fmt.Sprintf("%d is outside the range %d-%d", v, f.Min(), f.Max()),
+			)
+		}
+	}
+	if len(values) == 0 || slices.Equal(values, f.Allowed) {
+		return string(Any), nil
+	}
+
+	// best[i] is the shortest list of entries for values[i:], found
+	// by trying each entry that can start at values[i]. Entries
+	// cover values next to each other in sorted order.
+	n := len(values)
+	best := make([][]string, n+1)
+	length := make([]int, n+1)
+	for i := n - 1; i >= 0; i-- {
+		length[i] = -1
+		// longer entries are tried first, so they win ties
+		for j := n; j > i; j-- {
+			entry, ok := f.entry(values[i:j])
+			if !ok {
+				continue
+			}
+			l := len(entry) + length[j]
+			if j < n {
+				l++ // separator
+			}
+			if length[i] == -1 || l < length[i] {
+				length[i] = l
+				best[i] = append([]string{entry}, best[j]...)
+			}
+		}
+	}
+	return strings.Join(best[0], string(ListSeparator)), nil
+}
+
+// entry returns a single list entry including exactly the given
+// sorted values, if they're evenly spaced: a value, or a range or a
+// step over a range or wildcard, of at least three values
+func (f field) entry(values []int) (string, bool) {
+	first, last := values[0], values[len(values)-1]
+	if len(values) == 1 {
+		return strconv.Itoa(first), true
+	}
+	step := values[1] - first
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return "", false
+		}
+	}
+	switch {
+	case len(values) == 2:
+		// a list of two values is never longer, and reads better
+		// (ex: "1,2" rather than "1-2", "0,6" rather than "*/6")
+		return "", false
+	case step == 1:
+		return fmt.Sprintf("%d%c%d", first, Range, last), true
+	case first == f.Min() && last+step > f.Max():
+		return fmt.Sprintf("%c%c%d", Any, Step, step), true
+	}
+	return fmt.Sprintf("%d%c%d%c%d", first, Range, last, Step, step), true
+}
+
+// Canonical returns the expression in a normalized form, so that
+// equivalent expressions can be compared or deduplicated as strings.
+// Names are replaced by numbers (ex: "MON" is 1), and each field's
+// values are sorted, deduplicated, and written as compactly as
+// possible with ranges and steps, as with [FromValues] (ex:
+// "30,0 9-17/1 * JAN-MAR FRI,MON-THU" is "0,30 9-17 * 1-3 1-5").
+// Weeks of the month (ex: "W2") are replaced by their days, while
+// entries that depend on the month or year ('L', 'W', '#' and 'WY')
+// follow the other values. '?' is replaced by '*', unless the schedule
+// was parsed with WithStrictBlank. The canonical form parses, with
+// the same options, to an equivalent schedule. It doesn't include the
+// time zone (see [Schedule.Location]). One-shot (@at), fixed-interval
+// (@every) and startup (@reboot) schedules return String.
+func (s *Schedule) Canonical() string {
+	if !s.at.IsZero() || s.every > 0 || s.reboot {
+		return s.String()
+	}
+	fvs := s.fieldValues()
+	values := make([]string, 0, len(fvs))
+	for _, fv := range fvs {
+		values = append(values, s.canonicalField(fv.field, fv.value))
+	}
+	return strings.Join(values, " ")
+}
+
+// canonicalField returns the normalized form of the given
+// field value (see Schedule.Canonical)
+func (s *Schedule) canonicalField(f field, value string) string {
+	if value == string(Blank) && s.options.strictBlank {
+		return value
+	}
+	spec := f.spec(value)
+	if spec.Kind == FieldAny {
+		return string(Any)
+	}
+	entries := []FieldSpec{spec}
+	if spec.Kind == FieldList {
+		entries = spec.Entries
+	}
+
+	var values []int
+	var special []FieldSpec
+	for _, entry := range entries {
+		switch entry.Kind {
+		case FieldLast, FieldNearestWeekday, FieldNthWeekday, FieldWeekOfYear:
+			entry.Start = f.canonical([]int{entry.Start})[0]
+			special = append(special, entry)
+		default:
+			values = append(values, entry.Values...)
+		}
+	}
+	slices.SortFunc(
+		special, func(a, b FieldSpec) int {
+			if a.Kind != b.Kind {
+				return int(a.Kind) - int(b.Kind)
+			}
+			if a.Start != b.Start {
+				return a.Start - b.Start
+			}
+			return a.Nth - b.Nth
+		},
+	)
+
+	// the weekday field's aliases (7 for Sunday) are replaced
+	// by canonical values, so they're compacted without them
+	cf := f
+	if f.Aliases != nil {
+		cf = weekdayOpts
+	}
+	var entryValues []string
+	if len(values) > 0 {
+		compacted, _ := cf.compact(values)
+		if compacted == string(Any) && s.options.domDowUnion && restricted(value) &&
+			(f.Index == dayInd || f.Index == weekdayInd) {
+			// '*' would stop the field from being unioned with
+			// the other (see WithDomDowUnion)
+			compacted = fmt.Sprintf("%d%c%d", cf.Min(), Range, cf.Max())
+		}
+		if compacted == string(Any) {
+			return compacted
+		}
+		entryValues = append(entryValues, compacted)
+	}
+	for _, entry := range special {
+		var e string
+		switch entry.Kind {
+		case FieldLast:
+			e = string(Last)
+			if f.Index == weekdayInd {
+				e = strconv.Itoa(entry.Start) + e
+			}
+		case FieldNearestWeekday:
+			e = string(Last) + string(Week)
+			if entry.Start > 0 {
+				e = strconv.Itoa(entry.Start) + string(Week)
+			}
+		case FieldNthWeekday:
+			e = fmt.Sprintf("%d%c%d", entry.Start, Nth, entry.Nth)
+		case FieldWeekOfYear:
+			e = fmt.Sprintf("%cY%d", Week, entry.Start)
+		}
+		if !slices.Contains(entryValues, e) {
+			entryValues = append(entryValues, e)
+		}
+	}
+	return strings.Join(entryValues, string(ListSeparator))
+}
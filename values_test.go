@@ -0,0 +1,177 @@
+package crong
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestFromValues(t *testing.T) {
+	type valuesCase struct {
+		Minutes     []int
+		Hours       []int
+		Days        []int
+		Months      []int
+		Weekdays    []int
+		Expect      string
+		ExpectError bool
+	}
+	cases := []valuesCase{
+		{Expect: "* * * * *"},
+		{Minutes: []int{0, 15, 30, 45}, Expect: "*/15 * * * *"},
+		{
+			Minutes:  []int{30},
+			Hours:    []int{17, 9, 10, 11, 12, 13, 14, 15, 16, 9},
+			Weekdays: []int{1, 2, 3, 4, 5},
+			Expect:   "30 9-17 * * 1-5",
+		},
+		{Minutes: []int{0}, Hours: []int{0, 8, 16}, Expect: "0 */8 * * *"},
+		{Minutes: []int{0}, Hours: []int{0, 12}, Expect: "0 0,12 * * *"},
+		{Minutes: []int{5, 9}, Expect: "5,9 * * * *"},
+		{Minutes: []int{1, 2}, Expect: "1,2 * * * *"},
+		{Minutes: []int{10, 20, 30, 31, 32, 33}, Expect: "10,20,30-33 * * * *"},
+		{Minutes: []int{5, 15, 25, 35}, Expect: "5-35/10 * * * *"},
+		{Days: []int{1, 8, 15, 22, 29}, Expect: "* * */7 * *"},
+		{Months: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, Expect: "* * * * *"},
+		{Weekdays: []int{0, 6}, Expect: "* * * * 0,6"},
+		{Minutes: []int{60}, ExpectError: true},
+		{Days: []int{0}, ExpectError: true},
+		{Weekdays: []int{7}, ExpectError: true},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Expect, func(t *testing.T) {
+				expr, err := FromValues(tc.Minutes, tc.Hours, tc.Days, tc.Months, tc.Weekdays)
+				if tc.ExpectError {
+					if err == nil {
+						t.Fatalf("expected error, got %q", expr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, expr, tc.Expect)
+			},
+		)
+	}
+}
+
+// TestFromValuesRoundTrip checks that the expression returned for
+// random sets of values includes exactly those values
+func TestFromValuesRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := func(f field) []int {
+		var values []int
+		density := r.Float64()
+		for _, v := range f.Allowed {
+			if r.Float64() < density {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	expand := func(values []int, f field) []int {
+		if len(values) == 0 {
+			return f.Allowed
+		}
+		values = slices.Clone(values)
+		slices.Sort(values)
+		return values
+	}
+	for range 500 {
+		minutes, hours := random(minuteOpts), random(hourOpts)
+		days, months := random(dayOpts), random(monthOpts)
+		weekdays := random(weekdayOpts)
+		expr, err := FromValues(minutes, hours, days, months, weekdays)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s, err := New(expr, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", expr, err)
+		}
+		for _, fv := range []struct {
+			f      field
+			values []int
+			parsed []int
+		}{
+			{minuteOpts, minutes, s.minutes},
+			{hourOpts, hours, s.hours},
+			{dayOpts, days, s.days},
+			{monthOpts, months, s.months},
+			{weekdayOpts, weekdays, s.weekdays},
+		} {
+			parsed := fv.parsed
+			if parsed == nil {
+				parsed = fv.f.Allowed
+			}
+			if !slices.Equal(parsed, expand(fv.values, fv.f)) {
+				t.Fatalf(
+					"%s: expected %s values %v, got %v",
+					expr,
+					fv.f.Name,
+					expand(fv.values, fv.f),
+					parsed,
+				)
+			}
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	type canonicalCase struct {
+		Cron      string
+		Options   []ParseOption
+		Canonical string
+	}
+	cases := []canonicalCase{
+		{Cron: "30,0 9-17/1 * JAN-MAR FRI,MON-THU", Canonical: "0,30 9-17 * 1-3 1-5"},
+		{Cron: "0 0 * * MON-FRI", Canonical: "0 0 * * 1-5"},
+		{Cron: "0 0 * * 1,2,3,4,5", Canonical: "0 0 * * 1-5"},
+		{Cron: "0 0 * * 5,1-5,1", Canonical: "0 0 * * 1-5"},
+		{Cron: "0 0 * * 0-7", Canonical: "0 0 * * *"},
+		{Cron: "0 0 * * 7", Canonical: "0 0 * * 0"},
+		{Cron: "0,15,30,45 * * * *", Canonical: "*/15 * * * *"},
+		{Cron: "0-59/15 * * * *", Canonical: "*/15 * * * *"},
+		{Cron: "0 0 ? * *", Canonical: "0 0 * * *"},
+		{Cron: "@daily", Canonical: "0 0 * * *"},
+		{Cron: "0 0 15W,L,1,W2 * *", Canonical: "0 0 1,8-14,L,15W * *"},
+		{Cron: "0 22 * * FRIL,MON#1,7#2", Canonical: "0 22 * * 5L,0#2,1#1"},
+		{
+			Cron:      "0 12 ? * MON",
+			Options:   []ParseOption{WithStrictBlank()},
+			Canonical: "0 12 ? * 1",
+		},
+		{
+			Cron:      "0 0 * * MON-SUN",
+			Options:   []ParseOption{WithISOWeekdays()},
+			Canonical: "0 0 * * *",
+		},
+		{
+			Cron:      "0 0 1-31 * MON",
+			Options:   []ParseOption{WithDomDowUnion()},
+			Canonical: "0 0 1-31 * 1",
+		},
+		{Cron: "@every 90s", Canonical: "@every 1m30s"},
+	}
+	for _, tc := range cases {
+		t.Run(
+			tc.Cron, func(t *testing.T) {
+				s, err := New(tc.Cron, nil, tc.Options...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, s.Canonical(), tc.Canonical)
+
+				// the canonical form is stable, and equivalent
+				c, err := New(s.Canonical(), nil, tc.Options...)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				assertEqual(t, c.Canonical(), tc.Canonical)
+				assertEqual(t, Equivalent(c, s), true)
+			},
+		)
+	}
+}
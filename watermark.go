@@ -0,0 +1,109 @@
+package crong
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WatermarkStore persists the most recent scheduled occurrence a
+// job ran for. When a [ScheduledJob] is configured with a
+// WatermarkStore, it skips ticks for occurrences at or before the
+// stored watermark, so a job that's restarted within the same minute
+// doesn't run a second time for an occurrence it already ran for.
+type WatermarkStore interface {
+	// Watermark returns the most recent occurrence recorded for the
+	// given key, or the zero time if none has been recorded
+	Watermark(ctx context.Context, key string) (time.Time, error)
+
+	// SetWatermark records t as the most recent occurrence for the
+	// given key
+	SetWatermark(ctx context.Context, key string, t time.Time) error
+}
+
+// AdvanceWatermarkStore is a [WatermarkStore] that can check and move
+// a watermark forward in one step. Jobs use AdvanceWatermark when
+// their store provides it, so a watermark never moves backward, even
+// if writers race (ex: a replica's lock expired while it held it).
+type AdvanceWatermarkStore interface {
+	WatermarkStore
+
+	// AdvanceWatermark sets the watermark for the given key to t if
+	// t is after the current watermark (or none is recorded), and
+	// returns false, leaving it unchanged, otherwise
+	AdvanceWatermark(ctx context.Context, key string, t time.Time) (bool, error)
+}
+
+// advanceWatermark moves the watermark for key forward to t, returning
+// false if it's already at or past t. Stores that don't implement
+// AdvanceWatermarkStore are checked, then set, so callers that need
+// the two steps to be atomic must hold a lock on key.
+func advanceWatermark(
+	ctx context.Context,
+	store WatermarkStore,
+	key string,
+	t time.Time,
+) (bool, error) {
+	if as, ok := store.(AdvanceWatermarkStore); ok {
+		return as.AdvanceWatermark(ctx, key, t)
+	}
+	watermark, err := store.Watermark(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if !watermark.IsZero() && !t.After(watermark) {
+		return false, nil
+	}
+	return true, store.SetWatermark(ctx, key, t)
+}
+
+// MemoryWatermarkStore is an [AdvanceWatermarkStore] that keeps watermarks
+// in memory. Watermarks are shared by jobs using the same store, but
+// don't survive the process exiting.
+type MemoryWatermarkStore struct {
+	watermarks map[string]time.Time
+	mu         sync.RWMutex
+}
+
+// NewMemoryWatermarkStore returns an empty [MemoryWatermarkStore]
+func NewMemoryWatermarkStore() *MemoryWatermarkStore {
+	return &MemoryWatermarkStore{watermarks: map[string]time.Time{}}
+}
+
+// Watermark returns the watermark for the given key
+func (m *MemoryWatermarkStore) Watermark(
+	_ context.Context,
+	key string,
+) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.watermarks[key], nil
+}
+
+// SetWatermark sets the watermark for the given key
+func (m *MemoryWatermarkStore) SetWatermark(
+	_ context.Context,
+	key string,
+	t time.Time,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watermarks[key] = t
+	return nil
+}
+
+// AdvanceWatermark sets the watermark for the given key to t,
+// if t is after it
+func (m *MemoryWatermarkStore) AdvanceWatermark(
+	_ context.Context,
+	key string,
+	t time.Time,
+) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !t.After(m.watermarks[key]) {
+		return false, nil
+	}
+	m.watermarks[key] = t
+	return true, nil
+}
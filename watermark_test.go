@@ -0,0 +1,168 @@
+package crong
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryWatermarkStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryWatermarkStore()
+
+	wm, err := store.Watermark(ctx, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !wm.IsZero() {
+		t.Fatalf("expected zero watermark, got %s", wm)
+	}
+
+	expected := time.Date(2024, 2, 21, 10, 0, 0, 0, time.UTC)
+	if err = store.SetWatermark(ctx, "foo", expected); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wm, err = store.Watermark(ctx, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, wm, expected)
+
+	// advancing only moves the watermark forward
+	advanced, err := store.AdvanceWatermark(ctx, "foo", expected.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, advanced, false)
+	advanced, _ = store.AdvanceWatermark(ctx, "foo", expected)
+	assertEqual(t, advanced, false)
+	advanced, _ = store.AdvanceWatermark(ctx, "foo", expected.Add(time.Minute))
+	assertEqual(t, advanced, true)
+	wm, _ = store.Watermark(ctx, "foo")
+	assertEqual(t, wm, expected.Add(time.Minute))
+}
+
+func TestJobWatermark(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	store := NewMemoryWatermarkStore()
+	doneCh := make(chan time.Time, 10)
+	opts := ScheduledJobOptions{
+		TickerReceiveTimeout: 5 * time.Second,
+		Watermarks:           store,
+		WatermarkKey:         "job",
+	}
+	sj := ScheduleFunc(
+		ctx, s, opts, func(dt time.Time) error {
+			doneCh <- dt
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	sj.ticker.tick(ctx)
+	dt := <-doneCh
+	wm, err := store.Watermark(ctx, "job")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, wm, dt.Truncate(time.Minute))
+
+	// simulates a restarted job seeing an occurrence that
+	// was already recorded
+	if err = store.SetWatermark(ctx, "job", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	restarted := ScheduleFunc(
+		ctx, s, opts, func(dt time.Time) error {
+			doneCh <- dt
+			return nil
+		},
+	)
+	defer restarted.Stop(context.Background())
+
+	restarted.ticker.tick(ctx)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return restarted.Duplicates.Load() == 1
+		},
+	)
+	assertEqual(t, restarted.Runs.Load(), int64(0))
+}
+
+func TestJobWatermarkShed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	s, err := New("* * * * *", nil) // every minute
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	store := NewMemoryWatermarkStore()
+	startedCh := make(chan struct{}, 10)
+	releaseCh := make(chan struct{})
+	sj := ScheduleFunc(
+		ctx,
+		s,
+		ScheduledJobOptions{
+			MaxConcurrent:        1,
+			MaxQueueDepth:        1,
+			TickerReceiveTimeout: 5 * time.Second,
+			Watermarks:           store,
+			WatermarkKey:         "job",
+		},
+		func(dt time.Time) error {
+			startedCh <- struct{}{}
+			<-releaseCh
+			return nil
+		},
+	)
+	defer sj.Stop(context.Background())
+
+	first := time.Date(2024, 2, 21, 10, 0, 0, 0, time.Local)
+	sj.ticker.inject(ctx, first)
+	<-startedCh
+	sj.ticker.inject(ctx, first.Add(time.Minute))
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.ticker.ticksSent.Load() == 2
+		},
+	)
+
+	// shed, as the queue is full, so it isn't recorded
+	shed := first.Add(2 * time.Minute)
+	sj.ticker.inject(ctx, shed)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return sj.Shed.Load() == 1
+		},
+	)
+
+	close(releaseCh)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 2
+		},
+	)
+	wm, err := store.Watermark(ctx, "job")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := first.Add(time.Minute); !wm.Equal(expected) {
+		t.Errorf("expected watermark %s, got %s", expected, wm)
+	}
+
+	// the shed occurrence can still run
+	sj.ticker.inject(ctx, shed)
+	waitFor(
+		t, 5*time.Second, func() bool {
+			return len(sj.Runtimes()) == 3
+		},
+	)
+	assertEqual(t, sj.Duplicates.Load(), int64(0))
+}
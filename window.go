@@ -0,0 +1,157 @@
+package crong
+
+import (
+	"errors"
+	"time"
+)
+
+// Window is a recurring period of time, which opens at each scheduled
+// time and stays open for a fixed duration (ex: a maintenance window
+// from 02:00 to 04:00 on Sundays is "0 2 * * SUN" for two hours)
+type Window struct {
+	schedule *Schedule
+	duration time.Duration
+}
+
+// NewWindow creates a new Window that opens at each scheduled
+// time of the given schedule, and stays open for duration
+func NewWindow(schedule *Schedule, duration time.Duration) (*Window, error) {
+	if schedule == nil {
+		return nil, errors.New("schedule cannot be nil")
+	}
+	if duration <= 0 {
+		return nil, errors.New("window duration must be greater than 0")
+	}
+	return &Window{schedule: schedule, duration: duration}, nil
+}
+
+// Schedule returns the schedule the window opens on
+func (w *Window) Schedule() *Schedule {
+	return w.schedule
+}
+
+// Duration returns how long the window stays open
+func (w *Window) Duration() time.Duration {
+	return w.duration
+}
+
+// ActiveAt returns true if the window is open at the given time.
+// A window is open from its start, up to (but not including) its end.
+func (w *Window) ActiveAt(t time.Time) bool {
+	start := w.lastStart(t)
+	return !start.IsZero() && t.Before(start.Add(w.duration))
+}
+
+// NextWindow returns the start and end of the window that's open at
+// the given time, or if it's closed, of the next window to open after
+// it. If the window won't open again, the zero time is returned for
+// both. If windows overlap (the duration is longer than the time
+// between scheduled times), the latest window to open is returned.
+func (w *Window) NextWindow(t time.Time) (start time.Time, end time.Time) {
+	if start = w.lastStart(t); !start.IsZero() {
+		if end = start.Add(w.duration); t.Before(end) {
+			return start, end
+		}
+	}
+	start = w.schedule.Next(t)
+	if start.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	return start, start.Add(w.duration)
+}
+
+// lastStart returns the latest scheduled time at or before
+// the given time, or the zero time if there isn't one
+func (w *Window) lastStart(t time.Time) time.Time {
+	s := w.schedule
+	if !s.at.IsZero() {
+		if t.Before(s.at) {
+			return time.Time{}
+		}
+		return s.at
+	}
+	candidate := t.In(s.loc).Truncate(s.resolution())
+	if s.MatchesSecond(candidate) {
+		return candidate
+	}
+	return s.Prev(t)
+}
+
+// Interval is a period of time, from Start up to (but not including) End
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// Intervals returns the periods between from and to that the window
+// is open, in order. Overlapping or adjacent windows are merged into a
+// single interval, and intervals are clipped to start no earlier than
+// from and end no later than to.
+func (w *Window) Intervals(from time.Time, to time.Time) []Interval {
+	var intervals []Interval
+	add := func(start time.Time) {
+		end := start.Add(w.duration)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !start.Before(end) {
+			return
+		}
+		if n := len(intervals); n > 0 && !start.After(intervals[n-1].End) {
+			if end.After(intervals[n-1].End) {
+				intervals[n-1].End = end
+			}
+			return
+		}
+		intervals = append(intervals, Interval{Start: start, End: end})
+	}
+
+	// windows that opened before from may still be open. With
+	// overlapping windows, the latest to open closes last.
+	if start := w.lastStart(from); !start.IsZero() {
+		add(start)
+	}
+	for start := w.schedule.Next(from); !start.IsZero() && start.Before(to); start = w.schedule.Next(start) {
+		add(start)
+	}
+	return intervals
+}
+
+// OverlapReport returns the periods within horizon of from when both
+// windows are open, in order (ex: to check that a job's window never
+// collides with a backup window)
+func OverlapReport(a *Window, b *Window, from time.Time, horizon time.Duration) []Interval {
+	to := from.Add(horizon)
+	ai := a.Intervals(from, to)
+	bi := b.Intervals(from, to)
+
+	var overlaps []Interval
+	for i, j := 0, 0; i < len(ai) && j < len(bi); {
+		start := ai[i].Start
+		if bi[j].Start.After(start) {
+			start = bi[j].Start
+		}
+		end := ai[i].End
+		if bi[j].End.Before(end) {
+			end = bi[j].End
+		}
+		if start.Before(end) {
+			overlaps = append(overlaps, Interval{Start: start, End: end})
+		}
+		// move past whichever interval closes first
+		if ai[i].End.Before(bi[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return overlaps
+}
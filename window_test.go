@@ -0,0 +1,177 @@
+package crong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	s, err := New("0 2 * * SUN", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w, err := NewWindow(s, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, w.Schedule(), s)
+	assertEqual(t, w.Duration(), 2*time.Hour)
+
+	// 2024-03-03 is a Sunday
+	opens := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC)
+	closes := opens.Add(2 * time.Hour)
+	nextOpens := opens.AddDate(0, 0, 7)
+
+	assertEqual(t, w.ActiveAt(opens.Add(-time.Second)), false)
+	assertEqual(t, w.ActiveAt(opens), true)
+	assertEqual(t, w.ActiveAt(opens.Add(30*time.Second)), true)
+	assertEqual(t, w.ActiveAt(opens.Add(90*time.Minute)), true)
+	assertEqual(t, w.ActiveAt(closes.Add(-time.Nanosecond)), true)
+	assertEqual(t, w.ActiveAt(closes), false)
+
+	start, end := w.NextWindow(opens.Add(-time.Hour))
+	assertEqual(t, start, opens)
+	assertEqual(t, end, closes)
+
+	start, end = w.NextWindow(opens.Add(time.Hour))
+	assertEqual(t, start, opens)
+	assertEqual(t, end, closes)
+
+	start, end = w.NextWindow(closes)
+	assertEqual(t, start, nextOpens)
+	assertEqual(t, end, nextOpens.Add(2*time.Hour))
+
+	if _, err = NewWindow(s, 0); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, err = NewWindow(nil, time.Hour); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestWindowAt(t *testing.T) {
+	s, err := New(At+" 2024-03-03T02:00:30Z", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w, err := NewWindow(s, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	opens := s.At()
+
+	assertEqual(t, w.ActiveAt(opens.Add(-time.Second)), false)
+	assertEqual(t, w.ActiveAt(opens), true)
+	assertEqual(t, w.ActiveAt(opens.Add(time.Minute)), false)
+
+	start, end := w.NextWindow(opens.Add(-time.Hour))
+	assertEqual(t, start, opens)
+	assertEqual(t, end, opens.Add(time.Minute))
+
+	start, end = w.NextWindow(opens.Add(time.Hour))
+	assertEqual(t, start, time.Time{})
+	assertEqual(t, end, time.Time{})
+}
+
+func TestWindowIntervals(t *testing.T) {
+	// overlapping windows are merged
+	s, err := New("0,30 * * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w, err := NewWindow(s, 45*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	from := time.Date(2024, 3, 3, 2, 10, 0, 0, time.UTC)
+	intervals := w.Intervals(from, from.Add(time.Hour))
+	assertEqual(t, len(intervals), 1)
+	assertEqual(t, intervals[0].Start, from)
+	assertEqual(t, intervals[0].End, from.Add(time.Hour))
+	assertEqual(t, intervals[0].Duration(), time.Hour)
+
+	s, err = New("0 */6 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w, err = NewWindow(s, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	from = time.Date(2024, 3, 3, 0, 30, 0, 0, time.UTC)
+	intervals = w.Intervals(from, from.Add(12*time.Hour))
+	assertEqual(t, len(intervals), 3)
+	assertEqual(t, intervals[0], Interval{Start: from, End: from.Add(30 * time.Minute)})
+	assertEqual(
+		t,
+		intervals[1],
+		Interval{
+			Start: time.Date(2024, 3, 3, 6, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 3, 3, 7, 0, 0, 0, time.UTC),
+		},
+	)
+	assertEqual(
+		t,
+		intervals[2],
+		Interval{
+			Start: time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 3, 3, 12, 30, 0, 0, time.UTC),
+		},
+	)
+}
+
+func TestOverlapReport(t *testing.T) {
+	// backups run 02:00-04:00 on Sundays
+	backupSchedule, err := New("0 2 * * SUN", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	backup, err := NewWindow(backupSchedule, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a job running for 30 minutes every day at 03:45
+	jobSchedule, err := New("45 3 * * *", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	job, err := NewWindow(jobSchedule, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	overlaps := OverlapReport(backup, job, from, 14*24*time.Hour)
+	assertEqual(t, len(overlaps), 2)
+	// 2024-03-03 and 2024-03-10 are Sundays
+	assertEqual(
+		t,
+		overlaps[0],
+		Interval{
+			Start: time.Date(2024, 3, 3, 3, 45, 0, 0, time.UTC),
+			End:   time.Date(2024, 3, 3, 4, 0, 0, 0, time.UTC),
+		},
+	)
+	assertEqual(
+		t,
+		overlaps[1],
+		Interval{
+			Start: time.Date(2024, 3, 10, 3, 45, 0, 0, time.UTC),
+			End:   time.Date(2024, 3, 10, 4, 0, 0, 0, time.UTC),
+		},
+	)
+	// the order of the windows doesn't matter
+	assertEqual(t, len(OverlapReport(job, backup, from, 14*24*time.Hour)), 2)
+
+	// moving the job after the backup window avoids a collision
+	jobSchedule, err = jobSchedule.WithHour("4")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	job, err = NewWindow(jobSchedule, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, len(OverlapReport(backup, job, from, 14*24*time.Hour)), 0)
+}